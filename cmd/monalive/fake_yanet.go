@@ -0,0 +1,101 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"os"
+	"os/signal"
+	"slices"
+	"syscall"
+
+	"github.com/spf13/cobra"
+	log "go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+
+	"github.com/yanet-platform/monalive/internal/monitoring/logger"
+	"github.com/yanet-platform/monalive/pkg/yanet/fake"
+)
+
+// newFakeYANETCommand creates the command running the fake YANET control plane,
+// so Monalive can be run locally without YANET.
+func newFakeYANETCommand() *cobra.Command {
+	var (
+		sockPath string
+		modules  []string
+		strict   bool
+	)
+	cmd := &cobra.Command{
+		Use:   "fake-yanet",
+		Short: "Run in-memory fake of the YANET control plane",
+		Long: "Run in-memory fake of the YANET control plane serving the balancer " +
+			"real updates on the unix socket. The table of the reals is logged on SIGUSR1.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runFakeYANET(sockPath, modules, strict)
+		},
+	}
+
+	cmd.Flags().StringVarP(&sockPath, "sock", "s", "/run/yanet/protocontrolplane.sock", "Path to the control plane socket.")
+	cmd.Flags().StringSliceVarP(&modules, "module", "m", []string{fake.DefaultModule}, "Balancer modules to serve.")
+	cmd.Flags().BoolVar(&strict, "strict", false, "Ignore the updates of the reals unknown to the table.")
+
+	return cmd
+}
+
+// runFakeYANET serves the fake YANET control plane until the interruption
+// signal.
+func runFakeYANET(sockPath string, modules []string, strict bool) error {
+	logger, err := logger.New(context.Background(), &logger.Config{
+		Encoding: "console",
+		Level:    zapcore.InfoLevel,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create logger: %w", err)
+	}
+	defer logger.Sync()
+
+	opts := []fake.Option{fake.WithModules(modules...), fake.WithLogger(logger)}
+	if strict {
+		opts = append(opts, fake.WithStrict())
+	}
+
+	// Remove the socket left by the previous run.
+	if err := os.Remove(sockPath); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove socket: %w", err)
+	}
+	server, err := fake.Start(sockPath, opts...)
+	if err != nil {
+		return err
+	}
+	defer server.Close()
+	logger.Info("fake yanet is started", log.String("sock", sockPath), log.Strings("modules", modules))
+
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, syscall.SIGINT, syscall.SIGTERM, syscall.SIGUSR1)
+	for sig := range ch {
+		if sig != syscall.SIGUSR1 {
+			logger.Info("fake yanet is stopped", log.Stringer("signal", sig))
+			return nil
+		}
+		dumpFakeTable(server, logger)
+	}
+	return nil
+}
+
+// dumpFakeTable logs the reals of the fake control plane table.
+func dumpFakeTable(server *fake.Server, logger *log.Logger) {
+	table := server.Table()
+	keys := slices.SortedFunc(maps.Keys(table), fake.CompareKeys)
+	for _, key := range keys {
+		state := table[key]
+		logger.Info(
+			"real",
+			log.String("module", key.Module),
+			log.String("service", key.Service()),
+			log.String("real", key.Real()),
+			log.Bool("enabled", state.Enabled),
+			log.Uint32("weight", state.Weight),
+		)
+	}
+	logger.Info("table is dumped", log.Int("reals", len(table)), log.Int("pending", server.Pending()))
+}
@@ -43,6 +43,9 @@ func main() {
 		panic("Logic error: `config` flag not exists in the program")
 	}
 
+	// Add the command running the fake YANET control plane.
+	cmd.AddCommand(newFakeYANETCommand())
+
 	// Execute the command. If an error occurs, print it and exit with a
 	// non-zero status code.
 	if err := cmd.Execute(); err != nil {
@@ -97,6 +100,37 @@ func exec(configPath string) error {
 		return fmt.Errorf("failed to init monalive: %w", err)
 	}
 
+	// Reload the announcer configuration on SIGHUP.
+	reload := make(chan os.Signal, 1)
+	signal.Notify(reload, syscall.SIGHUP)
+	wg.Go(func() error {
+		for {
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-reload:
+				if err := reloadAnnouncer(monalive, configPath); err != nil {
+					logger.Error("failed to reload announcer", log.Error(err))
+					continue
+				}
+				logger.Info("announcer is reloaded")
+			}
+		}
+	})
+
+	// Shut down keeping the load balancer and announcer state on SIGUSR2.
+	keepState := make(chan os.Signal, 1)
+	signal.Notify(keepState, syscall.SIGUSR2)
+	wg.Go(func() error {
+		select {
+		case <-ctx.Done():
+			return nil
+		case s := <-keepState:
+			monalive.KeepStateOnShutdown()
+			return errors.New(s.String())
+		}
+	})
+
 	// Add a goroutine to the error group that runs the main application logic.
 	wg.Go(func() error {
 		return monalive.Run(ctx)
@@ -105,3 +139,12 @@ func exec(configPath string) error {
 	// Wait for all goroutines in the error group to complete.
 	return wg.Wait()
 }
+
+// reloadAnnouncer re-reads the config file and applies its announcer section.
+func reloadAnnouncer(monalive *app.Monalive, configPath string) error {
+	config, err := app.LoadConfig(configPath)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	return monalive.ReloadAnnouncer(config.Announcer)
+}
@@ -9,12 +9,15 @@ import (
 	"errors"
 	"fmt"
 	"net/netip"
+	"slices"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	log "go.uber.org/zap"
 	"golang.org/x/sync/errgroup"
 
+	"github.com/yanet-platform/monalive/internal/monitoring/metrics"
 	"github.com/yanet-platform/monalive/internal/types/key"
 	event "github.com/yanet-platform/monalive/internal/utils/event_registry"
 	"github.com/yanet-platform/monalive/internal/utils/shutdown"
@@ -51,30 +54,66 @@ type Stater interface {
 	ListenStateRequest(group string) error
 }
 
+// GroupReloader is an interface that defines a method for changing the set of
+// announce groups served by the client at runtime.
+type GroupReloader interface {
+	// ReloadGroups replaces the announce groups served by the client. The
+	// backends of the groups are resolved.
+	ReloadGroups(groups []*GroupConfig) error
+}
+
 // Announcer is responsible for managing prefix announces across multiple
 // groups. It maintains the configuration, announcer client, and internal state
 // required to synchronize prefix statuses and handle updates.
 type Announcer struct {
-	config *Config
+	config   *Config
+	services map[key.Service]ServiceAnnounce // services as passed to the last ReloadServices call
+	running  map[string]struct{}             // announce groups with running state request listeners
+	mu       sync.RWMutex                    // to protect the config, services and running listeners
+
 	client Client // client to communicate with an external announcer instance
 
 	prefixes             *Prefixes                                   // prefixes associated with their respective services
 	announceGroups       *AnnounceGroupRegistry                      // contains association between prefix and it's announce group
 	serviceEventRegistry *event.Registry[key.Service, ServiceStatus] // stores service status updates using the service as a key
+	sent                 *sentRegistry                               // stores prefix states delivered to an external announcer instance
+	damper               *damper                                     // delays prefix status changes to prevent flapping announces
 
+	pending map[string]map[netip.Prefix]PrefixStatus // prefix updates failed to be sent, accessed only by the updater worker
+	synced  map[string]time.Time                     // time of the last full state push per group, accessed only by the updater worker
+	updated map[string]time.Time                     // time of the last update per group, accessed only by the updater worker
+	failed  map[string]int                           // number of consecutive failed updates per group, accessed only by the updater worker
+	retry   map[string]time.Time                     // time the failed updates of the group are retried at, accessed only by the updater worker
+
+	reloadUpdater   chan struct{} // notifies the updater worker about the config reload
+	reloadListeners chan struct{} // notifies the state request handler about the config reload
+
+	frozen   atomic.Bool        // whether the new service events are ignored and the announces are kept on stop
 	shutdown *shutdown.Shutdown // shutdown mechanism to handle graceful termination
+	metrics  *Metrics
 	log      *log.Logger
 }
 
 // New creates a new instance of Announcer.
-func New(config *Config, client Client, logger *log.Logger) *Announcer {
+func New(config *Config, client Client, provider metrics.Provider, logger *log.Logger) *Announcer {
 	return &Announcer{
 		config:               config,
+		running:              make(map[string]struct{}),
 		client:               client,
 		prefixes:             NewPrefixes(),
 		serviceEventRegistry: event.NewRegistry[key.Service, ServiceStatus](),
-		announceGroups:       NewAnnounceGroupRegistry(config.AnnounceGroup),
+		announceGroups:       NewAnnounceGroupRegistry(config.Groups()),
+		sent:                 newSentRegistry(),
+		damper:               newDamper(config.dampings()),
+		pending:              make(map[string]map[netip.Prefix]PrefixStatus),
+		synced:               make(map[string]time.Time),
+		updated:              make(map[string]time.Time),
+		failed:               make(map[string]int),
+		retry:                make(map[string]time.Time),
+		reloadUpdater:        make(chan struct{}, 1),
+		reloadListeners:      make(chan struct{}, 1),
 		shutdown:             shutdown.New(),
+		metrics:              NewMetrics(provider),
 		log:                  logger,
 	}
 }
@@ -104,8 +143,11 @@ func (m *Announcer) Run() error {
 }
 
 // RegisterServiceEvent stores passed status of the service to the event
-// registry.
+// registry. The events are ignored if the announcer is frozen.
 func (m *Announcer) RegisterServiceEvent(service key.Service, status ServiceStatus) {
+	if m.frozen.Load() {
+		return
+	}
 	m.serviceEventRegistry.Store(service, status)
 }
 
@@ -120,57 +162,259 @@ func (m *Announcer) FlushServiceEvents() map[key.Service]ServiceStatus {
 // Changing the status of service may change it's host prefix announce depending
 // on the status of other services with the same host prefix.
 func (m *Announcer) UpdateService(service key.Service, status ServiceStatus) error {
-	_, exists := m.announceGroups.GetGroup(service.Prefix())
+	prefix, exists := m.prefixes.Prefix(service)
 	if !exists {
-		return fmt.Errorf("failed to determine announce group for the prefix %q", service.Prefix())
+		return fmt.Errorf("%w, service: %s", ErrPrefixNotFound, service)
+	}
+	if _, exists := m.announceGroups.GetGroup(prefix); !exists {
+		return fmt.Errorf("failed to determine announce group for the prefix %q", prefix)
 	}
 
 	return m.prefixes.UpdateService(service, status)
 }
 
+// Prefixes returns the current information about all known prefixes, including
+// the services preventing the unready prefixes from being announced.
+func (m *Announcer) Prefixes() []PrefixInfo {
+	return m.prefixes.Info()
+}
+
+// AnnounceInfo represents the current state of the prefix together with the
+// state delivered to an external announcer instance.
+type AnnounceInfo struct {
+	PrefixInfo
+	// Announced is the prefix status delivered by the last successful send.
+	Announced PrefixStatus
+	// Sent is the time of the last successful send of the prefix status. It is
+	// zero if the status has not been sent yet.
+	Sent time.Time
+	// Pinned reports whether the prefix is pinned withdrawn by the damping.
+	Pinned bool
+}
+
+// Announces returns the current state of all known prefixes along with the
+// states delivered to an external announcer instance.
+func (m *Announcer) Announces() []AnnounceInfo {
+	prefixes := m.prefixes.Info()
+	announces := make([]AnnounceInfo, 0, len(prefixes))
+	for _, prefix := range prefixes {
+		prefixKey := PrefixKey{Prefix: prefix.Prefix, Group: prefix.Group}
+		info := AnnounceInfo{
+			PrefixInfo: prefix,
+			Announced:  Unready,
+			Pinned:     m.damper.Pinned(prefixKey),
+		}
+		if sent, exists := m.sent.Get(prefixKey); exists {
+			info.Announced = sent.status
+			info.Sent = sent.ts
+		}
+		announces = append(announces, info)
+	}
+	return announces
+}
+
 // ReloadServices reloads the list of services for each prefix. Its also updates
-// current host prefix statuses according to the new services configuration.
-func (m *Announcer) ReloadServices(services map[key.Service]string) error {
+// current prefix statuses according to the new services configuration.
+//
+// The services without the announce policy get the default policy of their
+// announce group.
+func (m *Announcer) ReloadServices(services map[key.Service]ServiceAnnounce) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := m.reloadServices(m.config, services); err != nil {
+		return err
+	}
+	m.services = services
+	return nil
+}
+
+// ValidateServices checks that the services can be applied by ReloadServices
+// without applying them.
+func (m *Announcer) ValidateServices(services map[key.Service]ServiceAnnounce) error {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	_, _, err := m.resolveServices(m.config, services)
+	return err
+}
+
+// Reload applies the new announcer configuration. The announce groups may be
+// added, removed or changed, but the groups removed must not be used by the
+// current services. The prefixes of the removed groups are withdrawn only once
+// the reload is applied, so the failed reload keeps them announced.
+func (m *Announcer) Reload(config *Config) error {
+	if config == nil {
+		return fmt.Errorf("announcer is not configured")
+	}
+	if err := config.Validate(); err != nil {
+		return fmt.Errorf("invalid announcer config: %w", err)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	// Check that the services do not lose their announce groups.
+	for service, announce := range m.services {
+		if _, exists := config.Group(announce.Group); !exists {
+			return fmt.Errorf("announce group %q is used by service %s", announce.Group, service)
+		}
+	}
+
+	// Check that the services are valid with the new default policies of the
+	// groups before anything is changed.
+	if _, _, err := m.resolveServices(config, m.services); err != nil {
+		return err
+	}
+
+	var removed []*GroupConfig
+	for _, group := range m.config.ResolvedGroups() {
+		if _, exists := config.Group(group.Name); !exists {
+			removed = append(removed, group)
+		}
+	}
+
+	// Let the client serve the new set of groups. The removed groups are
+	// served until their prefixes are withdrawn.
+	client, implements := m.client.(GroupReloader)
+	if implements {
+		groups := append(config.ResolvedGroups(), removed...)
+		if err := client.ReloadGroups(groups); err != nil {
+			return fmt.Errorf("failed to reload announcer client: %w", err)
+		}
+	} else if !slices.Equal(m.config.Groups(), config.Groups()) {
+		return fmt.Errorf("announcer client does not support reloading of announce groups")
+	}
+
+	m.config = config
+	m.announceGroups.SetGroups(config.Groups())
+	m.damper.Reload(config.dampings())
+	// Re-apply the services, as the default policies of the groups may have
+	// been changed. The services are already validated, so it does not fail.
+	_ = m.reloadServices(config, m.services)
+
+	// The reload is applied, so the prefixes of the removed groups are
+	// withdrawn and the clients stop serving the groups.
+	for _, group := range removed {
+		if err := m.withdrawGroup(group.Name); err != nil {
+			m.log.Error(
+				"failed to withdraw announces of removed group",
+				log.String("group_name", group.Name),
+				log.Error(err),
+			)
+		}
+	}
+	if len(removed) > 0 {
+		if err := client.ReloadGroups(config.ResolvedGroups()); err != nil {
+			m.log.Error("failed to release removed announce groups", log.Error(err))
+		}
+	}
+
+	// Notify the workers about the new configuration.
+	notify(m.reloadUpdater)
+	notify(m.reloadListeners)
+	return nil
+}
+
+// reloadServices validates the services against the configuration and applies
+// them. It assumes the mutex is held.
+func (m *Announcer) reloadServices(config *Config, services map[key.Service]ServiceAnnounce) error {
+	resolved, groupByPrefix, err := m.resolveServices(config, services)
+	if err != nil {
+		return err
+	}
+
+	m.prefixes.ReloadServices(resolved)
+	m.announceGroups.Update(groupByPrefix)
+
+	return nil
+}
+
+// resolveServices validates the services against the configuration. It returns
+// the services with the default policies of their groups applied along with
+// the mapping of the prefixes to their announce groups. It assumes the mutex is
+// held.
+func (m *Announcer) resolveServices(config *Config, services map[key.Service]ServiceAnnounce) (map[key.Service]ServiceAnnounce, map[netip.Prefix]string, error) {
 	// Construct mapping of prefixes to their announce group.
 	groupByPrefix := make(map[netip.Prefix]string)
-	for service, group := range services {
+	policyByPrefix := make(map[netip.Prefix]Policy)
+	resolved := make(map[key.Service]ServiceAnnounce, len(services))
+	for service, announce := range services {
+		group := announce.Group
 		// Validate announce group.
 		if !m.announceGroups.ContainsGroup(group) {
-			return fmt.Errorf("%w: %q", ErrUnknownGroup, group)
+			return nil, nil, fmt.Errorf("%w %q of service %s", ErrUnknownGroup, group, service)
+		}
+		// Use the default policy of the group if the service has none.
+		if announce.Policy.Mode == "" {
+			announce.Policy = config.GetPolicy(group)
+		}
+		resolved[service] = announce
+		// Validate announce policy.
+		if err := announce.Policy.Validate(); err != nil {
+			return nil, nil, err
 		}
 
-		prefix := service.Prefix()
+		prefix := announcePrefix(service, announce)
+		if !prefix.Contains(service.Addr) {
+			return nil, nil, fmt.Errorf("service %s is outside of its announce prefix %s", service, prefix)
+		}
+
+		// Prevent different policies of the same prefix.
+		if knownPolicy, exists := policyByPrefix[prefix]; exists && knownPolicy != announce.Policy {
+			return nil, nil, fmt.Errorf("conflicting announce policies of prefix: %s", prefix)
+		}
+		policyByPrefix[prefix] = announce.Policy
 
 		// Prevent duplication of prefixes in differrent groups.
 		if knownGroup, exists := groupByPrefix[prefix]; exists && knownGroup != group {
-			return fmt.Errorf("duplicate announce group prefix: %s", prefix)
+			return nil, nil, fmt.Errorf("duplicate announce group prefix: %s", prefix)
 		}
 
 		groupByPrefix[prefix] = group
 	}
 
-	m.prefixes.ReloadServices(services)
-	m.announceGroups.Update(groupByPrefix)
-
-	return nil
+	return resolved, groupByPrefix, nil
 }
 
 // Stop gracefully stops the Announcer.
-// It triggers the shutdown mechanism, removes all announces, and shuts down the
-// announcer client.
+// It triggers the shutdown mechanism, removes all announces unless the
+// announcer is frozen, and shuts down the announcer client.
 func (m *Announcer) Stop() {
 	// Signal shutdown to ongoing processes.
 	m.shutdown.Do()
-	// Remove all prefix announces.
-	m.removeAll()
+	// Remove all prefix announces unless they are kept.
+	if !m.frozen.Load() {
+		m.removeAll()
+	}
 	// Shut down the announcer client.
 	m.client.Shutdown()
 }
 
+// Freeze makes the announcer ignore the new service events and keep the
+// announces on stop. It is used to stop Monalive without withdrawing the
+// prefixes.
+//
+// The prefix updates not sent yet are dropped, so the external announcer keeps
+// the last delivered state. The announces are kept by the backends outliving
+// Monalive only: the "bgp" backend closes its sessions on stop, so the peers
+// withdraw the prefixes anyway.
+func (m *Announcer) Freeze() {
+	m.frozen.Store(true)
+}
+
+// currentConfig returns the current announcer configuration. The returned
+// configuration is never modified, so it is safe to use without the mutex.
+func (m *Announcer) currentConfig() *Config {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.config
+}
+
 // updater periodically sends new updated prefix statuses to an external
-// announcer instance.
+// announcer instance. It ticks with the shortest update period of the groups.
 func (m *Announcer) updater() {
-	updateTicker := time.NewTicker(m.config.UpdatePeriod)
+	updateTicker := time.NewTicker(m.currentConfig().minUpdatePeriod())
 	defer updateTicker.Stop()
 
 	for {
@@ -179,40 +423,140 @@ func (m *Announcer) updater() {
 			// Exit if a shutdown signal is received.
 			return
 
-		case <-updateTicker.C:
-			// Check and process any prefix status updates.
-			events := m.prefixes.Events()
-			if len(events) == 0 {
-				continue
-			}
+		case <-m.reloadUpdater:
+			// The update periods of the groups may have been changed.
+			updateTicker.Reset(m.currentConfig().minUpdatePeriod())
 
-			// Group the events by announce group.
-			eventsByGroup := make(map[string]map[netip.Prefix]PrefixStatus)
-			for prefixKey, status := range events {
-				prefix := prefixKey.Prefix
-				group := prefixKey.Group
-				if _, exists := eventsByGroup[group]; !exists {
-					eventsByGroup[group] = make(map[netip.Prefix]PrefixStatus)
-				}
-				eventsByGroup[group][prefix] = status
-			}
+		case now := <-updateTicker.C:
+			m.update(now)
+		}
+	}
+}
 
-			// Send the update events to the client for processing.
-			for group, events := range eventsByGroup {
-				if err := m.client.ProcessBatch(group, events); err != nil {
-					m.log.Error(
-						"failed to sync announces state",
-						log.String("group_name", group),
-						log.Error(err),
-					)
-				}
-			}
+// update sends the prefix status updates to an external announcer instance.
+// The updates failed to be sent are re-queued and retried with the backoff
+// growing with each consecutive failure of the group.
+// The full state of the group is pushed if the resync period of the group has
+// passed.
+//
+// It must be called only from the updater worker.
+func (m *Announcer) update(now time.Time) {
+	config := m.currentConfig()
+
+	// Check and process any prefix status updates. The damper also releases
+	// the delayed updates, so it is called on every tick.
+	events := m.damper.Process(m.prefixes.Events(), now)
+
+	// Group the events by announce group on top of the re-queued ones, as the
+	// new events are more recent.
+	eventsByGroup := m.pending
+	m.pending = make(map[string]map[netip.Prefix]PrefixStatus)
+	for prefixKey, status := range events {
+		prefix := prefixKey.Prefix
+		group := prefixKey.Group
+		if _, exists := eventsByGroup[group]; !exists {
+			eventsByGroup[group] = make(map[netip.Prefix]PrefixStatus)
 		}
+		eventsByGroup[group][prefix] = status
 	}
+
+	// The groups are updated with their own periods. The ticks are not
+	// precise, so the group is considered due within half of the tick.
+	tolerance := config.minUpdatePeriod() / 2
+	groups := config.Groups()
+	due := make(map[string]bool, len(groups))
+	for _, group := range groups {
+		due[group] = now.Sub(m.updated[group]) >= config.GetUpdatePeriod(group)-tolerance
+	}
+
+	// Push the full state of the groups the resync period has passed for.
+	for _, group := range groups {
+		period := config.GetResyncPeriod(group)
+		if !due[group] || period <= 0 || now.Sub(m.synced[group]) < period {
+			continue
+		}
+		// The full state supersedes the updates of the group.
+		eventsByGroup[group] = m.groupStatus(group)
+		m.synced[group] = now
+	}
+
+	// Send the update events to the client for processing.
+	for group, events := range eventsByGroup {
+		if len(events) == 0 {
+			continue
+		}
+		if _, exists := due[group]; !exists {
+			// The group has been removed and its prefixes are withdrawn, so
+			// its updates are dropped.
+			m.log.Warn(
+				"dropping updates of removed announce group",
+				log.String("group_name", group),
+				log.Int("updates", len(events)),
+			)
+			continue
+		}
+		ready := due[group]
+		if m.failed[group] > 0 {
+			// The failed updates are retried once the backoff passes
+			// regardless of the update period of the group.
+			ready = !now.Before(m.retry[group])
+		}
+		if !ready {
+			// Keep the updates until the update period or the retry backoff
+			// of the group passes.
+			m.pending[group] = events
+			continue
+		}
+		if err := m.processBatch(group, events); err != nil {
+			m.failed[group]++
+			backoff := config.GetRetryBackoff(group, m.failed[group])
+			m.retry[group] = now.Add(backoff)
+			m.log.Error(
+				"failed to sync announces state",
+				log.String("group_name", group),
+				log.Duration("retry_in", backoff),
+				log.Error(err),
+			)
+			// Re-queue the failed updates to retry them after the backoff.
+			m.pending[group] = events
+			m.metrics.FailedUpdates(group).Add(float64(len(events)))
+			continue
+		}
+		delete(m.failed, group)
+		delete(m.retry, group)
+		m.damper.Delivered(group, events, now)
+		m.updated[group] = now
+	}
+
+	// Forget the failures of the removed groups.
+	for group := range m.failed {
+		if _, exists := due[group]; !exists {
+			delete(m.failed, group)
+			delete(m.retry, group)
+		}
+	}
+
+	// Account the updates waiting to be sent.
+	for _, group := range groups {
+		m.metrics.PendingUpdates(group).Set(float64(len(m.pending[group])))
+	}
+}
+
+// groupStatus returns the current status of the prefixes of the group
+// respecting the damping of the prefixes.
+func (m *Announcer) groupStatus(group string) map[netip.Prefix]PrefixStatus {
+	prefixes := m.announceGroups.GetPrefixes(group)
+	status := m.prefixes.StatusFor(prefixes)
+	for prefix, current := range status {
+		status[prefix] = m.damper.Status(PrefixKey{Prefix: prefix, Group: group}, current)
+	}
+	return status
 }
 
 // stateRequestHandler listens for state requests if the client supports it. It
-// responds with the current status of prefixes for each announce group.
+// responds with the current status of prefixes for each announce group. The
+// listeners of the groups are started and stopped along with the config
+// reloads.
 func (m *Announcer) stateRequestHandler() error {
 	client, implements := m.client.(Stater)
 	if !implements {
@@ -220,53 +564,129 @@ func (m *Announcer) stateRequestHandler() error {
 		return nil
 	}
 
-	var wg errgroup.Group
-	// Listen for state requests for each group.
-	for _, group := range m.config.AnnounceGroup {
-		wg.Go(func() error {
-			for {
-				// Handle state requests for the group.
-				if err := client.ListenStateRequest(group); err != nil {
-					if errors.Is(err, ErrShutdown) {
-						// Stop the worker on shutdown.
-						return err
-					}
-					// Other errors must be logged, but does not terminate the
-					// lifecycle of the worker.
-					m.log.Error(
-						"failed to handle state request",
-						log.String("group_name", group),
-						log.Error(err),
-					)
-					continue
-				}
+	var wg sync.WaitGroup
+	for {
+		// Start the listeners of the groups that have none.
+		m.mu.Lock()
+		for _, group := range m.config.Groups() {
+			if _, exists := m.running[group]; exists {
+				continue
+			}
+			m.running[group] = struct{}{}
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				m.listenStateRequests(client, group)
+			}()
+		}
+		m.mu.Unlock()
+
+		select {
+		case <-m.shutdown.Done():
+			// Wait for all state request handling goroutines to complete.
+			wg.Wait()
+			return ErrShutdown
+
+		case <-m.reloadListeners:
+		}
+	}
+}
 
-				// Retrieve the current prefix statuses for the requested group.
-				prefixes := m.announceGroups.GetPrefixes(group)
-				status := m.prefixes.StatusFor(prefixes)
-
-				// Respond with the current prefix statuses for the requested
-				// group.
-				if err := m.client.ProcessBatch(group, status); err != nil {
-					m.log.Error(
-						"failed to sync announces state",
-						log.String("group_name", group),
-						log.Error(err),
-					)
+// listenStateRequests handles the state requests of the group. It returns on
+// the announcer shutdown or once the group is removed.
+func (m *Announcer) listenStateRequests(client Stater, group string) {
+	for {
+		// Handle state requests for the group.
+		if err := client.ListenStateRequest(group); err != nil {
+			if errors.Is(err, ErrShutdown) {
+				// The client of the group is shut down either because the
+				// announcer is stopped or because the group is reloaded.
+				if m.stopListening(group) {
+					return
 				}
+				continue
 			}
-		})
+			// Other errors must be logged, but does not terminate the
+			// lifecycle of the worker.
+			m.log.Error(
+				"failed to handle state request",
+				log.String("group_name", group),
+				log.Error(err),
+			)
+			continue
+		}
+
+		// Retrieve the current prefix statuses for the requested group.
+		status := m.groupStatus(group)
+
+		// Respond with the current prefix statuses for the requested
+		// group.
+		if err := m.processBatch(group, status); err != nil {
+			m.log.Error(
+				"failed to sync announces state",
+				log.String("group_name", group),
+				log.Error(err),
+			)
+		}
 	}
+}
 
-	// Wait for all state request handling goroutines to complete.
-	return wg.Wait()
+// stopListening reports whether the state request listener of the group must
+// be stopped. It unregisters the listener if so.
+func (m *Announcer) stopListening(group string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	select {
+	case <-m.shutdown.Done():
+	default:
+		if _, exists := m.config.Group(group); exists {
+			return false
+		}
+	}
+	delete(m.running, group)
+	return true
+}
+
+// processBatch sends the batch of prefix updates of the group to the client.
+// On success it records the delivered statuses, otherwise it accounts the
+// failure.
+func (m *Announcer) processBatch(group string, prefixes map[netip.Prefix]PrefixStatus) error {
+	if err := m.client.ProcessBatch(group, prefixes); err != nil {
+		m.metrics.SendFailures(group).Inc()
+		return err
+	}
+
+	known := func(prefix netip.Prefix) bool {
+		knownGroup, exists := m.announceGroups.GetGroup(prefix)
+		return exists && knownGroup == group
+	}
+	announced := m.sent.Store(group, prefixes, known)
+	m.metrics.Announced(group).Set(float64(announced))
+	return nil
+}
+
+// withdrawGroup withdraws the prefixes of the group announced to the external
+// announcer. It is used to remove the group, which has no services anymore, so
+// the prefixes are taken from the delivered state.
+func (m *Announcer) withdrawGroup(group string) error {
+	prefixes := m.sent.Announced(group)
+	if len(prefixes) == 0 {
+		return nil
+	}
+
+	prefixesStatus := make(map[netip.Prefix]PrefixStatus, len(prefixes))
+	for _, prefix := range prefixes {
+		prefixesStatus[prefix] = Unready
+	}
+	return m.processBatch(group, prefixesStatus)
 }
 
 // removeAll removes all prefix announces for every known group.
 // This is typically called during the shutdown process to ensure no announces
 // remain active.
 func (m *Announcer) removeAll() {
-	for _, group := range m.config.AnnounceGroup {
+	for _, group := range m.currentConfig().Groups() {
 		prefixes := m.announceGroups.GetPrefixes(group)
 		if len(prefixes) == 0 {
 			continue
@@ -280,7 +700,7 @@ func (m *Announcer) removeAll() {
 		}
 
 		// Send the removal updates to the client.
-		if err := m.client.ProcessBatch(group, prefixesStatus); err != nil {
+		if err := m.processBatch(group, prefixesStatus); err != nil {
 			m.log.Error(
 				"failed to remove announces",
 				log.String("group_name", group),
@@ -313,6 +733,17 @@ func NewAnnounceGroupRegistry(groups []string) *AnnounceGroupRegistry {
 	}
 }
 
+// SetGroups replaces the list of known groups.
+func (m *AnnounceGroupRegistry) SetGroups(groups []string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	groupSet := make(map[string]struct{}, len(groups))
+	for _, group := range groups {
+		groupSet[group] = struct{}{}
+	}
+	m.groups = groupSet
+}
+
 // GetGroup retrieves the announce group associated with the given
 // prefix.
 func (m *AnnounceGroupRegistry) GetGroup(prefix netip.Prefix) (group string, exists bool) {
@@ -350,3 +781,11 @@ func (m *AnnounceGroupRegistry) Update(groupByPrefix map[netip.Prefix]string) {
 
 	m.groupByPrefix = groupByPrefix
 }
+
+// notify sends a non-blocking notification to the channel.
+func notify(ch chan struct{}) {
+	select {
+	case ch <- struct{}{}:
+	default:
+	}
+}
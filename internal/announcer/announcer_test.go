@@ -0,0 +1,298 @@
+package announcer
+
+import (
+	"errors"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	log "go.uber.org/zap"
+
+	"github.com/yanet-platform/monalive/internal/monitoring/metrics"
+	"github.com/yanet-platform/monalive/internal/types/key"
+)
+
+// fakeClient is an announcer client recording the processed batches. It fails
+// the batches while err is set.
+type fakeClient struct {
+	batches  []map[netip.Prefix]PrefixStatus
+	err      error
+	shutdown bool
+}
+
+func (m *fakeClient) RaiseAnnounce(string, netip.Prefix) error  { return m.err }
+func (m *fakeClient) RemoveAnnounce(string, netip.Prefix) error { return m.err }
+func (m *fakeClient) Shutdown()                                 { m.shutdown = true }
+
+func (m *fakeClient) ProcessBatch(_ string, prefixes map[netip.Prefix]PrefixStatus) error {
+	if m.err != nil {
+		return m.err
+	}
+	m.batches = append(m.batches, prefixes)
+	return nil
+}
+
+// TestAnnouncer_Announces tests that the announces report the prefix states
+// along with the states delivered by the last successful send.
+func TestAnnouncer_Announces(t *testing.T) {
+	client := &fakeClient{}
+	announcer := New(&Config{AnnounceGroup: []*GroupConfig{{Name: "default"}}}, client, &metrics.NopProvider{}, log.NewNop())
+
+	services, servicesWithGroups := defaultServices()
+	require.NoError(t, announcer.ReloadServices(servicesWithGroups))
+	prefix := services[0].Prefix()
+
+	require.NoError(t, announcer.UpdateService(services[0], ServiceEnabled))
+	require.NoError(t, announcer.UpdateService(services[1], ServiceEnabled))
+
+	announces := announcer.Announces()
+	require.Len(t, announces, 2)
+	assert.Equal(t, prefix, announces[0].Prefix)
+	assert.Equal(t, Ready, announces[0].Status)
+	assert.Equal(t, Unready, announces[0].Announced)
+	assert.False(t, announces[0].Changed.IsZero())
+	assert.True(t, announces[0].Sent.IsZero())
+	assert.Len(t, announces[0].Services, 2)
+
+	// The failed send is not recorded.
+	client.err = errors.New("send failed")
+	require.Error(t, announcer.processBatch("default", map[netip.Prefix]PrefixStatus{prefix: Ready}))
+	assert.True(t, announcer.Announces()[0].Sent.IsZero())
+
+	client.err = nil
+	require.NoError(t, announcer.processBatch("default", map[netip.Prefix]PrefixStatus{prefix: Ready}))
+	announces = announcer.Announces()
+	assert.Equal(t, Ready, announces[0].Announced)
+	assert.False(t, announces[0].Sent.IsZero())
+	// The other prefix has never been sent.
+	assert.True(t, announces[1].Sent.IsZero())
+}
+
+// TestAnnouncer_Update_Requeue tests that the updates failed to be sent are
+// sent again once the retry backoff passes.
+func TestAnnouncer_Update_Requeue(t *testing.T) {
+	client := &fakeClient{}
+	announcer := New(&Config{AnnounceGroup: []*GroupConfig{{Name: "default"}}}, client, &metrics.NopProvider{}, log.NewNop())
+
+	service, serviceWithGroup := defaultService()
+	require.NoError(t, announcer.ReloadServices(serviceWithGroup))
+	require.NoError(t, announcer.UpdateService(service, ServiceEnabled))
+
+	start := time.Now()
+	client.err = errors.New("send failed")
+	announcer.update(start)
+	assert.Empty(t, client.batches)
+
+	client.err = nil
+	announcer.update(start.Add(defaultUpdatePeriod))
+	expected := map[netip.Prefix]PrefixStatus{service.Prefix(): Ready}
+	assert.Equal(t, []map[netip.Prefix]PrefixStatus{expected}, client.batches)
+
+	// Nothing is left to be sent.
+	announcer.update(start.Add(2 * defaultUpdatePeriod))
+	assert.Len(t, client.batches, 1)
+}
+
+// TestAnnouncer_Update_RetryBackoff tests that the retry delay of the failed
+// updates doubles on each consecutive failure up to the maximum and is reset
+// once the updates are sent.
+func TestAnnouncer_Update_RetryBackoff(t *testing.T) {
+	client := &fakeClient{err: errors.New("send failed")}
+	config := &Config{
+		AnnounceGroup:   []*GroupConfig{{Name: "default"}},
+		UpdatePeriod:    time.Second,
+		MaxRetryBackoff: 3 * time.Second,
+	}
+	announcer := New(config, client, &metrics.NopProvider{}, log.NewNop())
+
+	service, serviceWithGroup := defaultService()
+	require.NoError(t, announcer.ReloadServices(serviceWithGroup))
+	require.NoError(t, announcer.UpdateService(service, ServiceEnabled))
+
+	now := time.Now()
+	announcer.update(now)
+	for _, backoff := range []time.Duration{time.Second, 2 * time.Second, 3 * time.Second, 3 * time.Second} {
+		assert.Equal(t, now.Add(backoff), announcer.retry["default"])
+		// The updates are not retried before the backoff passes.
+		announcer.update(now.Add(backoff - time.Millisecond))
+		assert.Len(t, announcer.pending["default"], 1)
+		assert.Equal(t, now.Add(backoff), announcer.retry["default"])
+		now = now.Add(backoff)
+		announcer.update(now)
+	}
+
+	client.err = nil
+	now = now.Add(3 * time.Second)
+	announcer.update(now)
+	assert.Len(t, client.batches, 1)
+	assert.Empty(t, announcer.failed)
+	assert.Empty(t, announcer.retry)
+}
+
+// TestAnnouncer_Update_Resync tests that the full state of the group is pushed
+// periodically.
+func TestAnnouncer_Update_Resync(t *testing.T) {
+	client := &fakeClient{}
+	config := &Config{AnnounceGroup: []*GroupConfig{{Name: "default"}}, ResyncPeriod: time.Minute}
+	announcer := New(config, client, &metrics.NopProvider{}, log.NewNop())
+
+	services, servicesWithGroups := defaultServices()
+	require.NoError(t, announcer.ReloadServices(servicesWithGroups))
+	require.NoError(t, announcer.UpdateService(services[0], ServiceEnabled))
+	require.NoError(t, announcer.UpdateService(services[1], ServiceEnabled))
+
+	fullState := map[netip.Prefix]PrefixStatus{
+		services[0].Prefix(): Ready,
+		services[2].Prefix(): Unready,
+	}
+
+	start := time.Now()
+	announcer.update(start)
+	require.Len(t, client.batches, 1)
+	assert.Equal(t, fullState, client.batches[0])
+
+	// The period has not passed yet.
+	announcer.update(start.Add(time.Second))
+	assert.Len(t, client.batches, 1)
+
+	announcer.update(start.Add(time.Minute))
+	require.Len(t, client.batches, 2)
+	assert.Equal(t, fullState, client.batches[1])
+}
+
+// TestAnnouncer_ValidateServices tests that the services are validated without
+// being applied.
+func TestAnnouncer_ValidateServices(t *testing.T) {
+	announcer := New(&Config{AnnounceGroup: []*GroupConfig{{Name: "default"}}}, &fakeClient{}, &metrics.NopProvider{}, log.NewNop())
+
+	_, servicesWithGroups := defaultServices()
+	require.NoError(t, announcer.ValidateServices(servicesWithGroups))
+	assert.Empty(t, announcer.Prefixes())
+
+	for service, announce := range servicesWithGroups {
+		announce.Group = "unknown"
+		servicesWithGroups[service] = announce
+	}
+	assert.ErrorIs(t, announcer.ValidateServices(servicesWithGroups), ErrUnknownGroup)
+}
+
+// TestAnnouncer_Reload tests that the announce groups can be added and removed
+// at runtime, and the default policies of the groups are applied.
+func TestAnnouncer_Reload(t *testing.T) {
+	client := &fakeClient{}
+	announcer := New(&Config{AnnounceGroup: []*GroupConfig{{Name: "default"}}}, client, &metrics.NopProvider{}, log.NewNop())
+
+	services, servicesWithGroups := defaultServices()
+	for service, announce := range servicesWithGroups {
+		announce.Group = "new"
+		servicesWithGroups[service] = announce
+	}
+	require.ErrorIs(t, announcer.ReloadServices(servicesWithGroups), ErrUnknownGroup)
+
+	// The client does not support the groups reload.
+	require.Error(t, announcer.Reload(&Config{AnnounceGroup: []*GroupConfig{{Name: "default"}, {Name: "new"}}}))
+
+	multi := NewMultiClient(func(Backend, []*GroupConfig) (Client, error) { return client, nil })
+	announcer.client = multi
+	config := &Config{AnnounceGroup: []*GroupConfig{{Name: "default"}, {Name: "new"}}}
+	require.NoError(t, multi.ReloadGroups(announcer.config.ResolvedGroups()))
+	require.NoError(t, announcer.Reload(config))
+	require.NoError(t, announcer.ReloadServices(servicesWithGroups))
+
+	// The group in use can not be removed.
+	require.Error(t, announcer.Reload(&Config{AnnounceGroup: []*GroupConfig{{Name: "default"}}}))
+
+	// The default policy of the group is applied to the services.
+	require.NoError(t, announcer.UpdateService(services[0], ServiceEnabled))
+	assert.Equal(t, Unready, announcer.Prefixes()[0].Status)
+	config = &Config{AnnounceGroup: []*GroupConfig{{Name: "new", Policy: PolicyAny}}}
+	require.NoError(t, announcer.Reload(config))
+	assert.Equal(t, Ready, announcer.Prefixes()[0].Status)
+	assert.Equal(t, "any", announcer.Prefixes()[0].Policy.String())
+}
+
+// TestAnnouncer_Reload_WithdrawRemoved tests that the prefixes announced in the
+// removed group are withdrawn once the reload is applied, while the failed
+// reload keeps them.
+func TestAnnouncer_Reload_WithdrawRemoved(t *testing.T) {
+	client := &fakeClient{}
+	multi := NewMultiClient(func(backend Backend, _ []*GroupConfig) (Client, error) {
+		if backend == BackendExec {
+			return nil, errors.New("failed to create client")
+		}
+		return client, nil
+	})
+	config := &Config{AnnounceGroup: []*GroupConfig{{Name: "default"}, {Name: "old"}}}
+	require.NoError(t, multi.ReloadGroups(config.ResolvedGroups()))
+	announcer := New(config, multi, &metrics.NopProvider{}, log.NewNop())
+
+	service := key.Service{Addr: netip.MustParseAddr("127.0.0.1"), Port: 80, Proto: "TCP"}
+	require.NoError(t, announcer.ReloadServices(map[key.Service]ServiceAnnounce{service: {Group: "old"}}))
+	require.NoError(t, announcer.UpdateService(service, ServiceEnabled))
+	announcer.update(time.Now())
+	require.Len(t, client.batches, 1)
+
+	// The service is moved out of the group to remove it.
+	require.NoError(t, announcer.ReloadServices(map[key.Service]ServiceAnnounce{}))
+
+	// The prefixes are kept if the reload fails.
+	failed := &Config{AnnounceGroup: []*GroupConfig{{Name: "default"}, {Name: "new", Backend: BackendExec}}}
+	require.Error(t, announcer.Reload(failed))
+	assert.Equal(t, []string{"default", "old"}, announcer.config.Groups())
+	assert.Len(t, client.batches, 1)
+
+	require.NoError(t, announcer.Reload(&Config{AnnounceGroup: []*GroupConfig{{Name: "default"}}}))
+	require.Len(t, client.batches, 2)
+	assert.Equal(t, map[netip.Prefix]PrefixStatus{service.Prefix(): Unready}, client.batches[1])
+	// The removed group is no longer served.
+	assert.ErrorIs(t, multi.ProcessBatch("old", nil), ErrUnknownGroup)
+}
+
+// TestAnnouncer_Update_GroupPeriod tests that the updates of the group are
+// sent with the update period of the group.
+func TestAnnouncer_Update_GroupPeriod(t *testing.T) {
+	client := &fakeClient{}
+	config := &Config{
+		UpdatePeriod:  time.Second,
+		AnnounceGroup: []*GroupConfig{{Name: "default", UpdatePeriod: time.Minute}},
+	}
+	announcer := New(config, client, &metrics.NopProvider{}, log.NewNop())
+
+	service, serviceWithGroup := defaultService()
+	require.NoError(t, announcer.ReloadServices(serviceWithGroup))
+	require.NoError(t, announcer.UpdateService(service, ServiceEnabled))
+
+	start := time.Now()
+	announcer.update(start)
+	require.Len(t, client.batches, 1)
+
+	require.NoError(t, announcer.UpdateService(service, ServiceDisabled))
+	announcer.update(start.Add(time.Second))
+	assert.Len(t, client.batches, 1)
+
+	announcer.update(start.Add(time.Minute))
+	require.Len(t, client.batches, 2)
+	assert.Equal(t, map[netip.Prefix]PrefixStatus{service.Prefix(): Unready}, client.batches[1])
+}
+
+// TestAnnouncer_Freeze tests that the frozen announcer ignores the service
+// events and keeps the announces on stop.
+func TestAnnouncer_Freeze(t *testing.T) {
+	client := &fakeClient{}
+	announcer := New(&Config{AnnounceGroup: []*GroupConfig{{Name: "default"}}}, client, &metrics.NopProvider{}, log.NewNop())
+
+	service, serviceWithGroup := defaultService()
+	require.NoError(t, announcer.ReloadServices(serviceWithGroup))
+	require.NoError(t, announcer.UpdateService(service, ServiceEnabled))
+	announcer.update(time.Now())
+	require.Len(t, client.batches, 1)
+
+	announcer.Freeze()
+	announcer.RegisterServiceEvent(service, ServiceDisabled)
+	assert.Empty(t, announcer.FlushServiceEvents())
+
+	announcer.Stop()
+	assert.Len(t, client.batches, 1, "no prefixes are withdrawn")
+}
@@ -0,0 +1,141 @@
+// Package bgp provides an implementation of the [announcer.Client] interface
+// originating the prefixes with the embedded BGP speaker.
+package bgp
+
+import (
+	"errors"
+	"fmt"
+	"net/netip"
+	"sync"
+
+	log "go.uber.org/zap"
+
+	"github.com/yanet-platform/monalive/internal/announcer"
+	xbgp "github.com/yanet-platform/monalive/pkg/bgp"
+)
+
+// BGP is an implementation of the announcer.Client interface that originates
+// the prefixes to the configured BGP peers.
+type BGP struct {
+	config   *Config
+	speakers []*xbgp.Speaker
+	groups   map[string]groupAttributes // path attributes per announce group
+	mu       sync.RWMutex               // to protect the groups map
+}
+
+// New creates a new BGP instance and starts the sessions with the peers.
+// Returns an error if the configuration is invalid.
+func New(config *Config, groups []*announcer.GroupConfig, logger *log.Logger) (*BGP, error) {
+	if config == nil {
+		return nil, fmt.Errorf("bgp is not configured")
+	}
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid bgp config: %w", err)
+	}
+
+	attrs, err := config.attributesByGroup(groups)
+	if err != nil {
+		return nil, err
+	}
+
+	speakers := make([]*xbgp.Speaker, 0, len(config.Peers))
+	for _, peer := range config.Peers {
+		speaker := xbgp.NewSpeaker(xbgp.Config{
+			LocalAS:      config.LocalAS,
+			RouterID:     netip.MustParseAddr(config.RouterID),
+			Peer:         peer.peer(),
+			PeerAS:       peer.PeerAS,
+			HoldTime:     config.HoldTime,
+			ConnectRetry: config.ConnectRetry,
+		}, logger)
+		go speaker.Run()
+		speakers = append(speakers, speaker)
+	}
+
+	return &BGP{
+		config:   config,
+		speakers: speakers,
+		groups:   attrs,
+	}, nil
+}
+
+// ReloadGroups replaces the announce groups served by the instance along with
+// their path attributes. The prefixes already originated keep their attributes
+// until they are announced again.
+func (m *BGP) ReloadGroups(groups []*announcer.GroupConfig) error {
+	attrs, err := m.config.attributesByGroup(groups)
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.groups = attrs
+	return nil
+}
+
+// RaiseAnnounce originates the prefix of the specified group.
+func (m *BGP) RaiseAnnounce(group string, prefix netip.Prefix) error {
+	return m.processAnnounce(group, prefix, true)
+}
+
+// RemoveAnnounce withdraws the prefix of the specified group.
+func (m *BGP) RemoveAnnounce(group string, prefix netip.Prefix) error {
+	return m.processAnnounce(group, prefix, false)
+}
+
+// ProcessBatch originates the ready prefixes of the given group and withdraws
+// the unready ones.
+func (m *BGP) ProcessBatch(group string, announces map[netip.Prefix]announcer.PrefixStatus) error {
+	var errs []error
+	for prefix, status := range announces {
+		if err := m.processAnnounce(group, prefix, status == announcer.Ready); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Shutdown closes the sessions with all peers. The peers withdraw the
+// originated prefixes on their side.
+func (m *BGP) Shutdown() {
+	for _, speaker := range m.speakers {
+		speaker.Shutdown()
+	}
+}
+
+// processAnnounce originates or withdraws the prefix on all sessions. The
+// sessions that are not established advertise the prefix once they are up, so
+// they are not considered as failed.
+func (m *BGP) processAnnounce(group string, prefix netip.Prefix, enable bool) error {
+	m.mu.RLock()
+	attrs, exists := m.groups[group]
+	m.mu.RUnlock()
+	if !exists {
+		return fmt.Errorf("bgp attributes for group %q are not configured", group)
+	}
+
+	nextHop := attrs.nextHop
+	if prefix.Addr().Is6() {
+		nextHop = attrs.nextHopV6
+	}
+	pathAttrs := xbgp.Attributes{
+		NextHop:     nextHop,
+		LocalPref:   attrs.localPref,
+		Communities: attrs.communities,
+	}
+
+	var errs []error
+	for _, speaker := range m.speakers {
+		var err error
+		if enable {
+			err = speaker.Announce(prefix, pathAttrs)
+		} else {
+			err = speaker.Withdraw(prefix)
+		}
+		if err != nil && !errors.Is(err, xbgp.ErrNotEstablished) {
+			errs = append(errs, fmt.Errorf("failed to process prefix %s: %w", prefix, err))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,152 @@
+package bgp
+
+import (
+	"fmt"
+	"net/netip"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yanet-platform/monalive/internal/announcer"
+	xbgp "github.com/yanet-platform/monalive/pkg/bgp"
+)
+
+// Config represents the configuration of the embedded BGP speaker.
+type Config struct {
+	// LocalAS is the AS number of the speaker.
+	LocalAS uint32 `yaml:"local_as"`
+	// RouterID is the BGP identifier of the speaker. It must be an IPv4
+	// address.
+	RouterID string `yaml:"router_id"`
+	// HoldTime is the proposed hold time of the sessions. It must be at least
+	// three seconds.
+	HoldTime time.Duration `yaml:"hold_time"`
+	// ConnectRetry is the delay between the connection attempts.
+	ConnectRetry time.Duration `yaml:"connect_retry"`
+	// Peers is the list of the peers the prefixes are advertised to.
+	Peers []*PeerConfig `yaml:"peers"`
+}
+
+// PeerConfig represents the configuration of a BGP peer.
+type PeerConfig struct {
+	// Address of the peer.
+	Address string `yaml:"address"`
+	// Port of the peer. Defaults to 179.
+	Port uint16 `yaml:"port"`
+	// AS number of the peer.
+	PeerAS uint32 `yaml:"peer_as"`
+}
+
+// Default sets the default values for the configuration.
+func (m *Config) Default() {
+	m.HoldTime = 90 * time.Second
+	m.ConnectRetry = 5 * time.Second
+}
+
+// Validate checks the configuration.
+func (m *Config) Validate() error {
+	if m.LocalAS == 0 {
+		return fmt.Errorf("local AS is not set")
+	}
+	if routerID, err := netip.ParseAddr(m.RouterID); err != nil || !routerID.Is4() {
+		return fmt.Errorf("invalid router ID: %q", m.RouterID)
+	}
+	if m.HoldTime != 0 && m.HoldTime < xbgp.MinHoldTime {
+		return fmt.Errorf("hold time %s is less than %s", m.HoldTime, xbgp.MinHoldTime)
+	}
+	if len(m.Peers) == 0 {
+		return fmt.Errorf("no peers configured")
+	}
+	for _, peer := range m.Peers {
+		if _, err := netip.ParseAddr(peer.Address); err != nil {
+			return fmt.Errorf("invalid peer address: %q", peer.Address)
+		}
+		if peer.PeerAS == 0 {
+			return fmt.Errorf("peer %s AS is not set", peer.Address)
+		}
+	}
+	return nil
+}
+
+// peer returns the address of the peer.
+func (m *PeerConfig) peer() netip.AddrPort {
+	port := m.Port
+	if port == 0 {
+		port = 179
+	}
+	return netip.AddrPortFrom(netip.MustParseAddr(m.Address), port)
+}
+
+// groupAttributes are the parsed path attributes of the announce group.
+type groupAttributes struct {
+	nextHop     netip.Addr
+	nextHopV6   netip.Addr
+	localPref   *uint32
+	communities []uint32
+}
+
+// attributesByGroup returns the parsed path attributes of the announce groups.
+func (m *Config) attributesByGroup(groups []*announcer.GroupConfig) (map[string]groupAttributes, error) {
+	attrs := make(map[string]groupAttributes, len(groups))
+	for _, group := range groups {
+		groupAttrs, err := m.groupAttributes(group)
+		if err != nil {
+			return nil, err
+		}
+		attrs[group.Name] = groupAttrs
+	}
+	return attrs, nil
+}
+
+// groupAttributes returns the parsed path attributes of the announce group set
+// in the announce group configuration.
+func (m *Config) groupAttributes(group *announcer.GroupConfig) (groupAttributes, error) {
+	if group.BGP == nil {
+		return groupAttributes{}, fmt.Errorf("bgp attributes for group %q are not configured", group.Name)
+	}
+	attrs, err := attributes(group.BGP)
+	if err != nil {
+		return attrs, fmt.Errorf("announce group %q: %w", group.Name, err)
+	}
+	return attrs, nil
+}
+
+// attributes parses the path attributes of the group.
+func attributes(m *announcer.BGPAttributes) (attrs groupAttributes, err error) {
+	if m.NextHop != "" {
+		if attrs.nextHop, err = netip.ParseAddr(m.NextHop); err != nil || !attrs.nextHop.Is4() {
+			return attrs, fmt.Errorf("invalid next hop: %q", m.NextHop)
+		}
+	}
+	if m.NextHopV6 != "" {
+		if attrs.nextHopV6, err = netip.ParseAddr(m.NextHopV6); err != nil || !attrs.nextHopV6.Is6() {
+			return attrs, fmt.Errorf("invalid IPv6 next hop: %q", m.NextHopV6)
+		}
+	}
+	attrs.localPref = m.LocalPref
+	for _, community := range m.Communities {
+		value, err := parseCommunity(community)
+		if err != nil {
+			return attrs, err
+		}
+		attrs.communities = append(attrs.communities, value)
+	}
+	return attrs, nil
+}
+
+// parseCommunity parses the standard community in the "ASN:value" format.
+func parseCommunity(community string) (uint32, error) {
+	asn, value, found := strings.Cut(community, ":")
+	if !found {
+		return 0, fmt.Errorf("invalid community: %q", community)
+	}
+	high, err := strconv.ParseUint(asn, 10, 16)
+	if err != nil {
+		return 0, fmt.Errorf("invalid community: %q", community)
+	}
+	low, err := strconv.ParseUint(value, 10, 16)
+	if err != nil {
+		return 0, fmt.Errorf("invalid community: %q", community)
+	}
+	return uint32(high)<<16 | uint32(low), nil
+}
@@ -0,0 +1,67 @@
+package bgp
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yanet-platform/monalive/internal/announcer"
+)
+
+// TestAttributes tests the parsing of the announce group path attributes.
+func TestAttributes(t *testing.T) {
+	config := &announcer.BGPAttributes{
+		NextHop:     "192.0.2.1",
+		NextHopV6:   "2001:db8::1",
+		Communities: []string{"65000:100", "65000:65535"},
+	}
+	attrs, err := attributes(config)
+	require.NoError(t, err)
+	assert.Equal(t, []uint32{65000<<16 | 100, 65000<<16 | 65535}, attrs.communities)
+
+	for _, config := range []*announcer.BGPAttributes{
+		{NextHop: "2001:db8::1"},
+		{NextHopV6: "192.0.2.1"},
+		{Communities: []string{"65000"}},
+		{Communities: []string{"65000:65536"}},
+	} {
+		_, err := attributes(config)
+		assert.Error(t, err)
+	}
+}
+
+// TestConfig_GroupAttributes tests that the attributes are taken from the
+// announce group configuration.
+func TestConfig_GroupAttributes(t *testing.T) {
+	config := &Config{}
+
+	attrs, err := config.groupAttributes(&announcer.GroupConfig{
+		Name: "g-1",
+		BGP:  &announcer.BGPAttributes{NextHop: "192.0.2.1"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "192.0.2.1", attrs.nextHop.String())
+
+	_, err = config.groupAttributes(&announcer.GroupConfig{Name: "g-2"})
+	assert.Error(t, err)
+}
+
+// TestConfig_HoldTime tests that the hold time of less than three seconds is
+// rejected, while the zero value stands for the default one.
+func TestConfig_HoldTime(t *testing.T) {
+	config := &Config{
+		LocalAS:  65000,
+		RouterID: "192.0.2.1",
+		Peers:    []*PeerConfig{{Address: "192.0.2.254", PeerAS: 65000}},
+	}
+	for _, holdTime := range []time.Duration{0, 3 * time.Second, 90 * time.Second} {
+		config.HoldTime = holdTime
+		assert.NoError(t, config.Validate())
+	}
+	for _, holdTime := range []time.Duration{time.Second, 2 * time.Second, 2900 * time.Millisecond} {
+		config.HoldTime = holdTime
+		assert.Error(t, config.Validate())
+	}
+}
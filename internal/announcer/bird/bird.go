@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"net"
 	"net/netip"
+	"sync"
 
 	xbird "github.com/yanet-platform/monalive/pkg/bird"
 
@@ -16,25 +17,85 @@ import (
 // Bird is an implementation of the announcer.Client interface that interacts
 // with the BIRD routing daemon to manage route announces.
 type Bird struct {
+	config  *Config
+	opts    []xbird.ClientOption
 	clients map[string]*xbird.Client // maps group names to their corresponding BIRD clients
+	mu      sync.RWMutex             // to protect the clients map
 }
 
 // New creates a new Bird instance that manages multiple BIRD clients for
 // different groups. Returns an error if any client fails to initialize.
 func New(config *Config, groups []string) (*Bird, error) {
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid bird config: %w", err)
+	}
+	opts, err := config.clientOptions()
+	if err != nil {
+		return nil, err
+	}
+
+	bird := &Bird{
+		config: config,
+		opts:   opts,
+	}
+	clients, err := bird.newClients(groups)
+	if err != nil {
+		return nil, err
+	}
+	bird.clients = clients
+
+	return bird, nil
+}
+
+// ReloadGroups replaces the announce groups served by the instance. The clients
+// of the new groups are created and the clients of the removed groups are shut
+// down. The clients of the remaining groups are kept intact.
+func (m *Bird) ReloadGroups(groups []*announcer.GroupConfig) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var added []string
+	names := make(map[string]struct{}, len(groups))
+	for _, group := range groups {
+		names[group.Name] = struct{}{}
+		if _, exists := m.clients[group.Name]; !exists {
+			added = append(added, group.Name)
+		}
+	}
+
+	clients, err := m.newClients(added)
+	if err != nil {
+		return err
+	}
+	for group, client := range m.clients {
+		if _, exists := names[group]; !exists {
+			client.Shutdown()
+			continue
+		}
+		clients[group] = client
+	}
+	m.clients = clients
+
+	return nil
+}
+
+// newClients creates the BIRD clients of the groups. If any client fails to
+// initialize, the clients already created are shut down.
+func (m *Bird) newClients(groups []string) (map[string]*xbird.Client, error) {
 	clients := make(map[string]*xbird.Client, len(groups))
 	for _, group := range groups {
-		client, err := xbird.NewClient(config.SockDir, group)
+		client, err := xbird.NewClient(m.config.GetSockDir(group), group, m.opts...)
 		if err != nil {
+			// Release the sockets of the clients already created.
+			for _, client := range clients {
+				client.Shutdown()
+			}
 			return nil, fmt.Errorf("failed to create bird client for group %q: %w", group, err)
 		}
 
 		clients[group] = client
 	}
-
-	return &Bird{
-		clients: clients,
-	}, nil
+	return clients, nil
 }
 
 // RaiseAnnounce enables the announce of a given prefix in the specified group.
@@ -80,6 +141,8 @@ func (m *Bird) ProcessBatch(group string, announces map[netip.Prefix]announcer.P
 // Shutdown gracefully shuts down all BIRD clients managed by this instance. It
 // closes all active Unix domain sockets used for communication.
 func (m *Bird) Shutdown() {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
 	for _, client := range m.clients {
 		client.Shutdown()
 	}
@@ -131,6 +194,8 @@ func (m *Bird) processAnnounce(group string, prefix netip.Prefix, enable bool) e
 // clientByGroup returns the BIRD client for the specified group.
 // Returns an error if the client is not configured.
 func (m *Bird) clientByGroup(group string) (*xbird.Client, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
 	client := m.clients[group]
 	if client == nil {
 		return nil, fmt.Errorf("bird client for group %q is not configured", group)
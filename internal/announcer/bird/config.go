@@ -1,5 +1,15 @@
 package bird
 
+import (
+	"fmt"
+	"io/fs"
+	"os/user"
+	"strconv"
+	"strings"
+
+	xbird "github.com/yanet-platform/monalive/pkg/bird"
+)
+
 // Config represents the configuration of the BIRD client.
 type Config struct {
 	// BatchSize determines the maximum number of messages sent to the BIRD in a
@@ -8,10 +18,145 @@ type Config struct {
 	BatchSize int `yaml:"batch_size"`
 	// SockDir is the directory where the BIRD UNIX domain sockets are located.
 	SockDir string `yaml:"sock_dir"`
+	// GroupSockDir overrides the SockDir per announce group.
+	GroupSockDir map[string]string `yaml:"group_sock_dir"`
+	// ListenSockName is the name template of the socket the BIRD state
+	// requests are received on. "{group}" is replaced with the announce group.
+	ListenSockName string `yaml:"listen_sock_name"`
+	// WriteSockName is the name template of the socket the BIRD listens for
+	// prefix updates on. "{group}" is replaced with the announce group.
+	WriteSockName string `yaml:"write_sock_name"`
+	// SockMode is the octal permissions of the listen socket file. If not set,
+	// write permissions for group and others are added.
+	SockMode string `yaml:"sock_mode"`
+	// SockOwner is the user name or ID the listen socket file is owned by.
+	SockOwner string `yaml:"sock_owner"`
+	// SockGroup is the group name or ID the listen socket file is owned by.
+	SockGroup string `yaml:"sock_group"`
 }
 
 // Default sets the default values for the configuration.
 func (m *Config) Default() {
 	m.BatchSize = 4096
 	m.SockDir = "/var/run"
+	m.ListenSockName = xbird.DefaultListenSockName
+	m.WriteSockName = xbird.DefaultWriteSockName
+}
+
+// Validate checks the configuration.
+func (m *Config) Validate() error {
+	if m.BatchSize < 0 {
+		return fmt.Errorf("negative batch size: %d", m.BatchSize)
+	}
+	if maxBatchSize := xbird.MaxBatchSize(); uint(m.BatchSize) > maxBatchSize {
+		return fmt.Errorf("batch size %d exceeds %d messages a single datagram can hold", m.BatchSize, maxBatchSize)
+	}
+	for _, template := range []string{m.ListenSockName, m.WriteSockName} {
+		if template != "" && !strings.Contains(template, xbird.GroupPlaceholder) {
+			return fmt.Errorf("socket name template %q does not contain %s", template, xbird.GroupPlaceholder)
+		}
+	}
+	if _, err := m.sockMode(); err != nil {
+		return err
+	}
+	if _, _, err := m.sockOwner(); err != nil {
+		return err
+	}
+	return nil
+}
+
+// GetSockDir returns the socket directory of the announce group.
+func (m *Config) GetSockDir(group string) string {
+	if dir, exists := m.GroupSockDir[group]; exists {
+		return dir
+	}
+	return m.SockDir
+}
+
+// clientOptions returns the BIRD client options according to the
+// configuration.
+func (m *Config) clientOptions() ([]xbird.ClientOption, error) {
+	var opts []xbird.ClientOption
+	if m.BatchSize > 0 {
+		opts = append(opts, xbird.WithBatchSize(uint(m.BatchSize)))
+	}
+
+	listenSockName, writeSockName := m.ListenSockName, m.WriteSockName
+	if listenSockName == "" {
+		listenSockName = xbird.DefaultListenSockName
+	}
+	if writeSockName == "" {
+		writeSockName = xbird.DefaultWriteSockName
+	}
+	opts = append(opts, xbird.WithSockNames(listenSockName, writeSockName))
+
+	mode, err := m.sockMode()
+	if err != nil {
+		return nil, err
+	}
+	if mode != nil {
+		opts = append(opts, xbird.WithSockMode(*mode))
+	}
+
+	uid, gid, err := m.sockOwner()
+	if err != nil {
+		return nil, err
+	}
+	opts = append(opts, xbird.WithSockOwner(uid, gid))
+
+	return opts, nil
+}
+
+// sockMode parses the socket file permissions. Returns nil if not set.
+func (m *Config) sockMode() (*fs.FileMode, error) {
+	if m.SockMode == "" {
+		return nil, nil
+	}
+	value, err := strconv.ParseUint(m.SockMode, 8, 32)
+	if err != nil || value > uint64(fs.ModePerm) {
+		return nil, fmt.Errorf("invalid socket mode: %q", m.SockMode)
+	}
+	mode := fs.FileMode(value)
+	return &mode, nil
+}
+
+// sockOwner resolves the socket file owner. Returns -1 for the IDs not set.
+func (m *Config) sockOwner() (uid, gid int, err error) {
+	uid, gid = -1, -1
+	if m.SockOwner != "" {
+		if uid, err = lookupID(m.SockOwner, func(name string) (string, error) {
+			u, err := user.Lookup(name)
+			if err != nil {
+				return "", err
+			}
+			return u.Uid, nil
+		}); err != nil {
+			return -1, -1, fmt.Errorf("invalid socket owner %q: %w", m.SockOwner, err)
+		}
+	}
+	if m.SockGroup != "" {
+		if gid, err = lookupID(m.SockGroup, func(name string) (string, error) {
+			g, err := user.LookupGroup(name)
+			if err != nil {
+				return "", err
+			}
+			return g.Gid, nil
+		}); err != nil {
+			return -1, -1, fmt.Errorf("invalid socket group %q: %w", m.SockGroup, err)
+		}
+	}
+	return uid, gid, nil
+}
+
+// lookupID returns the numeric ID as is, otherwise resolves the name using the
+// lookup function.
+func lookupID(value string, lookup func(string) (string, error)) (int, error) {
+	if id, err := strconv.Atoi(value); err == nil {
+		return id, nil
+	}
+	id, err := lookup(value)
+	if err != nil {
+		return -1, err
+	}
+	return strconv.Atoi(id)
 }
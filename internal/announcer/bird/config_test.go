@@ -0,0 +1,36 @@
+package bird
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestConfig_Validate tests the validation of the BIRD client configuration.
+func TestConfig_Validate(t *testing.T) {
+	config := &Config{}
+	config.Default()
+	assert.NoError(t, config.Validate())
+
+	tests := []func(*Config){
+		func(m *Config) { m.BatchSize = -1 },
+		func(m *Config) { m.BatchSize = 1 << 30 },
+		func(m *Config) { m.ListenSockName = "b2m" },
+		func(m *Config) { m.SockMode = "0999" },
+		func(m *Config) { m.SockOwner = "no-such-user-exists" },
+	}
+	for _, modify := range tests {
+		config := &Config{}
+		config.Default()
+		modify(config)
+		assert.Error(t, config.Validate())
+	}
+}
+
+// TestConfig_GetSockDir tests that the socket directory of the group can be
+// overridden.
+func TestConfig_GetSockDir(t *testing.T) {
+	config := &Config{SockDir: "/run/bird", GroupSockDir: map[string]string{"g-1": "/run/bird-1"}}
+	assert.Equal(t, "/run/bird-1", config.GetSockDir("g-1"))
+	assert.Equal(t, "/run/bird", config.GetSockDir("g-2"))
+}
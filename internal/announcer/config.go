@@ -1,20 +1,304 @@
 package announcer
 
 import (
+	"fmt"
 	"time"
 )
 
+// Backend is the type of the external announcer the prefixes are announced
+// with.
+type Backend string
+
+const (
+	// BackendBird announces the prefixes with the patched BIRD daemon.
+	BackendBird Backend = "bird"
+	// BackendBGP announces the prefixes with the embedded BGP speaker.
+	BackendBGP Backend = "bgp"
+	// BackendExec announces the prefixes by running a command.
+	BackendExec Backend = "exec"
+	// BackendWebhook announces the prefixes by sending HTTP requests.
+	BackendWebhook Backend = "webhook"
+)
+
+// defaultUpdatePeriod is the update period used if it is not configured.
+const defaultUpdatePeriod = 50 * time.Millisecond
+
+// defaultMaxRetryBackoff is the maximum delay between the retries of the failed
+// updates used if it is not configured.
+const defaultMaxRetryBackoff = 10 * time.Second
+
 // Config represents the configuration of the announcer.
 type Config struct {
+	// Backend is the default type of the external announcer of the announce
+	// groups. Defaults to "bird".
+	Backend Backend `yaml:"backend"`
 	// The time interval between sending requests to external announcer with
 	// prefix updates.
 	UpdatePeriod time.Duration `yaml:"update_period"`
-	// List of known announce groups.
-	AnnounceGroup []string `yaml:"announce_group"`
+	// List of known announce groups. Each group is either a name or an object
+	// with per-group settings.
+	AnnounceGroup []*GroupConfig `yaml:"announce_group"`
+	// The time interval between pushes of the full prefixes state to external
+	// announcer to repair possible desynchronization. Zero value disables the
+	// periodic pushes.
+	ResyncPeriod time.Duration `yaml:"resync_period"`
+	// The maximum delay between the retries of the updates failed to be
+	// sent. The delay starts at the update period of the group and doubles on
+	// each consecutive failure.
+	MaxRetryBackoff time.Duration `yaml:"max_retry_backoff"`
+}
+
+// GroupConfig represents the configuration of an announce group. It is the
+// only place the per-group settings are set. The backend, update period and
+// resync period not set for the group are taken from the announcer
+// configuration.
+type GroupConfig struct {
+	// Name of the announce group.
+	Name string `yaml:"name"`
+	// Backend is the type of the external announcer of the group.
+	Backend Backend `yaml:"backend"`
+	// The time interval between sending requests to external announcer with
+	// prefix updates of the group.
+	UpdatePeriod time.Duration `yaml:"update_period"`
+	// The time interval between pushes of the full prefixes state of the
+	// group. Zero value disables the periodic pushes.
+	ResyncPeriod *time.Duration `yaml:"resync_period"`
+	// Damping of the prefix status changes of the group. The prefixes of the
+	// group are not damped if not set.
+	Damping *DampingConfig `yaml:"damping"`
+	// Policy is the default readiness policy of the prefixes of the group:
+	// "all", "any", "at_least" or "critical". It is used unless the services
+	// configuration sets the policy of the prefix or group.
+	Policy PolicyMode `yaml:"policy"`
+	// Minimal number of enabled member services for the "at_least" policy.
+	MinServices int `yaml:"min_services"`
+	// BGP holds the path attributes of the prefixes of the group for the "bgp"
+	// backend.
+	BGP *BGPAttributes `yaml:"bgp"`
+}
+
+// UnmarshalYAML implements the yaml.Unmarshaler interface. The group can be
+// set either by its name only or by an object.
+func (m *GroupConfig) UnmarshalYAML(unmarshal func(any) error) error {
+	var name string
+	if err := unmarshal(&name); err == nil {
+		*m = GroupConfig{Name: name}
+		return nil
+	}
+
+	// Use a type alias to prevent the recursive calls.
+	type plain GroupConfig
+	return unmarshal((*plain)(m))
+}
+
+// policy returns the default readiness policy of the group.
+func (m *GroupConfig) policy() Policy {
+	return Policy{Mode: m.Policy, Min: m.MinServices}
+}
+
+// BGPAttributes represents the BGP path attributes of the prefixes of an
+// announce group.
+type BGPAttributes struct {
+	// Next hop of the IPv4 prefixes.
+	NextHop string `yaml:"next_hop"`
+	// Next hop of the IPv6 prefixes.
+	NextHopV6 string `yaml:"next_hop_v6"`
+	// Local preference of the prefixes, sent to internal peers only.
+	LocalPref *uint32 `yaml:"local_pref"`
+	// Standard communities of the prefixes in the "ASN:value" format.
+	Communities []string `yaml:"communities"`
+}
+
+// Validate checks the configuration.
+func (m *Config) Validate() error {
+	if m.MaxRetryBackoff < 0 {
+		return fmt.Errorf("negative max_retry_backoff: %s", m.MaxRetryBackoff)
+	}
+	names := make(map[string]struct{}, len(m.AnnounceGroup))
+	for _, group := range m.AnnounceGroup {
+		if group == nil {
+			return fmt.Errorf("empty announce group")
+		}
+		if group.Name == "" {
+			return fmt.Errorf("announce group name is not set")
+		}
+		if _, exists := names[group.Name]; exists {
+			return fmt.Errorf("duplicate announce group: %q", group.Name)
+		}
+		names[group.Name] = struct{}{}
+		if err := group.policy().Validate(); err != nil {
+			return fmt.Errorf("announce group %q: %w", group.Name, err)
+		}
+		switch backend := m.GetBackend(group.Name); backend {
+		case BackendBird, BackendBGP, BackendExec, BackendWebhook:
+		default:
+			return fmt.Errorf("announce group %q: unknown backend %q", group.Name, backend)
+		}
+		if group.Damping != nil {
+			if err := group.Damping.Validate(); err != nil {
+				return fmt.Errorf("announce group %q damping: %w", group.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// Groups returns the names of the known announce groups.
+func (m *Config) Groups() []string {
+	groups := make([]string, 0, len(m.AnnounceGroup))
+	for _, group := range m.AnnounceGroup {
+		groups = append(groups, group.Name)
+	}
+	return groups
+}
+
+// ResolvedGroups returns the configurations of the announce groups with their
+// backends resolved according to the announcer settings.
+func (m *Config) ResolvedGroups() []*GroupConfig {
+	groups := make([]*GroupConfig, 0, len(m.AnnounceGroup))
+	for _, group := range m.AnnounceGroup {
+		resolved := *group
+		resolved.Backend = m.GetBackend(group.Name)
+		groups = append(groups, &resolved)
+	}
+	return groups
+}
+
+// Group returns the configuration of the announce group.
+func (m *Config) Group(name string) (group *GroupConfig, exists bool) {
+	for _, group := range m.AnnounceGroup {
+		if group.Name == name {
+			return group, true
+		}
+	}
+	return nil, false
+}
+
+// GetBackend returns the type of the external announcer of the announce group.
+func (m *Config) GetBackend(group string) Backend {
+	if config, exists := m.Group(group); exists && config.Backend != "" {
+		return config.Backend
+	}
+	if m.Backend == "" {
+		return BackendBird
+	}
+	return m.Backend
+}
+
+// GetUpdatePeriod returns the period of sending the prefix updates of the
+// announce group.
+func (m *Config) GetUpdatePeriod(group string) time.Duration {
+	if config, exists := m.Group(group); exists && config.UpdatePeriod > 0 {
+		return config.UpdatePeriod
+	}
+	if m.UpdatePeriod > 0 {
+		return m.UpdatePeriod
+	}
+	return defaultUpdatePeriod
+}
+
+// GetResyncPeriod returns the period of the full prefixes state pushes for the
+// announce group.
+func (m *Config) GetResyncPeriod(group string) time.Duration {
+	if config, exists := m.Group(group); exists && config.ResyncPeriod != nil {
+		return *config.ResyncPeriod
+	}
+	return m.ResyncPeriod
+}
+
+// GetRetryBackoff returns the delay before the next retry of the updates of the
+// announce group failed to be sent the given number of times in a row.
+func (m *Config) GetRetryBackoff(group string, failures int) time.Duration {
+	maxBackoff := m.MaxRetryBackoff
+	if maxBackoff == 0 {
+		maxBackoff = defaultMaxRetryBackoff
+	}
+	backoff := m.GetUpdatePeriod(group)
+	for i := 1; i < failures && backoff < maxBackoff; i++ {
+		backoff *= 2
+	}
+	return min(backoff, maxBackoff)
+}
+
+// GetDamping returns the damping configuration of the announce group. Returns
+// nil if the group is not damped.
+func (m *Config) GetDamping(group string) *DampingConfig {
+	if config, exists := m.Group(group); exists {
+		return config.Damping
+	}
+	return nil
+}
+
+// GetPolicy returns the default readiness policy of the prefixes of the
+// announce group.
+func (m *Config) GetPolicy(group string) Policy {
+	if config, exists := m.Group(group); exists {
+		return config.policy()
+	}
+	return Policy{}
+}
+
+// GetBGPAttributes returns the BGP path attributes of the announce group.
+// Returns nil if not set.
+func (m *Config) GetBGPAttributes(group string) *BGPAttributes {
+	if config, exists := m.Group(group); exists {
+		return config.BGP
+	}
+	return nil
+}
+
+// dampings returns the damping configurations of all damped announce groups.
+func (m *Config) dampings() map[string]*DampingConfig {
+	dampings := make(map[string]*DampingConfig)
+	for _, group := range m.AnnounceGroup {
+		if damping := m.GetDamping(group.Name); damping != nil {
+			dampings[group.Name] = damping
+		}
+	}
+	return dampings
+}
+
+// minUpdatePeriod returns the shortest update period of the announce groups.
+func (m *Config) minUpdatePeriod() time.Duration {
+	period := m.GetUpdatePeriod("")
+	for _, group := range m.AnnounceGroup {
+		period = min(period, m.GetUpdatePeriod(group.Name))
+	}
+	return period
+}
+
+// DampingConfig represents the configuration of the prefix status changes
+// damping for an announce group.
+type DampingConfig struct {
+	// The time the prefix must stay unready before its announce is removed.
+	WithdrawDelay time.Duration `yaml:"withdraw_delay"`
+	// The time the prefix must stay ready before it is announced again.
+	AnnounceDelay time.Duration `yaml:"announce_delay"`
+	// The maximum number of prefix status changes within ChangesWindow. If
+	// exceeded, the prefix is pinned withdrawn until its status stays
+	// unchanged for the whole ChangesWindow. Zero value means unlimited.
+	MaxChanges int `yaml:"max_changes"`
+	// The sliding window the prefix status changes are counted in.
+	ChangesWindow time.Duration `yaml:"changes_window"`
+}
+
+// Validate checks the damping configuration.
+func (m *DampingConfig) Validate() error {
+	if m.WithdrawDelay < 0 || m.AnnounceDelay < 0 {
+		return fmt.Errorf("negative delay")
+	}
+	if m.MaxChanges < 0 {
+		return fmt.Errorf("negative max changes")
+	}
+	if m.MaxChanges > 0 && m.ChangesWindow <= 0 {
+		return fmt.Errorf("max changes requires positive changes window")
+	}
+	return nil
 }
 
 // Default sets the default values for the configuration.
 func (m *Config) Default() {
-	m.UpdatePeriod = 50 * time.Millisecond
-	m.AnnounceGroup = []string{"default"}
+	m.Backend = BackendBird
+	m.UpdatePeriod = defaultUpdatePeriod
+	m.AnnounceGroup = []*GroupConfig{{Name: "default"}}
 }
@@ -0,0 +1,76 @@
+package announcer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v2"
+)
+
+// TestConfig_AnnounceGroup tests that the announce groups can be set either by
+// their names or by objects with per-group settings.
+func TestConfig_AnnounceGroup(t *testing.T) {
+	data := `
+backend: bird
+update_period: 100ms
+resync_period: 1m
+announce_group:
+  - default
+  - name: bgp
+    backend: bgp
+    update_period: 1s
+    resync_period: 0s
+    policy: at_least
+    min_services: 2
+    bgp:
+      next_hop: 192.0.2.1
+`
+	var config Config
+	require.NoError(t, yaml.Unmarshal([]byte(data), &config))
+	require.NoError(t, config.Validate())
+	assert.Equal(t, []string{"default", "bgp"}, config.Groups())
+
+	assert.Equal(t, BackendBird, config.GetBackend("default"))
+	assert.Equal(t, 100*time.Millisecond, config.GetUpdatePeriod("default"))
+	assert.Equal(t, time.Minute, config.GetResyncPeriod("default"))
+	assert.Equal(t, Policy{}, config.GetPolicy("default"))
+	assert.Nil(t, config.GetBGPAttributes("default"))
+
+	assert.Equal(t, BackendBGP, config.GetBackend("bgp"))
+	assert.Equal(t, time.Second, config.GetUpdatePeriod("bgp"))
+	assert.Equal(t, time.Duration(0), config.GetResyncPeriod("bgp"))
+	assert.Equal(t, Policy{Mode: PolicyAtLeast, Min: 2}, config.GetPolicy("bgp"))
+	assert.Equal(t, "192.0.2.1", config.GetBGPAttributes("bgp").NextHop)
+
+	assert.Equal(t, 100*time.Millisecond, config.minUpdatePeriod())
+}
+
+// TestConfig_Validate tests the validation of the announce groups.
+func TestConfig_Validate(t *testing.T) {
+	for _, config := range []*Config{
+		{AnnounceGroup: []*GroupConfig{nil}},
+		{AnnounceGroup: []*GroupConfig{{}}},
+		{AnnounceGroup: []*GroupConfig{{Name: "default"}, {Name: "default"}}},
+		{AnnounceGroup: []*GroupConfig{{Name: "default", Policy: PolicyAtLeast}}},
+		{AnnounceGroup: []*GroupConfig{{Name: "default", Backend: "unknown"}}},
+		{AnnounceGroup: []*GroupConfig{{Name: "default", Damping: &DampingConfig{MaxChanges: 1}}}},
+		{AnnounceGroup: []*GroupConfig{{Name: "default"}}, MaxRetryBackoff: -time.Second},
+	} {
+		assert.Error(t, config.Validate())
+	}
+}
+
+// TestConfig_NullGroup tests that the null entry of the announce groups list is
+// rejected.
+func TestConfig_NullGroup(t *testing.T) {
+	data := `
+announce_group:
+  - default
+  - ~
+`
+	var config Config
+	require.NoError(t, yaml.Unmarshal([]byte(data), &config))
+	assert.Error(t, config.Validate())
+}
@@ -0,0 +1,168 @@
+package announcer
+
+import (
+	"net/netip"
+	"sync"
+	"time"
+)
+
+// damper delays the prefix status changes before they are sent to an external
+// announcer instance, so flapping services do not flap the announces.
+//
+// The desired status of a prefix is sent only after it stays unchanged for
+// the configured delay. A prefix changing its status too often is pinned
+// withdrawn until it calms down.
+type damper struct {
+	config   map[string]*DampingConfig // damping configuration per announce group
+	prefixes map[PrefixKey]*dampedPrefix
+	mu       sync.Mutex // to protect concurent access to the prefixes map
+}
+
+// dampedPrefix is the damping state of the prefix.
+type dampedPrefix struct {
+	desired   PrefixStatus // the latest status of the prefix
+	since     time.Time    // the time the desired status is observed since
+	delivered PrefixStatus // the status sent to the external announcer
+	changes   []time.Time  // the times of the delivered status changes within the window
+	pinned    bool         // whether the prefix is pinned withdrawn
+}
+
+// newDamper creates a new instance of damper.
+func newDamper(config map[string]*DampingConfig) *damper {
+	return &damper{
+		config:   config,
+		prefixes: make(map[PrefixKey]*dampedPrefix),
+	}
+}
+
+// Reload replaces the damping configuration. The prefixes of the groups that
+// are no longer damped are released on the next Process call.
+func (m *damper) Reload(config map[string]*DampingConfig) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.config = config
+}
+
+// Process applies the new prefix events and returns the events that are to be
+// sent now. It must be called periodically even if there are no new events to
+// release the delayed ones.
+//
+// The returned events are not considered as delivered until they are passed to
+// the Delivered method, so the events failed to be sent are returned again.
+func (m *damper) Process(events map[PrefixKey]PrefixStatus, now time.Time) map[PrefixKey]PrefixStatus {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	result := make(map[PrefixKey]PrefixStatus, len(events))
+	for prefixKey, status := range events {
+		if m.config[prefixKey.Group] == nil {
+			// The group is not damped.
+			result[prefixKey] = status
+			continue
+		}
+
+		prefix, exists := m.prefixes[prefixKey]
+		if !exists {
+			// The status opposite to the event one is considered as delivered
+			// to the external announcer.
+			prefix = &dampedPrefix{delivered: !status}
+			m.prefixes[prefixKey] = prefix
+		}
+		prefix.desired = status
+		prefix.since = now
+	}
+
+	for prefixKey, prefix := range m.prefixes {
+		config := m.config[prefixKey.Group]
+		if config == nil {
+			// The group is no longer damped, release the desired status
+			// unless it is superseded by the new event.
+			if _, exists := result[prefixKey]; !exists && prefix.desired != prefix.delivered {
+				result[prefixKey] = prefix.desired
+			}
+			delete(m.prefixes, prefixKey)
+			continue
+		}
+
+		// Forget the changes outside of the window.
+		for len(prefix.changes) > 0 && now.Sub(prefix.changes[0]) >= config.ChangesWindow {
+			prefix.changes = prefix.changes[1:]
+		}
+		if len(prefix.changes) == 0 {
+			// The status has not changed for the whole window.
+			prefix.pinned = false
+		}
+
+		status, ready := prefix.status(config, now)
+		if ready && status != prefix.delivered {
+			if config.MaxChanges > 0 && len(prefix.changes) >= config.MaxChanges {
+				// One more change exceeds the limit, so the prefix is pinned
+				// withdrawn instead.
+				prefix.pinned = true
+				status = Unready
+			}
+			if status != prefix.delivered {
+				result[prefixKey] = status
+			}
+		}
+
+		if !prefix.pinned && len(prefix.changes) == 0 && prefix.delivered == prefix.desired {
+			// The prefix is settled, stop tracking it.
+			delete(m.prefixes, prefixKey)
+		}
+	}
+
+	return result
+}
+
+// Delivered records the prefix statuses of the group successfully sent to the
+// external announcer. Only the delivered status changes count towards the
+// changes limit.
+func (m *damper) Delivered(group string, prefixes map[netip.Prefix]PrefixStatus, now time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for prefix, status := range prefixes {
+		damped, exists := m.prefixes[PrefixKey{Prefix: prefix, Group: group}]
+		if !exists || damped.delivered == status {
+			continue
+		}
+		damped.delivered = status
+		damped.changes = append(damped.changes, now)
+	}
+}
+
+// Status returns the status of the prefix that is passed to the external
+// announcer considering the damping. The current status is returned for the
+// prefixes that are not damped at the moment.
+func (m *damper) Status(prefixKey PrefixKey, current PrefixStatus) PrefixStatus {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if prefix, exists := m.prefixes[prefixKey]; exists {
+		return prefix.delivered
+	}
+	return current
+}
+
+// Pinned reports whether the prefix is pinned withdrawn.
+func (m *damper) Pinned(prefixKey PrefixKey) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	prefix, exists := m.prefixes[prefixKey]
+	return exists && prefix.pinned
+}
+
+// status returns the status the prefix should have at the moment and whether
+// it is allowed to be passed to the external announcer.
+func (m *dampedPrefix) status(config *DampingConfig, now time.Time) (status PrefixStatus, ready bool) {
+	if m.pinned {
+		// The pinned prefix is withdrawn immediately.
+		return Unready, true
+	}
+
+	delay := config.WithdrawDelay
+	if m.desired == Ready {
+		delay = config.AnnounceDelay
+	}
+	return m.desired, now.Sub(m.since) >= delay
+}
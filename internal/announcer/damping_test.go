@@ -0,0 +1,98 @@
+package announcer
+
+import (
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestDamper_Delays tests that the prefix status changes are passed only after
+// the status stays unchanged for the configured delay.
+func TestDamper_Delays(t *testing.T) {
+	prefixKey := PrefixKey{Prefix: netip.MustParsePrefix("127.0.0.1/32"), Group: "default"}
+	damper := newDamper(map[string]*DampingConfig{
+		"default": {WithdrawDelay: 10 * time.Second, AnnounceDelay: 5 * time.Second},
+	})
+	start := time.Now()
+
+	// The announce is delayed.
+	events := damper.Process(map[PrefixKey]PrefixStatus{prefixKey: Ready}, start)
+	assert.Empty(t, events)
+	assert.Equal(t, Unready, damper.Status(prefixKey, Ready))
+	events = damper.Process(nil, start.Add(5*time.Second))
+	assert.Equal(t, map[PrefixKey]PrefixStatus{prefixKey: Ready}, events)
+	damper.Delivered(prefixKey.Group, map[netip.Prefix]PrefixStatus{prefixKey.Prefix: Ready}, start.Add(5*time.Second))
+
+	// The short withdraw is suppressed.
+	events = damper.Process(map[PrefixKey]PrefixStatus{prefixKey: Unready}, start.Add(6*time.Second))
+	assert.Empty(t, events)
+	events = damper.Process(map[PrefixKey]PrefixStatus{prefixKey: Ready}, start.Add(7*time.Second))
+	assert.Empty(t, events)
+	events = damper.Process(nil, start.Add(20*time.Second))
+	assert.Empty(t, events)
+	assert.Equal(t, Ready, damper.Status(prefixKey, Ready))
+
+	// Groups without damping are passed as is.
+	otherKey := PrefixKey{Prefix: prefixKey.Prefix, Group: "other"}
+	events = damper.Process(map[PrefixKey]PrefixStatus{otherKey: Ready}, start)
+	assert.Equal(t, map[PrefixKey]PrefixStatus{otherKey: Ready}, events)
+}
+
+// TestDamper_Pin tests that the flapping prefix is pinned withdrawn until its
+// status stays unchanged for the whole window.
+func TestDamper_Pin(t *testing.T) {
+	prefixKey := PrefixKey{Prefix: netip.MustParsePrefix("127.0.0.1/32"), Group: "default"}
+	damper := newDamper(map[string]*DampingConfig{
+		"default": {MaxChanges: 2, ChangesWindow: time.Minute},
+	})
+	start := time.Now()
+
+	// process passes the events to the damper and delivers the result.
+	process := func(events map[PrefixKey]PrefixStatus, now time.Time) map[PrefixKey]PrefixStatus {
+		result := damper.Process(events, now)
+		for prefixKey, status := range result {
+			damper.Delivered(prefixKey.Group, map[netip.Prefix]PrefixStatus{prefixKey.Prefix: status}, now)
+		}
+		return result
+	}
+
+	events := process(map[PrefixKey]PrefixStatus{prefixKey: Ready}, start)
+	assert.Equal(t, map[PrefixKey]PrefixStatus{prefixKey: Ready}, events)
+	events = process(map[PrefixKey]PrefixStatus{prefixKey: Unready}, start.Add(time.Second))
+	assert.Equal(t, map[PrefixKey]PrefixStatus{prefixKey: Unready}, events)
+
+	// The third change exceeds the limit, so the prefix stays withdrawn.
+	events = process(map[PrefixKey]PrefixStatus{prefixKey: Ready}, start.Add(2*time.Second))
+	assert.Empty(t, events)
+	assert.True(t, damper.Pinned(prefixKey))
+	assert.Equal(t, Unready, damper.Status(prefixKey, Ready))
+
+	// The prefix is released once the window is clear.
+	events = process(nil, start.Add(time.Second+time.Minute))
+	assert.Equal(t, map[PrefixKey]PrefixStatus{prefixKey: Ready}, events)
+	assert.False(t, damper.Pinned(prefixKey))
+}
+
+// TestDamper_FailedSend tests that the changes failed to be sent do not count
+// towards the changes limit and are returned again.
+func TestDamper_FailedSend(t *testing.T) {
+	prefixKey := PrefixKey{Prefix: netip.MustParsePrefix("127.0.0.1/32"), Group: "default"}
+	damper := newDamper(map[string]*DampingConfig{
+		"default": {MaxChanges: 1, ChangesWindow: time.Minute},
+	})
+	start := time.Now()
+
+	// The change is not delivered.
+	events := damper.Process(map[PrefixKey]PrefixStatus{prefixKey: Ready}, start)
+	assert.Equal(t, map[PrefixKey]PrefixStatus{prefixKey: Ready}, events)
+	events = damper.Process(nil, start.Add(time.Second))
+	assert.Equal(t, map[PrefixKey]PrefixStatus{prefixKey: Ready}, events)
+	assert.False(t, damper.Pinned(prefixKey))
+
+	damper.Delivered(prefixKey.Group, map[netip.Prefix]PrefixStatus{prefixKey.Prefix: Ready}, start.Add(time.Second))
+	events = damper.Process(nil, start.Add(2*time.Second))
+	assert.Empty(t, events)
+	assert.Equal(t, Ready, damper.Status(prefixKey, Ready))
+}
@@ -0,0 +1,65 @@
+package hook
+
+import (
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// Config represents the configuration of the hook announcer client.
+type Config struct {
+	// Command is the command template run for the exec backend. The
+	// placeholders "{group}", "{action}" and "{prefix}" are replaced in the
+	// arguments. If the arguments contain "{action}" or "{prefix}", the
+	// command is run for each prefix separately, otherwise once per batch.
+	Command []string `yaml:"command"`
+	// URL is the endpoint the batches are posted to for the webhook backend.
+	URL string `yaml:"url"`
+	// Timeout is the maximum duration of a single command run or request.
+	// The failed runs are retried by the announcer with backoff.
+	Timeout time.Duration `yaml:"timeout"`
+	// FullState enables sending the full state of the announce group instead
+	// of the changed prefixes only.
+	FullState bool `yaml:"full_state"`
+}
+
+// Default sets the default values for the configuration.
+func (m *Config) Default() {
+	m.Timeout = 5 * time.Second
+	m.FullState = false
+}
+
+// UnmarshalYAML implements the yaml.Unmarshaler interface. The settings omitted
+// in the configuration get their default values.
+func (m *Config) UnmarshalYAML(unmarshal func(any) error) error {
+	m.Default()
+
+	// Use a type alias to prevent the recursive calls.
+	type plain Config
+	return unmarshal((*plain)(m))
+}
+
+// validateExec checks the configuration of the exec backend.
+func (m *Config) validateExec() error {
+	if len(m.Command) == 0 {
+		return fmt.Errorf("command is not set")
+	}
+	return m.validate()
+}
+
+// validateWebhook checks the configuration of the webhook backend.
+func (m *Config) validateWebhook() error {
+	endpoint, err := url.Parse(m.URL)
+	if err != nil || (endpoint.Scheme != "http" && endpoint.Scheme != "https") {
+		return fmt.Errorf("invalid url: %q", m.URL)
+	}
+	return m.validate()
+}
+
+// validate checks the common settings.
+func (m *Config) validate() error {
+	if m.Timeout < 0 {
+		return fmt.Errorf("negative timeout: %s", m.Timeout)
+	}
+	return nil
+}
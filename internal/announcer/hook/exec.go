@@ -0,0 +1,112 @@
+package hook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// Placeholders replaced in the command arguments.
+const (
+	placeholderGroup  = "{group}"
+	placeholderAction = "{action}"
+	placeholderPrefix = "{prefix}"
+)
+
+// commandRunner runs the command for the batches.
+//
+// If the command arguments contain "{action}" or "{prefix}", the command is
+// run for each update with MONALIVE_GROUP, MONALIVE_ACTION and MONALIVE_PREFIX
+// environment variables. Otherwise it is run once per batch with
+// MONALIVE_GROUP, MONALIVE_ANNOUNCE and MONALIVE_WITHDRAW environment
+// variables holding space separated prefixes, and the JSON batch on stdin.
+type commandRunner struct {
+	command []string
+}
+
+// Run runs the command for the batch.
+func (m *commandRunner) Run(ctx context.Context, batch Batch) error {
+	if !m.perPrefix() {
+		return m.runBatch(ctx, batch)
+	}
+	for _, update := range batch.Updates {
+		if err := m.runUpdate(ctx, batch.Group, update); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// perPrefix reports whether the command is run for each prefix.
+func (m *commandRunner) perPrefix() bool {
+	for _, arg := range m.command {
+		if strings.Contains(arg, placeholderAction) || strings.Contains(arg, placeholderPrefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// runBatch runs the command once for the whole batch.
+func (m *commandRunner) runBatch(ctx context.Context, batch Batch) error {
+	var announce, withdraw []string
+	for _, update := range batch.Updates {
+		switch update.Action {
+		case ActionAnnounce:
+			announce = append(announce, update.Prefix.String())
+		case ActionWithdraw:
+			withdraw = append(withdraw, update.Prefix.String())
+		}
+	}
+
+	stdin, err := json.Marshal(batch)
+	if err != nil {
+		return fmt.Errorf("failed to marshal batch: %w", err)
+	}
+
+	replacer := strings.NewReplacer(placeholderGroup, batch.Group)
+	env := []string{
+		"MONALIVE_GROUP=" + batch.Group,
+		"MONALIVE_ANNOUNCE=" + strings.Join(announce, " "),
+		"MONALIVE_WITHDRAW=" + strings.Join(withdraw, " "),
+	}
+	return m.run(ctx, replacer, env, stdin)
+}
+
+// runUpdate runs the command for a single update.
+func (m *commandRunner) runUpdate(ctx context.Context, group string, update Update) error {
+	replacer := strings.NewReplacer(
+		placeholderGroup, group,
+		placeholderAction, string(update.Action),
+		placeholderPrefix, update.Prefix.String(),
+	)
+	env := []string{
+		"MONALIVE_GROUP=" + group,
+		"MONALIVE_ACTION=" + string(update.Action),
+		"MONALIVE_PREFIX=" + update.Prefix.String(),
+	}
+	return m.run(ctx, replacer, env, nil)
+}
+
+// run runs the command with the placeholders replaced.
+func (m *commandRunner) run(ctx context.Context, replacer *strings.Replacer, env []string, stdin []byte) error {
+	args := make([]string, 0, len(m.command))
+	for _, arg := range m.command {
+		args = append(args, replacer.Replace(arg))
+	}
+
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	cmd.Env = append(os.Environ(), env...)
+	cmd.Stdin = bytes.NewReader(stdin)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("command %q failed: %w: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
@@ -0,0 +1,172 @@
+// Package hook provides implementations of the [announcer.Client] interface
+// delegating the prefix announces to an external command or HTTP endpoint. It
+// allows to integrate with ExaBGP, FRR or any other route controller.
+package hook
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"net/netip"
+	"slices"
+	"sync"
+
+	"github.com/yanet-platform/monalive/internal/announcer"
+	"github.com/yanet-platform/monalive/internal/utils/shutdown"
+)
+
+// Action is the action applied to the prefix.
+type Action string
+
+const (
+	// ActionAnnounce announces the prefix.
+	ActionAnnounce Action = "announce"
+	// ActionWithdraw withdraws the prefix.
+	ActionWithdraw Action = "withdraw"
+)
+
+// Update is the action applied to the prefix.
+type Update struct {
+	Prefix netip.Prefix `json:"prefix"`
+	Action Action       `json:"action"`
+}
+
+// Batch is the set of prefix updates of the announce group passed to the
+// runner.
+type Batch struct {
+	Group string `json:"group"`
+	// FullState reports whether the batch contains all prefixes of the group.
+	FullState bool     `json:"full_state"`
+	Updates   []Update `json:"updates"`
+}
+
+// runner applies the batch of prefix updates.
+type runner interface {
+	Run(ctx context.Context, batch Batch) error
+}
+
+// Hook is an implementation of the announcer.Client interface that passes the
+// prefix updates to the runner with timeout.
+type Hook struct {
+	config *Config
+	runner runner
+
+	state map[string]map[netip.Prefix]announcer.PrefixStatus // last known prefix statuses per group
+	mu    sync.Mutex                                         // protects state and serializes the runs
+
+	shutdown *shutdown.Shutdown
+}
+
+// NewExec creates a new Hook running the configured command.
+func NewExec(config *Config) (*Hook, error) {
+	if config == nil {
+		return nil, fmt.Errorf("exec is not configured")
+	}
+	if err := config.validateExec(); err != nil {
+		return nil, fmt.Errorf("invalid exec config: %w", err)
+	}
+	return newHook(config, &commandRunner{command: config.Command}), nil
+}
+
+// NewWebhook creates a new Hook posting the batches to the configured URL.
+func NewWebhook(config *Config) (*Hook, error) {
+	if config == nil {
+		return nil, fmt.Errorf("webhook is not configured")
+	}
+	if err := config.validateWebhook(); err != nil {
+		return nil, fmt.Errorf("invalid webhook config: %w", err)
+	}
+	return newHook(config, newWebhookRunner(config.URL)), nil
+}
+
+// newHook creates a new Hook with the passed runner.
+func newHook(config *Config, runner runner) *Hook {
+	return &Hook{
+		config:   config,
+		runner:   runner,
+		state:    make(map[string]map[netip.Prefix]announcer.PrefixStatus),
+		shutdown: shutdown.New(),
+	}
+}
+
+// RaiseAnnounce announces the prefix of the specified group.
+func (m *Hook) RaiseAnnounce(group string, prefix netip.Prefix) error {
+	return m.ProcessBatch(group, map[netip.Prefix]announcer.PrefixStatus{prefix: announcer.Ready})
+}
+
+// RemoveAnnounce withdraws the prefix of the specified group.
+func (m *Hook) RemoveAnnounce(group string, prefix netip.Prefix) error {
+	return m.ProcessBatch(group, map[netip.Prefix]announcer.PrefixStatus{prefix: announcer.Unready})
+}
+
+// ProcessBatch passes the prefix updates of the group to the runner. In the
+// full state mode, all known prefixes of the group are passed.
+func (m *Hook) ProcessBatch(group string, prefixes map[netip.Prefix]announcer.PrefixStatus) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	state := m.state[group]
+	if state == nil {
+		state = make(map[netip.Prefix]announcer.PrefixStatus)
+		m.state[group] = state
+	}
+	maps.Copy(state, prefixes)
+
+	batch := Batch{Group: group, FullState: m.config.FullState}
+	if batch.FullState {
+		prefixes = state
+	}
+	for _, prefix := range slices.SortedFunc(maps.Keys(prefixes), comparePrefixes) {
+		action := ActionWithdraw
+		if prefixes[prefix] == announcer.Ready {
+			action = ActionAnnounce
+		}
+		batch.Updates = append(batch.Updates, Update{Prefix: prefix, Action: action})
+	}
+
+	err := m.run(batch)
+
+	// Forget the withdrawn prefixes, so the full state does not grow.
+	for prefix, status := range state {
+		if status == announcer.Unready {
+			delete(state, prefix)
+		}
+	}
+	return err
+}
+
+// Shutdown interrupts the running batch.
+func (m *Hook) Shutdown() {
+	m.shutdown.Do()
+}
+
+// run runs the batch within the timeout. The failed batch is not retried, as
+// the announcer re-queues the failed updates and retries them with backoff
+// itself.
+func (m *Hook) run(batch Batch) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if m.config.Timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, m.config.Timeout)
+		defer cancel()
+	}
+
+	// Interrupt the run on shutdown.
+	go func() {
+		select {
+		case <-m.shutdown.Done():
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	return m.runner.Run(ctx, batch)
+}
+
+// comparePrefixes compares prefixes by address and length.
+func comparePrefixes(a, b netip.Prefix) int {
+	if c := a.Addr().Compare(b.Addr()); c != 0 {
+		return c
+	}
+	return a.Bits() - b.Bits()
+}
@@ -0,0 +1,132 @@
+package hook
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v2"
+
+	"github.com/yanet-platform/monalive/internal/announcer"
+)
+
+var (
+	prefix1 = netip.MustParsePrefix("192.0.2.0/24")
+	prefix2 = netip.MustParsePrefix("2001:db8::/32")
+)
+
+// TestExec_PerPrefix tests that the command is run for each prefix with the
+// placeholders replaced.
+func TestExec_PerPrefix(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "out")
+	hook, err := NewExec(&Config{
+		Command: []string{"sh", "-c", `echo "$0 $1 $2" >> ` + out, "{group}", "{action}", "{prefix}"},
+	})
+	require.NoError(t, err)
+
+	err = hook.ProcessBatch("g-1", map[netip.Prefix]announcer.PrefixStatus{
+		prefix1: announcer.Ready,
+		prefix2: announcer.Unready,
+	})
+	require.NoError(t, err)
+
+	data, err := os.ReadFile(out)
+	require.NoError(t, err)
+	assert.Equal(t, "g-1 announce 192.0.2.0/24\ng-1 withdraw 2001:db8::/32\n", string(data))
+}
+
+// TestExec_Batch tests that the command is run once per batch with the
+// prefixes passed in the environment.
+func TestExec_Batch(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "out")
+	hook, err := NewExec(&Config{
+		Command: []string{"sh", "-c", `echo "$MONALIVE_GROUP|$MONALIVE_ANNOUNCE|$MONALIVE_WITHDRAW" > ` + out},
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, hook.RaiseAnnounce("g-1", prefix1))
+	data, err := os.ReadFile(out)
+	require.NoError(t, err)
+	assert.Equal(t, "g-1|192.0.2.0/24|\n", string(data))
+}
+
+// TestExec_Failure tests that the failed command is run once and the error
+// is returned, so the announcer re-queues the updates.
+func TestExec_Failure(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "out")
+	hook, err := NewExec(&Config{
+		Command: []string{"sh", "-c", `echo >> ` + out + `; exit 1`},
+	})
+	require.NoError(t, err)
+
+	assert.Error(t, hook.RaiseAnnounce("g-1", prefix1))
+	data, err := os.ReadFile(out)
+	require.NoError(t, err)
+	assert.Equal(t, "\n", string(data))
+}
+
+// TestConfig_Default tests that the settings omitted in the configuration get
+// their default values.
+func TestConfig_Default(t *testing.T) {
+	var config Config
+	require.NoError(t, yaml.Unmarshal([]byte(`command: ["true"]`), &config))
+	assert.Equal(t, []string{"true"}, config.Command)
+	assert.Equal(t, 5*time.Second, config.Timeout)
+	assert.False(t, config.FullState)
+
+	require.NoError(t, yaml.Unmarshal([]byte(`timeout: 1s`), &config))
+	assert.Equal(t, time.Second, config.Timeout)
+}
+
+// TestWebhook_FullState tests that the full state of the group is posted in
+// the full state mode.
+func TestWebhook_FullState(t *testing.T) {
+	var batches []Batch
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var batch Batch
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&batch))
+		batches = append(batches, batch)
+	}))
+	defer server.Close()
+
+	hook, err := NewWebhook(&Config{URL: server.URL, FullState: true})
+	require.NoError(t, err)
+
+	require.NoError(t, hook.RaiseAnnounce("g-1", prefix1))
+	require.NoError(t, hook.RaiseAnnounce("g-1", prefix2))
+	require.NoError(t, hook.RemoveAnnounce("g-1", prefix1))
+
+	require.Len(t, batches, 3)
+	assert.Equal(t, Batch{
+		Group:     "g-1",
+		FullState: true,
+		Updates: []Update{
+			{Prefix: prefix1, Action: ActionAnnounce},
+			{Prefix: prefix2, Action: ActionAnnounce},
+		},
+	}, batches[1])
+	assert.Equal(t, []Update{
+		{Prefix: prefix1, Action: ActionWithdraw},
+		{Prefix: prefix2, Action: ActionAnnounce},
+	}, batches[2].Updates)
+}
+
+// TestWebhook_Status tests that the non-2xx response is considered as a
+// failure.
+func TestWebhook_Status(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	hook, err := NewWebhook(&Config{URL: server.URL})
+	require.NoError(t, err)
+	assert.Error(t, hook.RaiseAnnounce("g-1", prefix1))
+}
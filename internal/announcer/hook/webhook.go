@@ -0,0 +1,52 @@
+package hook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// webhookRunner posts the batches in JSON to the HTTP endpoint.
+type webhookRunner struct {
+	url    string
+	client *http.Client
+}
+
+// newWebhookRunner creates a new webhookRunner posting to the url.
+func newWebhookRunner(url string) *webhookRunner {
+	return &webhookRunner{
+		url:    url,
+		client: &http.Client{},
+	}
+}
+
+// Run posts the batch to the endpoint. Any non-2xx response is considered as a
+// failure.
+func (m *webhookRunner) Run(ctx context.Context, batch Batch) error {
+	body, err := json.Marshal(batch)
+	if err != nil {
+		return fmt.Errorf("failed to marshal batch: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, m.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := m.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to post batch: %w", err)
+	}
+	defer resp.Body.Close()
+	// Drain the body to reuse the connection.
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected response status: %s", resp.Status)
+	}
+	return nil
+}
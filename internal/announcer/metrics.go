@@ -0,0 +1,57 @@
+package announcer
+
+import (
+	"github.com/yanet-platform/monalive/internal/monitoring/metrics"
+)
+
+// Metrics holds the metrics of the announcer.
+type Metrics struct {
+	announced    metrics.GaugeVec
+	sendFailures metrics.CounterVec
+
+	pendingUpdates metrics.GaugeVec
+	failedUpdates  metrics.CounterVec
+}
+
+// NewMetrics creates announcer metrics using the passed provider.
+func NewMetrics(provider metrics.Provider) *Metrics {
+	return &Metrics{
+		announced: provider.GetGaugeVec(
+			"announced_prefixes",
+			[]string{"group"},
+			metrics.WithDescription("number of prefixes announced to the external announcer"),
+		),
+		sendFailures: provider.GetCounterVec(
+			"announce_send_failures",
+			[]string{"group"},
+			metrics.WithDescription("number of failed sends of prefix updates to the external announcer"),
+		),
+
+		pendingUpdates: provider.GetGaugeVec(
+			"announce_pending_updates",
+			[]string{"group"},
+			metrics.WithDescription("number of prefix updates waiting to be sent again to the external announcer"),
+		),
+		failedUpdates: provider.GetCounterVec(
+			"announce_failed_updates",
+			[]string{"group"},
+			metrics.WithDescription("number of prefix updates failed to be sent to the external announcer"),
+		),
+	}
+}
+
+func (m *Metrics) Announced(group string) metrics.Gauge {
+	return m.announced.GetMetricWith(metrics.Labels{"group": group})
+}
+
+func (m *Metrics) SendFailures(group string) metrics.Counter {
+	return m.sendFailures.GetMetricWith(metrics.Labels{"group": group})
+}
+
+func (m *Metrics) PendingUpdates(group string) metrics.Gauge {
+	return m.pendingUpdates.GetMetricWith(metrics.Labels{"group": group})
+}
+
+func (m *Metrics) FailedUpdates(group string) metrics.Counter {
+	return m.failedUpdates.GetMetricWith(metrics.Labels{"group": group})
+}
@@ -0,0 +1,194 @@
+package announcer
+
+import (
+	"fmt"
+	"net/netip"
+	"sync"
+
+	"github.com/yanet-platform/monalive/internal/utils/shutdown"
+)
+
+// ClientFactory creates the client of the backend serving the announce groups.
+type ClientFactory func(backend Backend, groups []*GroupConfig) (Client, error)
+
+// MultiClient is an implementation of the [Client] interface dispatching the
+// announces of each announce group to the client of the group backend. It
+// allows to use different external announcers for different groups.
+//
+// The clients of the backends are created on demand by the factory, so the
+// groups may be reloaded at runtime.
+type MultiClient struct {
+	factory  ClientFactory
+	backends map[Backend]Client // maps backends to their clients
+	clients  map[string]Client  // maps group names to their clients
+	changed  chan struct{}      // closed once the groups are reloaded
+	mu       sync.RWMutex       // to protect the clients maps
+
+	shutdown *shutdown.Shutdown
+}
+
+// NewMultiClient creates a new MultiClient creating the clients of the backends
+// with the factory. It serves no groups until ReloadGroups is called.
+func NewMultiClient(factory ClientFactory) *MultiClient {
+	return &MultiClient{
+		factory:  factory,
+		backends: make(map[Backend]Client),
+		clients:  make(map[string]Client),
+		changed:  make(chan struct{}),
+		shutdown: shutdown.New(),
+	}
+}
+
+// ReloadGroups replaces the announce groups served by the client. The clients
+// of the new backends are created, the clients of the existing ones reload
+// their groups if they implement the [GroupReloader] interface, and the clients
+// of the backends no longer used are shut down.
+func (m *MultiClient) ReloadGroups(groups []*GroupConfig) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	// Split the announce groups by their backends keeping the groups order.
+	var backends []Backend
+	groupsByBackend := make(map[Backend][]*GroupConfig)
+	for _, group := range groups {
+		if _, exists := groupsByBackend[group.Backend]; !exists {
+			backends = append(backends, group.Backend)
+		}
+		groupsByBackend[group.Backend] = append(groupsByBackend[group.Backend], group)
+	}
+
+	// Create the clients of the new backends first, so they can be released
+	// if any of them fails.
+	created := make(map[Backend]Client)
+	for _, backend := range backends {
+		if _, exists := m.backends[backend]; exists {
+			continue
+		}
+		client, err := m.factory(backend, groupsByBackend[backend])
+		if err != nil {
+			for _, client := range created {
+				client.Shutdown()
+			}
+			return err
+		}
+		created[backend] = client
+	}
+
+	// Reload the groups of the existing backends.
+	for _, backend := range backends {
+		client, exists := m.backends[backend]
+		if !exists {
+			continue
+		}
+		if reloader, implements := client.(GroupReloader); implements {
+			if err := reloader.ReloadGroups(groupsByBackend[backend]); err != nil {
+				for _, client := range created {
+					client.Shutdown()
+				}
+				return fmt.Errorf("failed to reload %q backend: %w", backend, err)
+			}
+		}
+	}
+
+	backendClients := make(map[Backend]Client, len(backends))
+	clients := make(map[string]Client, len(groups))
+	for _, backend := range backends {
+		client, exists := m.backends[backend]
+		if !exists {
+			client = created[backend]
+		}
+		backendClients[backend] = client
+		for _, group := range groupsByBackend[backend] {
+			clients[group.Name] = client
+		}
+	}
+
+	// Shut down the clients of the backends no longer used.
+	for backend, client := range m.backends {
+		if _, exists := backendClients[backend]; !exists {
+			client.Shutdown()
+		}
+	}
+
+	m.backends = backendClients
+	m.clients = clients
+
+	// Wake up the state request listeners waiting on the previous groups.
+	close(m.changed)
+	m.changed = make(chan struct{})
+
+	return nil
+}
+
+// RaiseAnnounce enables the announce of a given prefix in the specified group.
+func (m *MultiClient) RaiseAnnounce(group string, prefix netip.Prefix) error {
+	client, err := m.clientByGroup(group)
+	if err != nil {
+		return err
+	}
+	return client.RaiseAnnounce(group, prefix)
+}
+
+// RemoveAnnounce disables the announce of a given prefix in the specified
+// group.
+func (m *MultiClient) RemoveAnnounce(group string, prefix netip.Prefix) error {
+	client, err := m.clientByGroup(group)
+	if err != nil {
+		return err
+	}
+	return client.RemoveAnnounce(group, prefix)
+}
+
+// ProcessBatch processes a batch of prefix announces for a given group with the
+// client of the group.
+func (m *MultiClient) ProcessBatch(group string, prefixes map[netip.Prefix]PrefixStatus) error {
+	client, err := m.clientByGroup(group)
+	if err != nil {
+		return err
+	}
+	return client.ProcessBatch(group, prefixes)
+}
+
+// ListenStateRequest listens for state requests with the client of the group.
+// If the client does not implement the [Stater] interface, it blocks until the
+// shutdown or the groups reload. Both cases are reported with [ErrShutdown], so
+// the caller re-checks whether the group is still served.
+func (m *MultiClient) ListenStateRequest(group string) error {
+	m.mu.RLock()
+	client, changed := m.clients[group], m.changed
+	m.mu.RUnlock()
+	if client == nil {
+		return fmt.Errorf("%w: %q", ErrUnknownGroup, group)
+	}
+
+	if stater, implements := client.(Stater); implements {
+		return stater.ListenStateRequest(group)
+	}
+	select {
+	case <-m.shutdown.Done():
+	case <-changed:
+	}
+	return ErrShutdown
+}
+
+// Shutdown shuts down all clients.
+func (m *MultiClient) Shutdown() {
+	m.shutdown.Do()
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for _, client := range m.backends {
+		client.Shutdown()
+	}
+}
+
+// clientByGroup returns the client of the specified group.
+func (m *MultiClient) clientByGroup(group string) (Client, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	client := m.clients[group]
+	if client == nil {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownGroup, group)
+	}
+	return client, nil
+}
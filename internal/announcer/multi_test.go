@@ -0,0 +1,91 @@
+package announcer
+
+import (
+	"errors"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestMultiClient tests that the batches are dispatched to the clients of the
+// group backends and the state requests of the clients not implementing
+// [Stater] block until the shutdown.
+func TestMultiClient(t *testing.T) {
+	clients := map[Backend]*fakeClient{BackendBird: {}, BackendExec: {}}
+	multi := NewMultiClient(func(backend Backend, _ []*GroupConfig) (Client, error) {
+		return clients[backend], nil
+	})
+	require.NoError(t, multi.ReloadGroups([]*GroupConfig{
+		{Name: "g-1", Backend: BackendBird},
+		{Name: "g-2", Backend: BackendExec},
+	}))
+
+	batch := map[netip.Prefix]PrefixStatus{netip.MustParsePrefix("127.0.0.1/32"): Ready}
+	require.NoError(t, multi.ProcessBatch("g-2", batch))
+	assert.Empty(t, clients[BackendBird].batches)
+	assert.Equal(t, []map[netip.Prefix]PrefixStatus{batch}, clients[BackendExec].batches)
+
+	assert.ErrorIs(t, multi.ProcessBatch("g-3", batch), ErrUnknownGroup)
+
+	done := make(chan error)
+	go func() { done <- multi.ListenStateRequest("g-1") }()
+	multi.Shutdown()
+	select {
+	case err := <-done:
+		assert.True(t, errors.Is(err, ErrShutdown))
+	case <-time.After(time.Second):
+		t.Fatal("state request listener is not stopped")
+	}
+}
+
+// TestMultiClient_ReloadGroups tests that the clients of the backends are
+// created on demand and shut down once their backends are no longer used.
+func TestMultiClient_ReloadGroups(t *testing.T) {
+	var created []Backend
+	clients := make(map[Backend]*fakeClient)
+	multi := NewMultiClient(func(backend Backend, _ []*GroupConfig) (Client, error) {
+		if backend == BackendWebhook {
+			return nil, errors.New("failed to create client")
+		}
+		created = append(created, backend)
+		clients[backend] = &fakeClient{}
+		return clients[backend], nil
+	})
+	require.NoError(t, multi.ReloadGroups([]*GroupConfig{{Name: "g-1", Backend: BackendBird}}))
+
+	// The waiting state request listener is woken up by the reload.
+	done := make(chan error)
+	go func() { done <- multi.ListenStateRequest("g-1") }()
+	// Let the listener start waiting.
+	time.Sleep(50 * time.Millisecond)
+
+	require.NoError(t, multi.ReloadGroups([]*GroupConfig{
+		{Name: "g-1", Backend: BackendBird},
+		{Name: "g-2", Backend: BackendExec},
+	}))
+	assert.Equal(t, []Backend{BackendBird, BackendExec}, created)
+	select {
+	case err := <-done:
+		assert.True(t, errors.Is(err, ErrShutdown))
+	case <-time.After(time.Second):
+		t.Fatal("state request listener is not woken up")
+	}
+
+	// The failed reload keeps the groups served and shuts down the clients
+	// created by it.
+	require.Error(t, multi.ReloadGroups([]*GroupConfig{
+		{Name: "g-3", Backend: BackendBGP},
+		{Name: "g-4", Backend: BackendWebhook},
+	}))
+	assert.True(t, clients[BackendBGP].shutdown)
+	assert.False(t, clients[BackendExec].shutdown)
+	batch := map[netip.Prefix]PrefixStatus{netip.MustParsePrefix("127.0.0.1/32"): Ready}
+	assert.NoError(t, multi.ProcessBatch("g-2", batch))
+
+	require.NoError(t, multi.ReloadGroups([]*GroupConfig{{Name: "g-2", Backend: BackendExec}}))
+	assert.ErrorIs(t, multi.ProcessBatch("g-1", batch), ErrUnknownGroup)
+	assert.NoError(t, multi.ProcessBatch("g-2", batch))
+}
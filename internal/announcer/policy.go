@@ -0,0 +1,90 @@
+package announcer
+
+import (
+	"fmt"
+	"net/netip"
+)
+
+// PolicyMode defines how the states of the member services are combined into
+// the state of the announced prefix.
+type PolicyMode string
+
+const (
+	// PolicyAll requires all member services to be enabled. This is the
+	// default mode.
+	PolicyAll PolicyMode = "all"
+	// PolicyAny requires at least one member service to be enabled.
+	PolicyAny PolicyMode = "any"
+	// PolicyAtLeast requires at least [Policy.Min] member services to be
+	// enabled.
+	PolicyAtLeast PolicyMode = "at_least"
+	// PolicyCritical requires all member services marked as critical to be
+	// enabled. Other services do not affect the prefix. If there are no
+	// critical services, it falls back to [PolicyAll].
+	PolicyCritical PolicyMode = "critical"
+)
+
+// Policy represents the rule the prefix state is calculated by.
+type Policy struct {
+	Mode PolicyMode
+	// Min is the minimal number of enabled member services used by the
+	// [PolicyAtLeast] mode.
+	Min int
+}
+
+// Validate checks that the policy is well-formed.
+func (m Policy) Validate() error {
+	switch m.Mode {
+	case "", PolicyAll, PolicyAny, PolicyCritical:
+		return nil
+	case PolicyAtLeast:
+		if m.Min <= 0 {
+			return fmt.Errorf("%s policy requires positive minimal number of services", m.Mode)
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown announce policy: %s", m.Mode)
+	}
+}
+
+// Ready reports whether the prefix with the given number of enabled and total
+// member services is ready to be announced. The [PolicyCritical] mode is
+// expected to be called with the numbers of the critical services only.
+func (m Policy) Ready(active, total int) bool {
+	if active == 0 {
+		return false
+	}
+	switch m.Mode {
+	case PolicyAny:
+		return true
+	case PolicyAtLeast:
+		return active >= m.Min
+	default:
+		return active == total
+	}
+}
+
+// ServiceAnnounce describes how the service affects the prefix announces.
+type ServiceAnnounce struct {
+	// Group is the announce group of the prefix.
+	Group string
+	// Prefix is the aggregate prefix the service is member of. If not valid,
+	// the host prefix of the service is used.
+	Prefix netip.Prefix
+	// Policy is the rule the prefix state is calculated by.
+	Policy Policy
+	// Critical marks the service as critical for the [PolicyCritical] mode.
+	Critical bool
+}
+
+// String returns the textual representation of the policy.
+func (m Policy) String() string {
+	switch m.Mode {
+	case "":
+		return string(PolicyAll)
+	case PolicyAtLeast:
+		return fmt.Sprintf("%s %d", m.Mode, m.Min)
+	default:
+		return string(m.Mode)
+	}
+}
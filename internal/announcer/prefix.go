@@ -1,9 +1,13 @@
 package announcer
 
 import (
+	"cmp"
 	"fmt"
+	"maps"
 	"net/netip"
+	"slices"
 	"sync"
+	"time"
 
 	"github.com/yanet-platform/monalive/internal/types/key"
 	event "github.com/yanet-platform/monalive/internal/utils/event_registry"
@@ -70,6 +74,7 @@ func (status ServiceStatus) Merge(newStatus ServiceStatus) (result ServiceStatus
 // Prefixes manages the state of prefixes and stores events related to them.
 type Prefixes struct {
 	prefixes map[netip.Prefix]*prefixState            // stores the state of each prefix
+	services map[key.Service]netip.Prefix             // stores the prefix each service is member of
 	events   *event.Registry[PrefixKey, PrefixStatus] // stores prefix status updates using the prefix as a key
 	mu       sync.RWMutex                             // protects access to the prefixes map to ensure safe concurrent access
 }
@@ -78,26 +83,41 @@ type Prefixes struct {
 func NewPrefixes() *Prefixes {
 	return &Prefixes{
 		prefixes: make(map[netip.Prefix]*prefixState),
+		services: make(map[key.Service]netip.Prefix),
 		events:   event.NewRegistry[PrefixKey, PrefixStatus](),
 	}
 }
 
 // ReloadServices updates the services associated with each prefix. It applies
 // the new services and removes any prefixes that are no longer in use.
-func (m *Prefixes) ReloadServices(services map[key.Service]string) {
+func (m *Prefixes) ReloadServices(services map[key.Service]ServiceAnnounce) {
 	// Lock the mutex for writing since we are modifying the prefixes map.
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
 	// Temporarily stores the new set of services mapped by their prefixes.
 	prefixServices := make(map[netip.Prefix][]key.Service)
-	// Construct mapping of prefixes to their new announce group.
+	// Construct mapping of prefixes to their new announce group and policy.
 	prefixGroup := make(map[netip.Prefix]string)
-	for service, group := range services {
-		prefix := service.Prefix()
+	prefixPolicy := make(map[netip.Prefix]Policy)
+	// Construct mapping of prefixes to their critical services.
+	prefixCritical := make(map[netip.Prefix]map[key.Service]bool)
+	// Construct mapping of services to their prefixes.
+	servicePrefix := make(map[key.Service]netip.Prefix, len(services))
+	for service, announce := range services {
+		prefix := announcePrefix(service, announce)
 		prefixServices[prefix] = append(prefixServices[prefix], service)
-		prefixGroup[prefix] = group
+		prefixGroup[prefix] = announce.Group
+		prefixPolicy[prefix] = announce.Policy
+		servicePrefix[service] = prefix
+		if announce.Critical {
+			if prefixCritical[prefix] == nil {
+				prefixCritical[prefix] = make(map[key.Service]bool)
+			}
+			prefixCritical[prefix][service] = true
+		}
 	}
+	m.services = servicePrefix
 
 	// Iterate over the existing prefixes to update or remove them.
 	for prefix, state := range m.prefixes {
@@ -122,7 +142,7 @@ func (m *Prefixes) ReloadServices(services map[key.Service]string) {
 			}
 
 			oldStatus := state.Status()
-			state.ApplyServices(newServices)
+			state.ApplyServices(newServices, prefixPolicy[prefix], prefixCritical[prefix])
 			newStatus := state.Status()
 
 			if newStatus != oldStatus || newGroup != oldGroup {
@@ -148,7 +168,9 @@ func (m *Prefixes) ReloadServices(services map[key.Service]string) {
 	// Add any new prefixes.
 	for prefix, services := range prefixServices {
 		group := prefixGroup[prefix]
-		m.prefixes[prefix] = newState(group, services)
+		state := newState(group, prefixPolicy[prefix], services)
+		state.critical = prefixCritical[prefix]
+		m.prefixes[prefix] = state
 	}
 }
 
@@ -161,7 +183,11 @@ func (m *Prefixes) UpdateService(service key.Service, status ServiceStatus) erro
 	defer m.mu.RUnlock()
 
 	// Retrieve the prefix associated with the service.
-	prefix := service.Prefix()
+	prefix, exists := m.services[service]
+	if !exists {
+		// Return an error if the service is not a member of any prefix.
+		return fmt.Errorf("%w, service: %s", ErrPrefixNotFound, service)
+	}
 
 	// Find the state associated with the prefix.
 	state := m.prefixes[prefix]
@@ -180,6 +206,14 @@ func (m *Prefixes) UpdateService(service key.Service, status ServiceStatus) erro
 	return nil
 }
 
+// Prefix returns the prefix the service is member of.
+func (m *Prefixes) Prefix(service key.Service) (prefix netip.Prefix, exists bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	prefix, exists = m.services[service]
+	return prefix, exists
+}
+
 // StatusFor returns the current status of passed prefixes.
 func (m *Prefixes) StatusFor(prefixes []netip.Prefix) map[netip.Prefix]PrefixStatus {
 	// Lock the mutex for reading since we are only reading from the prefixes
@@ -203,6 +237,39 @@ func (m *Prefixes) StatusFor(prefixes []netip.Prefix) map[netip.Prefix]PrefixSta
 	return status
 }
 
+// PrefixInfo represents the current state of the prefix with explanation of
+// its readiness.
+type PrefixInfo struct {
+	Prefix netip.Prefix
+	Group  string
+	Policy Policy
+	Status PrefixStatus
+	// Services is the list of member services with their statuses.
+	Services map[key.Service]ServiceStatus
+	// Blocking is the list of disabled services preventing the unready
+	// prefix from being announced.
+	Blocking []key.Service
+	// Changed is the time of the last prefix status change. It is zero if
+	// the status has not changed since the prefix was loaded.
+	Changed time.Time
+}
+
+// Info returns the current information about all known prefixes sorted by
+// prefix.
+func (m *Prefixes) Info() []PrefixInfo {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	info := make([]PrefixInfo, 0, len(m.prefixes))
+	for prefix, state := range m.prefixes {
+		info = append(info, state.info(prefix))
+	}
+	slices.SortFunc(info, func(a, b PrefixInfo) int {
+		return cmp.Or(a.Prefix.Addr().Compare(b.Prefix.Addr()), cmp.Compare(a.Prefix.Bits(), b.Prefix.Bits()))
+	})
+	return info
+}
+
 // Events retrieves and clears the events storage.
 func (m *Prefixes) Events() map[PrefixKey]PrefixStatus {
 	return m.events.Flush()
@@ -210,38 +277,51 @@ func (m *Prefixes) Events() map[PrefixKey]PrefixStatus {
 
 // prefixState manages the state of services associated with a prefix. It tracks
 // the active services and determines if the prefix is ready based on the
-// quorum.
+// quorum and the policy.
 type prefixState struct {
 	group    string                        // announce group of the prefix
+	policy   Policy                        // rule the prefix status is calculated by
+	critical map[key.Service]bool          // services marked as critical for the [PolicyCritical] mode
 	services map[key.Service]ServiceStatus // keeps set of the services for this prefix
+	changed  time.Time                     // time of the last prefix status change
 	active   int                           // the number of active services for this prefix
 	quorum   int                           // the number of services required for the prefix to be considered ready
 	mu       sync.RWMutex                  // protects access to the prefixState to ensure safe concurrent access
 }
 
-// newState creates a new prefixState with the specified quorum.
-func newState(group string, services []key.Service) *prefixState {
+// newState creates a new prefixState with the specified policy.
+func newState(group string, policy Policy, services []key.Service) *prefixState {
 	servicesSet := make(map[key.Service]ServiceStatus)
 	for _, service := range services {
 		servicesSet[service] = ServiceDisabled
 	}
 	return &prefixState{
 		group:    group,
+		policy:   policy,
 		services: servicesSet,
 		active:   0,
-		// Number of services used as a quorum because prefix announce should
-		// not be raised until all dependent services are ready.
+		// Number of services used as a quorum because by default prefix
+		// announce should not be raised until all dependent services are
+		// ready.
 		quorum: len(servicesSet),
 	}
 }
 
-// ApplyServices updates the active services for the prefix based on the
-// provided list of new services.
-func (m *prefixState) ApplyServices(newServices []key.Service) {
+// ApplyServices updates the active services, the policy and the critical
+// services for the prefix based on the provided list of new services.
+func (m *prefixState) ApplyServices(newServices []key.Service, policy Policy, critical map[key.Service]bool) {
 	// Lock the mutex for writing since we are modifying the activeServices map.
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
+	// Record the time of the prefix status change, if any.
+	oldStatus := m.status()
+	defer func() {
+		if m.status() != oldStatus {
+			m.changed = time.Now()
+		}
+	}()
+
 	// Create a new set of services based on the provided list.
 	newServicesSet := make(map[key.Service]ServiceStatus, len(newServices))
 	// Count the number of active services in the new set of services.
@@ -265,6 +345,8 @@ func (m *prefixState) ApplyServices(newServices []key.Service) {
 	m.active = active
 	// Update the quorum based on the number of new services.
 	m.quorum = len(newServices)
+	m.policy = policy
+	m.critical = critical
 }
 
 // UpdateService updates the status of a specific service within the prefix. It
@@ -280,6 +362,8 @@ func (m *prefixState) UpdateService(service key.Service, status ServiceStatus) (
 		return m.status()
 	}
 
+	oldStatus := m.status()
+
 	// Update the active count based on the new status of the service.
 	switch status {
 	case ServiceEnabled:
@@ -291,8 +375,13 @@ func (m *prefixState) UpdateService(service key.Service, status ServiceStatus) (
 	// Update the status of the service.
 	m.services[service] = status
 
-	// Return the updated status of the prefix.
-	return m.status()
+	// Return the updated status of the prefix recording the time of its
+	// change.
+	newStatus = m.status()
+	if newStatus != oldStatus {
+		m.changed = time.Now()
+	}
+	return newStatus
 }
 
 // Status returns the current status of the prefix.
@@ -323,13 +412,66 @@ func (m *prefixState) UpdateGroup(group string) {
 }
 
 // status is a helper method to determine if the prefix is ready based on the
-// number of active services and the quorum. It assumes the prefixState mutex is
-// already held.
+// number of active services, the quorum and the policy. It assumes the
+// prefixState mutex is already held.
 func (m *prefixState) status() PrefixStatus {
-	// A prefix is considered ready if the number of active services meets the
-	// quorum.
-	if m.quorum == m.active && m.active != 0 {
+	// A prefix is considered ready if the number of active services satisfies
+	// the policy (meets the quorum by default).
+	active, total := m.active, m.quorum
+	if m.policy.Mode == PolicyCritical && len(m.critical) != 0 {
+		// Only the critical services are taken into account.
+		active, total = 0, len(m.critical)
+		for service := range m.critical {
+			if m.services[service] == ServiceEnabled {
+				active++
+			}
+		}
+	}
+	if m.policy.Ready(active, total) {
 		return Ready
 	}
 	return Unready
 }
+
+// blocking returns the disabled services preventing the unready prefix from
+// being announced. It assumes the prefixState mutex is already held.
+func (m *prefixState) blocking() []key.Service {
+	if m.status() == Ready {
+		return nil
+	}
+	criticalOnly := m.policy.Mode == PolicyCritical && len(m.critical) != 0
+
+	var services []key.Service
+	for service, status := range m.services {
+		if status == ServiceEnabled || (criticalOnly && !m.critical[service]) {
+			continue
+		}
+		services = append(services, service)
+	}
+	slices.SortFunc(services, key.Service.Compare)
+	return services
+}
+
+// info returns the current information about the prefix. It assumes the
+// prefixState mutex is not held.
+func (m *prefixState) info(prefix netip.Prefix) PrefixInfo {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return PrefixInfo{
+		Prefix:   prefix,
+		Group:    m.group,
+		Policy:   m.policy,
+		Status:   m.status(),
+		Services: maps.Clone(m.services),
+		Blocking: m.blocking(),
+		Changed:  m.changed,
+	}
+}
+
+// announcePrefix returns the prefix announced for the service.
+func announcePrefix(service key.Service, announce ServiceAnnounce) netip.Prefix {
+	if announce.Prefix.IsValid() {
+		return announce.Prefix
+	}
+	return service.Prefix()
+}
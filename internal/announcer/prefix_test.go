@@ -11,15 +11,15 @@ import (
 )
 
 // Helper function to create a default service.
-func defaultService() (service key.Service, serviceWithGroup map[key.Service]string) {
+func defaultService() (service key.Service, serviceWithGroup map[key.Service]ServiceAnnounce) {
 	service = key.Service{Addr: netip.MustParseAddr("127.0.0.1"), Port: 80, Proto: "TCP"}
-	serviceWithGroup = make(map[key.Service]string)
-	serviceWithGroup[service] = "default"
+	serviceWithGroup = make(map[key.Service]ServiceAnnounce)
+	serviceWithGroup[service] = ServiceAnnounce{Group: "default"}
 	return service, serviceWithGroup
 }
 
 // Helper function to create a list of default services.
-func defaultServices() (services []key.Service, servicesWithGroups map[key.Service]string) {
+func defaultServices() (services []key.Service, servicesWithGroups map[key.Service]ServiceAnnounce) {
 	services = []key.Service{
 		{Addr: netip.MustParseAddr("127.0.0.1"), Port: 80, Proto: "TCP"},          // Service1
 		{Addr: netip.MustParseAddr("127.0.0.1"), Port: 443, Proto: "TCP"},         // Service2
@@ -27,9 +27,9 @@ func defaultServices() (services []key.Service, servicesWithGroups map[key.Servi
 		{Addr: netip.MustParseAddr("2001:dead:beef::1"), Port: 443, Proto: "TCP"}, // Service4
 	}
 
-	servicesWithGroups = make(map[key.Service]string, len(services))
+	servicesWithGroups = make(map[key.Service]ServiceAnnounce, len(services))
 	for _, service := range services {
-		servicesWithGroups[service] = "default"
+		servicesWithGroups[service] = ServiceAnnounce{Group: "default"}
 	}
 
 	return services, servicesWithGroups
@@ -44,8 +44,8 @@ func TestPrefix_ReloadServices_InitialReload(t *testing.T) {
 
 	// Expected prefixes and their states after the initial reload.
 	prefixes := map[netip.Prefix]*prefixState{
-		netip.MustParsePrefix("127.0.0.1/32"):          newState("default", services[:2]),
-		netip.MustParsePrefix("2001:dead:beef::1/128"): newState("default", services[2:]),
+		netip.MustParsePrefix("127.0.0.1/32"):          newState("default", Policy{}, services[:2]),
+		netip.MustParsePrefix("2001:dead:beef::1/128"): newState("default", Policy{}, services[2:]),
 	}
 	assert.Equal(t, prefixes, prefixRegistry.prefixes)
 }
@@ -141,15 +141,15 @@ func TestPrefix_ReloadServices_UpdateReload_AddServices_WithoutAnnounces(t *test
 	// prefix.
 	newService1 := key.Service{Addr: netip.MustParseAddr("127.0.0.1"), Port: 8080, Proto: "TCP"}       // service for existing prefix
 	newService2 := key.Service{Addr: netip.MustParseAddr("2001:dead:beef::2"), Port: 80, Proto: "TCP"} // service for new prefix
-	servicesWithGroups[newService1] = "default"
-	servicesWithGroups[newService2] = "default"
+	servicesWithGroups[newService1] = ServiceAnnounce{Group: "default"}
+	servicesWithGroups[newService2] = ServiceAnnounce{Group: "default"}
 	services = append(services, newService1, newService2)
 	prefixRegistry.ReloadServices(servicesWithGroups)
 
 	prefixes := map[netip.Prefix]*prefixState{
-		netip.MustParsePrefix("127.0.0.1/32"):          newState("default", []key.Service{services[0], services[1], services[4]}),
-		netip.MustParsePrefix("2001:dead:beef::1/128"): newState("default", []key.Service{services[2], services[3]}),
-		netip.MustParsePrefix("2001:dead:beef::2/128"): newState("default", []key.Service{services[5]}),
+		netip.MustParsePrefix("127.0.0.1/32"):          newState("default", Policy{}, []key.Service{services[0], services[1], services[4]}),
+		netip.MustParsePrefix("2001:dead:beef::1/128"): newState("default", Policy{}, []key.Service{services[2], services[3]}),
+		netip.MustParsePrefix("2001:dead:beef::2/128"): newState("default", Policy{}, []key.Service{services[5]}),
 	}
 	assert.Equal(t, prefixes, prefixRegistry.prefixes)
 }
@@ -166,12 +166,12 @@ func TestPrefix_ReloadServices_UpdateReload_RemoveServices_WithoutAnnounces(t *t
 
 	// Remove all but one service and reload the registry, then check the
 	// updated prefixes.
-	services = services[:1]                                             // keep only Service1
-	servicesWithGroups = map[key.Service]string{services[0]: "default"} // keep only Service1
+	services = services[:1]                                                               // keep only Service1
+	servicesWithGroups = map[key.Service]ServiceAnnounce{services[0]: {Group: "default"}} // keep only Service1
 	prefixRegistry.ReloadServices(servicesWithGroups)
 
 	prefixes := map[netip.Prefix]*prefixState{
-		netip.MustParsePrefix("127.0.0.1/32"): newState("default", services),
+		netip.MustParsePrefix("127.0.0.1/32"): newState("default", Policy{}, services),
 	}
 	assert.Equal(t, prefixes, prefixRegistry.prefixes)
 	assert.Equal(t, 1, prefixRegistry.prefixes[netip.MustParsePrefix("127.0.0.1/32")].quorum)
@@ -227,7 +227,7 @@ func TestPrefix_ReloadServices_UpdateReload_AddServices_WithAnnounces(t *testing
 	// Add a new service for the existing prefix, reload the registry, and check
 	// that the prefix status becomes Unready.
 	newService := key.Service{Addr: netip.MustParseAddr("127.0.0.1"), Port: 8080, Proto: "TCP"}
-	servicesWithGroups[newService] = "default"
+	servicesWithGroups[newService] = ServiceAnnounce{Group: "default"}
 	prefixRegistry.ReloadServices(servicesWithGroups)
 
 	assert.Equal(t, Unready, prefixRegistry.prefixes[prefix].Status())
@@ -323,3 +323,84 @@ func TestPrefix_ReloadServices_UpdateReload_RemoveAllEnabledServicesForPrefix_Wi
 	}
 	assert.Equal(t, expectedEvents, prefixRegistry.Events())
 }
+
+// TestPrefix_AggregatePolicy tests that the services aggregated into the
+// declared prefix are combined according to the prefix policy.
+func TestPrefix_AggregatePolicy(t *testing.T) {
+	services, _ := defaultServices()
+	aggregate := netip.MustParsePrefix("127.0.0.0/24")
+
+	tests := []struct {
+		policy   Policy
+		expected []PrefixStatus // prefix status after each enabled service
+	}{
+		{Policy{}, []PrefixStatus{Unready, Ready}},
+		{Policy{Mode: PolicyAny}, []PrefixStatus{Ready, Ready}},
+		{Policy{Mode: PolicyAtLeast, Min: 2}, []PrefixStatus{Unready, Ready}},
+	}
+
+	for _, tt := range tests {
+		servicesWithPrefix := map[key.Service]ServiceAnnounce{
+			services[0]: {Group: "default", Prefix: aggregate, Policy: tt.policy},
+			services[1]: {Group: "default", Prefix: aggregate, Policy: tt.policy},
+		}
+		prefixRegistry := NewPrefixes()
+		prefixRegistry.ReloadServices(servicesWithPrefix)
+
+		prefix, exists := prefixRegistry.Prefix(services[0])
+		require.True(t, exists)
+		assert.Equal(t, aggregate, prefix)
+
+		for i, service := range services[:2] {
+			require.NoError(t, prefixRegistry.UpdateService(service, ServiceEnabled))
+			status := prefixRegistry.StatusFor([]netip.Prefix{aggregate})
+			assert.Equal(t, tt.expected[i], status[aggregate], tt.policy.Mode)
+		}
+	}
+}
+
+// TestPrefix_CriticalPolicy tests that only the critical services affect the
+// prefix with the critical policy and that the disabled critical services are
+// reported as blocking.
+func TestPrefix_CriticalPolicy(t *testing.T) {
+	services, _ := defaultServices()
+	prefix := services[0].Prefix()
+	policy := Policy{Mode: PolicyCritical}
+
+	prefixRegistry := NewPrefixes()
+	prefixRegistry.ReloadServices(map[key.Service]ServiceAnnounce{
+		services[0]: {Group: "default", Policy: policy, Critical: true},
+		services[1]: {Group: "default", Policy: policy},
+	})
+
+	info := prefixRegistry.Info()
+	require.Len(t, info, 1)
+	assert.Equal(t, Unready, info[0].Status)
+	assert.Equal(t, []key.Service{services[0]}, info[0].Blocking)
+
+	// The non-critical service does not make the prefix ready.
+	require.NoError(t, prefixRegistry.UpdateService(services[1], ServiceEnabled))
+	assert.Equal(t, Unready, prefixRegistry.StatusFor([]netip.Prefix{prefix})[prefix])
+
+	// The critical service does.
+	require.NoError(t, prefixRegistry.UpdateService(services[0], ServiceEnabled))
+	require.NoError(t, prefixRegistry.UpdateService(services[1], ServiceDisabled))
+	assert.Equal(t, Ready, prefixRegistry.StatusFor([]netip.Prefix{prefix})[prefix])
+	assert.Empty(t, prefixRegistry.Info()[0].Blocking)
+}
+
+// TestPrefix_Blocking tests that all disabled services are reported as
+// blocking the unready prefix with the default policy.
+func TestPrefix_Blocking(t *testing.T) {
+	services, servicesWithGroups := defaultServices()
+	prefixRegistry := NewPrefixes()
+	prefixRegistry.ReloadServices(servicesWithGroups)
+
+	require.NoError(t, prefixRegistry.UpdateService(services[0], ServiceEnabled))
+
+	info := prefixRegistry.Info()
+	require.Len(t, info, 2)
+	assert.Equal(t, services[0].Prefix(), info[0].Prefix)
+	assert.Equal(t, []key.Service{services[1]}, info[0].Blocking)
+	assert.Equal(t, []key.Service{services[2], services[3]}, info[1].Blocking)
+}
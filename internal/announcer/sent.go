@@ -0,0 +1,76 @@
+package announcer
+
+import (
+	"net/netip"
+	"sync"
+	"time"
+)
+
+// sentState is the prefix state delivered to the external announcer.
+type sentState struct {
+	status PrefixStatus // last successfully sent status of the prefix
+	ts     time.Time    // time of the last successful send
+}
+
+// sentRegistry keeps the prefix states successfully delivered to the external
+// announcer, that is, what the external announcer is currently told.
+type sentRegistry struct {
+	prefixes map[PrefixKey]sentState
+	mu       sync.RWMutex // to protect concurent access to the prefixes map
+}
+
+// newSentRegistry creates a new instance of sentRegistry.
+func newSentRegistry() *sentRegistry {
+	return &sentRegistry{
+		prefixes: make(map[PrefixKey]sentState),
+	}
+}
+
+// Store records the successfully sent statuses of the prefixes of the group
+// and returns the number of prefixes announced in the group.
+//
+// The withdrawn prefixes that are no longer known are forgotten.
+func (m *sentRegistry) Store(group string, prefixes map[netip.Prefix]PrefixStatus, known func(netip.Prefix) bool) (announced int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	for prefix, status := range prefixes {
+		m.prefixes[PrefixKey{Prefix: prefix, Group: group}] = sentState{status: status, ts: now}
+	}
+
+	for prefixKey, state := range m.prefixes {
+		if prefixKey.Group != group {
+			continue
+		}
+		if state.status == Ready {
+			announced++
+			continue
+		}
+		if !known(prefixKey.Prefix) {
+			delete(m.prefixes, prefixKey)
+		}
+	}
+	return announced
+}
+
+// Get returns the last successfully sent state of the prefix of the group.
+func (m *sentRegistry) Get(prefixKey PrefixKey) (state sentState, exists bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	state, exists = m.prefixes[prefixKey]
+	return state, exists
+}
+
+// Announced returns the prefixes of the group the external announcer is told
+// to be ready.
+func (m *sentRegistry) Announced(group string) (prefixes []netip.Prefix) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for prefixKey, state := range m.prefixes {
+		if prefixKey.Group == group && state.status == Ready {
+			prefixes = append(prefixes, prefixKey.Prefix)
+		}
+	}
+	return prefixes
+}
@@ -7,13 +7,17 @@ import (
 	"gopkg.in/yaml.v2"
 
 	"github.com/yanet-platform/monalive/internal/announcer"
+	"github.com/yanet-platform/monalive/internal/announcer/bgp"
 	"github.com/yanet-platform/monalive/internal/announcer/bird"
+	"github.com/yanet-platform/monalive/internal/announcer/hook"
 	"github.com/yanet-platform/monalive/internal/balancer"
 	"github.com/yanet-platform/monalive/internal/balancer/yanet"
 	"github.com/yanet-platform/monalive/internal/core"
+	"github.com/yanet-platform/monalive/internal/core/limiter"
 	"github.com/yanet-platform/monalive/internal/monitoring/logger"
 	"github.com/yanet-platform/monalive/internal/monitoring/metrics"
 	"github.com/yanet-platform/monalive/internal/server"
+	"github.com/yanet-platform/monalive/internal/shadow"
 	"github.com/yanet-platform/monalive/internal/utils/exp"
 	"github.com/yanet-platform/monalive/pkg/checktun"
 )
@@ -24,15 +28,21 @@ type Config struct {
 
 	Balancer *balancer.Config `yaml:"balancer"`
 	YANET    *yanet.Config    `yaml:"yanet"`
+	Shadow   shadow.Config    `yaml:"shadow"`
 
 	Announcer *announcer.Config `yaml:"announcer"`
 	Bird      *bird.Config      `yaml:"bird"`
+	BGP       *bgp.Config       `yaml:"bgp"`
+	Exec      *hook.Config      `yaml:"exec"`
+	Webhook   *hook.Config      `yaml:"webhook"`
 
 	TLSMinVersion string              `yaml:"tls_min_version"`
 	Service       *core.ManagerConfig `yaml:"service"`
 	Server        *server.Config      `yaml:"server"`
 	Tunnel        checktun.Config     `yaml:"check_tun"`
 
+	CheckLimiter *limiter.Config `yaml:"check_limiter"`
+
 	Experiments exp.Config `yaml:"experiments"`
 }
 
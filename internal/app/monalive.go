@@ -9,13 +9,18 @@ import (
 	"golang.org/x/sync/errgroup"
 
 	"github.com/yanet-platform/monalive/internal/announcer"
+	"github.com/yanet-platform/monalive/internal/announcer/bgp"
 	"github.com/yanet-platform/monalive/internal/announcer/bird"
+	"github.com/yanet-platform/monalive/internal/announcer/hook"
 	"github.com/yanet-platform/monalive/internal/balancer"
+	"github.com/yanet-platform/monalive/internal/balancer/ipvs"
 	"github.com/yanet-platform/monalive/internal/balancer/yanet"
 	"github.com/yanet-platform/monalive/internal/core"
+	"github.com/yanet-platform/monalive/internal/core/limiter"
 	"github.com/yanet-platform/monalive/internal/monitoring/metrics"
 	"github.com/yanet-platform/monalive/internal/monitoring/metrics/prometheus"
 	"github.com/yanet-platform/monalive/internal/server"
+	"github.com/yanet-platform/monalive/internal/shadow"
 	"github.com/yanet-platform/monalive/internal/utils/xtls"
 	"github.com/yanet-platform/monalive/pkg/checktun"
 )
@@ -37,7 +42,7 @@ type Monalive struct {
 }
 
 // New creates a new instance of Monalive service.
-func New(config Config, logger *log.Logger) (*Monalive, error) {
+func New(config Config, logger *log.Logger) (_ *Monalive, err error) {
 	// Set the minimum TLS version from the configuration.
 	if err := xtls.SetTLSMinVersion(config.TLSMinVersion); err != nil {
 		logger.Warn("failed to set TLSMinVersion from config", log.Error(err))
@@ -55,23 +60,40 @@ func New(config Config, logger *log.Logger) (*Monalive, error) {
 		)
 	}
 
-	// Initialize the Bird announcer.
-	bird, err := bird.New(config.Bird, config.Announcer.AnnounceGroup)
+	// Initialize the external announcer client.
+	if err := config.Announcer.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid announcer config: %w", err)
+	}
+	// In the shadow mode the decisions are recorded instead of being applied.
+	var recorder *shadow.Recorder
+	if config.Balancer.GetBackend() == balancer.BackendShadow {
+		var err error
+		if recorder, err = shadow.NewRecorder(config.Shadow, logger); err != nil {
+			return nil, fmt.Errorf("failed to create shadow recorder: %w", err)
+		}
+	}
+	announcerClient, err := newAnnouncerClient(config, recorder, logger)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create bird: %w", err)
+		return nil, err
 	}
+	// Shut down the announcer client if any of the next components fails.
+	defer func() {
+		if err != nil {
+			announcerClient.Shutdown()
+		}
+	}()
 
 	// Create an announcer instance.
-	announcer := announcer.New(config.Announcer, bird, logger)
+	announcer := announcer.New(config.Announcer, announcerClient, scopedMetrics.Scope(metrics.Global), logger)
 
-	// Initialize the YANET client to communicate with YANET control plane.
-	yanetClient, err := yanet.NewClient(config.YANET)
+	// Initialize the load balancer client of the configured backend.
+	balancerClient, err := newBalancerClient(config, recorder)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create yanet client: %w", err)
+		return nil, err
 	}
 
 	// Create a balancer worker instance.
-	balancer := balancer.New(config.Balancer, yanetClient, announcer, logger)
+	balancer := balancer.New(config.Balancer, balancerClient, announcer, scopedMetrics.Scope(metrics.Global), logger)
 
 	// Initialize the check tunneler.
 	tunneler, err := checktun.New(config.Tunnel, logger)
@@ -79,8 +101,17 @@ func New(config Config, logger *log.Logger) (*Monalive, error) {
 		return nil, fmt.Errorf("failed to create checktun: %w", err)
 	}
 
+	// Create the health checks limiter. It is nil if not configured, so the
+	// checks are not limited.
+	if config.CheckLimiter != nil {
+		if err := config.CheckLimiter.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid check limiter config: %w", err)
+		}
+	}
+	checkLimiter := limiter.New(config.CheckLimiter, scopedMetrics.Scope(metrics.Global))
+
 	// Create the core service and its manager.
-	coreService := core.New(announcer, balancer, scopedMetrics, logger)
+	coreService := core.New(announcer, balancer, scopedMetrics, logger, core.WithCheckLimiter(checkLimiter))
 	coreManager, err := core.NewManager(config.Service, coreService, scopedMetrics.Scope(metrics.Global), logger)
 	if err != nil {
 		return nil, err
@@ -144,6 +175,21 @@ func (m *Monalive) Run(ctx context.Context) error {
 
 		m.server.Stop()
 
+		// Persist the states of the reals before they are disabled by the
+		// core shutdown.
+		if err := m.coreManager.DumpStates(); err != nil {
+			m.logger.Error("failed to dump real states", log.Error(err))
+		}
+
+		// In the keep state mode the reals disabled by the core shutdown are
+		// neither disabled in the load balancer nor withdrawn, so the
+		// restarted instance takes over the current state.
+		if m.coreManager.KeepState() {
+			m.logger.Info("keeping load balancer and announcer state on shutdown")
+			m.balancer.Freeze()
+			m.announcer.Freeze()
+		}
+
 		// It is important that the core is stopped before the balancer.
 		// Otherwise, the load balancer state will keep false enabled reals.
 		m.core.Stop()
@@ -160,3 +206,109 @@ func (m *Monalive) Run(ctx context.Context) error {
 	// Wait for all goroutines in the errgroup to complete.
 	return wg.Wait()
 }
+
+// KeepStateOnShutdown makes the shutdown keep the current load balancer and
+// announcer state instead of disabling the reals and withdrawing the prefixes.
+func (m *Monalive) KeepStateOnShutdown() {
+	m.coreManager.SetKeepState(true)
+}
+
+// ReloadAnnouncer applies the new announcer configuration. It allows to add,
+// remove and change the announce groups without restart.
+func (m *Monalive) ReloadAnnouncer(config *announcer.Config) error {
+	return m.announcer.Reload(config)
+}
+
+// newAnnouncerClient creates the external announcer client according to the
+// backend settings of the announce groups. The client dispatches the announces
+// to the client of each group backend, so the groups may use different
+// backends and may be reloaded at runtime.
+//
+// The backend clients are created with the backend settings of the passed
+// config, they are not changed by the announcer reloads. If the recorder is
+// set, the announces are recorded by it instead.
+func newAnnouncerClient(config Config, recorder *shadow.Recorder, logger *log.Logger) (announcer.Client, error) {
+	if recorder != nil {
+		return shadow.NewAnnouncerClient(recorder), nil
+	}
+	client := announcer.NewMultiClient(func(backend announcer.Backend, groups []*announcer.GroupConfig) (announcer.Client, error) {
+		return newBackendClient(config, backend, groups, logger)
+	})
+	if err := client.ReloadGroups(config.Announcer.ResolvedGroups()); err != nil {
+		return nil, err
+	}
+	return client, nil
+}
+
+// newBalancerClient creates the load balancer client of the configured
+// backend. The recorder is set in the shadow mode only.
+func newBalancerClient(config Config, recorder *shadow.Recorder) (balancer.LoadBalancerClient, error) {
+	switch backend := config.Balancer.GetBackend(); backend {
+	case balancer.BackendYANET:
+		// Communicate with YANET control plane.
+		client, err := yanet.NewClient(config.YANET)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create yanet client: %w", err)
+		}
+		return client, nil
+
+	case balancer.BackendIPVS:
+		client, err := ipvs.NewClient()
+		if err != nil {
+			return nil, fmt.Errorf("failed to create ipvs client: %w", err)
+		}
+		return client, nil
+
+	case balancer.BackendShadow:
+		// The live YANET state is compared with the recorded decisions.
+		live, err := yanet.NewClient(config.YANET)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create yanet client: %w", err)
+		}
+		return shadow.NewBalancerClient(recorder, live), nil
+
+	default:
+		return nil, fmt.Errorf("unknown balancer backend %q", backend)
+	}
+}
+
+// newBackendClient creates the external announcer client of the backend for
+// the announce groups.
+func newBackendClient(config Config, backend announcer.Backend, groups []*announcer.GroupConfig, logger *log.Logger) (announcer.Client, error) {
+	switch backend {
+	case announcer.BackendBird:
+		names := make([]string, 0, len(groups))
+		for _, group := range groups {
+			names = append(names, group.Name)
+		}
+		client, err := bird.New(config.Bird, names)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create bird: %w", err)
+		}
+		return client, nil
+
+	case announcer.BackendBGP:
+		client, err := bgp.New(config.BGP, groups, logger)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create bgp: %w", err)
+		}
+		return client, nil
+
+	case announcer.BackendExec:
+		client, err := hook.NewExec(config.Exec)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create exec: %w", err)
+		}
+		return client, nil
+
+	case announcer.BackendWebhook:
+		client, err := hook.NewWebhook(config.Webhook)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create webhook: %w", err)
+		}
+		return client, nil
+
+	default:
+		return nil, fmt.Errorf("unknown announcer backend: %q", backend)
+	}
+}
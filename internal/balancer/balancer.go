@@ -8,12 +8,17 @@ package balancer
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync/atomic"
 	"time"
 
 	log "go.uber.org/zap"
 	"golang.org/x/sync/errgroup"
 
 	"github.com/yanet-platform/monalive/internal/announcer"
+	"github.com/yanet-platform/monalive/internal/monitoring/metrics"
 	"github.com/yanet-platform/monalive/internal/types/key"
 	"github.com/yanet-platform/monalive/internal/types/weight"
 	"github.com/yanet-platform/monalive/internal/types/xevent"
@@ -21,14 +26,101 @@ import (
 	"github.com/yanet-platform/monalive/internal/utils/shutdown"
 )
 
+// ErrUnknownModule is returned when the load balancer module is not reported by
+// the load balancer.
+var ErrUnknownModule = errors.New("unknown balancer module")
+
+// ErrNotComparable is returned when the load balancer client does not compare
+// its decisions with the load balancer state.
+var ErrNotComparable = errors.New("balancer client does not compare decisions")
+
 // LoadBalancerClient defines the interface that a balancer client must implement.
-// It provides methods to enable or disable a real server and to flush changes.
+// It provides methods to enable or disable a real server, to apply a batch of
+// real server updates and to flush changes.
 type LoadBalancerClient interface {
 	EnableReal(ctx context.Context, key key.Balancer, weight weight.Weight) error
 	DisableReal(ctx context.Context, key key.Balancer) error
+	// ApplyReals applies the batch of real server updates. It returns the keys
+	// of the updates failed to be applied along with the error.
+	ApplyReals(ctx context.Context, updates []RealUpdate) (failed []key.Balancer, err error)
 	Flush(ctx context.Context) error
 }
 
+// RealUpdate represents the update of a real server state.
+type RealUpdate struct {
+	Key    key.Balancer
+	Enable bool
+	// Weight is the weight of the enabled real server. It is ignored for the
+	// disabled ones.
+	Weight weight.Weight
+}
+
+// ModuleResolver defines an interface of the clients with the default load
+// balancer module. It is used to resolve the empty modules of the keys, so the
+// keys are looked up in the modules they are configured in.
+type ModuleResolver interface {
+	// ResolveModule returns the passed module or the default one if it is
+	// empty.
+	ResolveModule(module string) string
+}
+
+// ServiceConfigurer defines an interface of the clients managing the virtual
+// services of the load balancer themselves, as opposed to the clients updating
+// the reals of the services configured by other means.
+type ServiceConfigurer interface {
+	// ConfigureServices creates or updates the virtual services. The services
+	// configured by the previous call and missing in the passed ones are
+	// removed.
+	ConfigureServices(ctx context.Context, services []VirtualService) error
+}
+
+// VirtualService describes the virtual service of the load balancer.
+type VirtualService struct {
+	Key    key.Service
+	Module string
+	// Scheduler is the name of the scheduler distributing the connections
+	// over the reals.
+	Scheduler string
+	// ForwardingMethod is the method the packets are forwarded to the reals
+	// with, unless the real sets its own one.
+	ForwardingMethod string
+	// OnePacket enables the one-packet scheduling.
+	OnePacket bool
+	Reals     []VirtualReal
+}
+
+// VirtualReal describes the real server of the virtual service.
+type VirtualReal struct {
+	Key              key.Real
+	ForwardingMethod string
+}
+
+// Comparer defines an interface of the clients recording the decisions of
+// Monalive instead of applying them, which are able to compare the decisions
+// with the live load balancer state.
+type Comparer interface {
+	// Compare returns the reals whose decided state differs from their state
+	// in the load balancer.
+	Compare(ctx context.Context) ([]Drift, error)
+}
+
+// HealthReporter defines an interface of the clients reporting the health of
+// the connection to the load balancer.
+type HealthReporter interface {
+	Health() Health
+}
+
+// Health represents the health of the connection to the load balancer.
+type Health struct {
+	// Connected reports whether the last request to the load balancer
+	// succeeded.
+	Connected bool
+	// LastError is the error of the last failed request.
+	LastError string
+	// Since is the time the connection health changed last.
+	Since time.Time
+}
+
 // Stater defines an interface for obtaining the current state of the load
 // balancer reals.
 type Stater interface {
@@ -43,12 +135,15 @@ type Balancer struct {
 	announcer *announcer.Announcer                         // to process announce events after balancer changes
 	state     *State                                       // keeps balancer state
 	events    *event.Registry[key.Balancer, *xevent.Event] // stores events to the balancer
+	reconcile *reconciler                                  // detects the drift of the balancer state
+	frozen    atomic.Bool                                  // whether the new events are ignored
 	shutdown  *shutdown.Shutdown                           // manages graceful shutdown
+	metrics   *Metrics
 	log       *log.Logger
 }
 
 // New creates a new Balancer instance.
-func New(config *Config, client LoadBalancerClient, announcer *announcer.Announcer, logger *log.Logger) *Balancer {
+func New(config *Config, client LoadBalancerClient, announcer *announcer.Announcer, provider metrics.Provider, logger *log.Logger) *Balancer {
 	var state *State
 	// If the client implements the Stater interface, initialize the state.
 	if _, implements := client.(Stater); implements {
@@ -61,7 +156,9 @@ func New(config *Config, client LoadBalancerClient, announcer *announcer.Announc
 		announcer: announcer,
 		state:     state,
 		events:    event.NewRegistry[key.Balancer, *xevent.Event](),
+		reconcile: newReconciler(),
 		shutdown:  shutdown.New(),
+		metrics:   NewMetrics(provider),
 		log:       logger,
 	}
 }
@@ -96,9 +193,22 @@ func (m *Balancer) Stop() {
 	m.shutdown.Do()
 }
 
+// Freeze makes the balancer ignore the new events, keeping the current state of
+// the load balancer. The events stored before are applied by the last update on
+// stop, the ones failed to be applied are dropped. It is used to stop Monalive
+// without disabling the reals.
+func (m *Balancer) Freeze() {
+	m.frozen.Store(true)
+}
+
 // HandleEvent stores a new event into the event registry. Lately it will be
-// passed to the load balancer client.
+// passed to the load balancer client. The events are ignored if the balancer
+// is frozen.
 func (m *Balancer) HandleEvent(event *xevent.Event) {
+	if m.frozen.Load() {
+		return
+	}
+	event.Module = m.ResolveModule(event.Module)
 	// Store the event associated with the specified balancer key.
 	m.events.Store(event.Balancer, event)
 }
@@ -115,6 +225,7 @@ func (m *Balancer) LookupSubscription(ctx context.Context, key key.Balancer) <-c
 		return nil
 	}
 
+	key.Module = m.ResolveModule(key.Module)
 	if found := m.state.Lookup(key); found {
 		// If the key is already in the state, no need for a subscription.
 		return nil
@@ -151,6 +262,40 @@ func (m *Balancer) LookupSubscription(ctx context.Context, key key.Balancer) <-c
 	return notify
 }
 
+// ValidateModules checks that the load balancer reports the passed modules. The
+// empty module stands for the default one. The validation is skipped if the
+// client does not support state synchronization or the state can not be
+// obtained.
+func (m *Balancer) ValidateModules(ctx context.Context, modules []string) error {
+	client, implements := m.client.(Stater)
+	if !implements {
+		return nil
+	}
+
+	state, err := client.GetState(ctx)
+	if err != nil {
+		m.log.Warn("failed to validate balancer modules", log.Error(err))
+		return nil
+	}
+
+	for _, module := range modules {
+		module = m.ResolveModule(module)
+		if _, exists := state[module]; !exists {
+			return fmt.Errorf("%w: %q", ErrUnknownModule, module)
+		}
+	}
+	return nil
+}
+
+// ResolveModule returns the module or the default module of the client if it
+// is empty.
+func (m *Balancer) ResolveModule(module string) string {
+	if resolver, implements := m.client.(ModuleResolver); implements {
+		return resolver.ResolveModule(module)
+	}
+	return module
+}
+
 // stater periodically executes GetState request to the load balancer and stores
 // the response state. If the load balancer does not support state
 // synchronization, it returns nil.
@@ -163,12 +308,16 @@ func (m *Balancer) stater(ctx context.Context) error {
 	}
 
 	// Initial state sync.
+	requested := time.Now()
 	state, err := client.GetState(ctx)
 	if err != nil {
 		m.log.Error("failed to get balancer state", log.Error(err))
 	}
 	// Update the internal state with the fetched state.
 	m.state.Update(state)
+	if err == nil {
+		m.repairDrift(state, requested)
+	}
 
 	// Timer for periodic state updates.
 	updateTimer := time.NewTicker(m.config.SyncPeriod)
@@ -182,6 +331,7 @@ func (m *Balancer) stater(ctx context.Context) error {
 
 		// Trigger a state sync on each timer tick.
 		case <-updateTimer.C:
+			requested := time.Now()
 			state, err := client.GetState(ctx)
 			if err != nil {
 				m.log.Error("failed to get balancer state", log.Error(err))
@@ -189,6 +339,7 @@ func (m *Balancer) stater(ctx context.Context) error {
 			}
 			// Update the internal state with the new state.
 			m.state.Update(state)
+			m.repairDrift(state, requested)
 		}
 	}
 }
@@ -223,32 +374,33 @@ func (m *Balancer) updateBalancer(ctx context.Context) {
 	// the load balancer.
 	announceEvents := m.announcer.FlushServiceEvents()
 
-	// Process each event in the registry.
-	processed := m.events.Process(func(key key.Balancer, event *xevent.Event) error {
-		var err error
-		if event.New.Enable {
-			// Enable the real server with the specified weight.
-			err = m.client.EnableReal(ctx, key, event.New.Weight)
-		} else {
-			// Disable the real server.
-			err = m.client.DisableReal(ctx, key)
-		}
-		if err != nil {
-			m.log.Error(
-				"failed to update yanet real configuration",
-				log.Error(err),
-				log.String("event_type", "real handler"),
-			)
+	// Process the events in the registry in batches. The failed events are
+	// kept in the registry to be applied on the next update.
+	var appliedUpdates []RealUpdate
+	processed := m.events.ProcessBatch(func(events map[key.Balancer]*xevent.Event) []key.Balancer {
+		updates := make([]RealUpdate, 0, len(events))
+		for key, event := range events {
+			updates = append(updates, RealUpdate{
+				Key:    key,
+				Enable: event.New.Enable,
+				Weight: event.New.Weight,
+			})
 		}
-		return err
+		failed := m.applyReals(ctx, updates)
+		appliedUpdates = append(appliedUpdates, applied(updates, failed)...)
+		return failed
 	})
 
-	// If any events were processed, flush the changes to the client.
+	// If any events were processed, flush the changes to the client. The
+	// states of the reals are considered applied only once flushed.
 	if processed > 0 {
 		if err := m.client.Flush(ctx); err != nil {
 			m.log.Error("failed to flush balancer", log.Error(err))
+		} else {
+			m.reconcile.Applied(appliedUpdates, time.Now())
 		}
 	}
+	m.reportHealth()
 
 	// Since all known balancer events have been processed, it's safe to process
 	// announce events.
@@ -265,3 +417,143 @@ func (m *Balancer) updateBalancer(ctx context.Context) {
 		}
 	}
 }
+
+// applyReals applies the real server updates to the client in batches of the
+// configured size. Returns the keys of the updates failed to be applied.
+func (m *Balancer) applyReals(ctx context.Context, updates []RealUpdate) (failed []key.Balancer) {
+	for batch := range slices.Chunk(updates, m.config.GetMaxBatchSize()) {
+		batchFailed, err := m.client.ApplyReals(ctx, batch)
+		if err != nil {
+			m.log.Error(
+				"failed to update yanet real configuration",
+				log.Int("batch_size", len(batch)),
+				log.Int("failed", len(batchFailed)),
+				log.Error(err),
+				log.String("event_type", "real handler"),
+			)
+		}
+		failed = append(failed, batchFailed...)
+	}
+	return failed
+}
+
+// applied returns the updates except the failed ones.
+func applied(updates []RealUpdate, failed []key.Balancer) []RealUpdate {
+	if len(failed) == 0 {
+		return updates
+	}
+	failedSet := make(map[key.Balancer]struct{}, len(failed))
+	for _, key := range failed {
+		failedSet[key] = struct{}{}
+	}
+	result := make([]RealUpdate, 0, len(updates)-len(failed))
+	for _, update := range updates {
+		if _, exists := failedSet[update.Key]; !exists {
+			result = append(result, update)
+		}
+	}
+	return result
+}
+
+// SetReals sets the real servers of the current configuration. The states of
+// the reals removed from the configuration are no longer reconciled with the
+// load balancer state.
+func (m *Balancer) SetReals(reals []key.Balancer) {
+	for i := range reals {
+		reals[i].Module = m.ResolveModule(reals[i].Module)
+	}
+	m.reconcile.SetReals(reals)
+}
+
+// ConfigureServices creates or updates the virtual services in the load
+// balancer if the client manages them. Otherwise it does nothing.
+func (m *Balancer) ConfigureServices(ctx context.Context, services []VirtualService) error {
+	client, implements := m.client.(ServiceConfigurer)
+	if !implements {
+		return nil
+	}
+	for i := range services {
+		services[i].Module = m.ResolveModule(services[i].Module)
+	}
+	return client.ConfigureServices(ctx, services)
+}
+
+// Compare returns the reals whose state decided by Monalive differs from their
+// state in the load balancer if the client records the decisions instead of
+// applying them. Otherwise it returns [ErrNotComparable].
+func (m *Balancer) Compare(ctx context.Context) ([]Drift, error) {
+	client, implements := m.client.(Comparer)
+	if !implements {
+		return nil, ErrNotComparable
+	}
+	return client.Compare(ctx)
+}
+
+// Drift returns the reals whose state in the load balancer differs from the
+// state applied by Monalive, as detected by the last state synchronization,
+// along with the time of the synchronization.
+func (m *Balancer) Drift() (drift []Drift, synced time.Time) {
+	return m.reconcile.Drift()
+}
+
+// repairDrift detects the drift of the load balancer state requested at the
+// given time and re-issues the updates of the drifted reals. The reals with
+// pending events are not re-issued, as they are updated anyway.
+func (m *Balancer) repairDrift(state map[string]services, requested time.Time) {
+	drift := m.reconcile.Reconcile(state, requested)
+
+	driftedByModule := make(map[string]int, len(state))
+	for module := range state {
+		driftedByModule[module] = 0
+	}
+	for _, real := range drift {
+		driftedByModule[real.Key.Module]++
+
+		event := &xevent.Event{
+			Type:     xevent.Disable,
+			Balancer: real.Key,
+			New:      real.Desired,
+			Init:     xevent.Status{Enable: real.Actual.Enabled, Weight: real.Actual.Weight},
+		}
+		if real.Desired.Enable {
+			event.Type = xevent.Enable
+		}
+		if m.events.StoreIfAbsent(real.Key, event) {
+			m.log.Warn(
+				"repairing balancer state drift",
+				log.String("module", real.Key.Module),
+				log.String("service", real.Key.Service.String()),
+				log.String("real", real.Key.Real.Addr.String()),
+				log.Bool("enabled", real.Desired.Enable),
+				log.Bool("balancer_enabled", real.Actual.Enabled),
+			)
+			m.metrics.DriftRepairs(real.Key.Module).Inc()
+		}
+	}
+	for module, drifted := range driftedByModule {
+		m.metrics.DriftedReals(module).Set(float64(drifted))
+	}
+}
+
+// Health returns the health of the connection to the load balancer. The second
+// value reports whether the client tracks the health.
+func (m *Balancer) Health() (Health, bool) {
+	reporter, implements := m.client.(HealthReporter)
+	if !implements {
+		return Health{}, false
+	}
+	return reporter.Health(), true
+}
+
+// reportHealth exports the health of the connection to the load balancer.
+func (m *Balancer) reportHealth() {
+	health, known := m.Health()
+	if !known {
+		return
+	}
+	connected := 0.
+	if health.Connected {
+		connected = 1
+	}
+	m.metrics.Connected().Set(connected)
+}
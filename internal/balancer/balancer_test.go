@@ -2,6 +2,7 @@ package balancer
 
 import (
 	"context"
+	"errors"
 	"net/netip"
 	"sync"
 	"testing"
@@ -11,6 +12,8 @@ import (
 	"github.com/stretchr/testify/require"
 	log "go.uber.org/zap"
 
+	"github.com/yanet-platform/monalive/internal/announcer"
+	"github.com/yanet-platform/monalive/internal/monitoring/metrics"
 	"github.com/yanet-platform/monalive/internal/types/key"
 	"github.com/yanet-platform/monalive/internal/types/xevent"
 )
@@ -51,7 +54,7 @@ func defaultKey() key.Balancer {
 // correctly handled and stored in the balancer's events map with the correct
 // new and initial status.
 func TestBalancer_HandleEvent_Basic(t *testing.T) {
-	balancer := New(&Config{}, &mockBalancerClient{}, nil, log.NewNop())
+	balancer := New(&Config{}, &mockBalancerClient{}, nil, &metrics.NopProvider{}, log.NewNop())
 
 	// Define a default key and initial status.
 	key := defaultKey()
@@ -76,7 +79,7 @@ func TestBalancer_HandleEvent_Basic(t *testing.T) {
 // struct to ensure it updates the event status correctly. It verifies that the
 // new status is recorded after multiple updates.
 func TestBalancer_HandleEvent_Update(t *testing.T) {
-	balancer := New(&Config{}, &mockBalancerClient{}, nil, log.NewNop())
+	balancer := New(&Config{}, &mockBalancerClient{}, nil, &metrics.NopProvider{}, log.NewNop())
 
 	// Define a default key and initial status.
 	key := defaultKey()
@@ -118,7 +121,7 @@ func TestBalancer_HandleEvent_Update(t *testing.T) {
 // that the event is correctly removed from the balancer's events map when the
 // status is changed to disablement.
 func TestBalancer_HandleEvent_RemoveEnable(t *testing.T) {
-	balancer := New(&Config{}, &mockBalancerClient{}, nil, log.NewNop())
+	balancer := New(&Config{}, &mockBalancerClient{}, nil, &metrics.NopProvider{}, log.NewNop())
 
 	// Define a default key and initial status.
 	key := defaultKey()
@@ -158,7 +161,7 @@ func TestBalancer_HandleEvent_RemoveEnable(t *testing.T) {
 // that the event is correctly removed from the balancer's events map when the
 // status is updated to disablement.
 func TestBalancer_HandleEvent_RemoveDisable(t *testing.T) {
-	balancer := New(&Config{}, &mockBalancerClient{}, nil, log.NewNop())
+	balancer := New(&Config{}, &mockBalancerClient{}, nil, &metrics.NopProvider{}, log.NewNop())
 
 	// Define a default key and initial status.
 	key := defaultKey()
@@ -197,7 +200,7 @@ func TestBalancer_HandleEvent_RemoveDisable(t *testing.T) {
 // when the key already exists in the balancer state. It verifies that the
 // subscription returns nil, indicating no notification is needed.
 func TestBalancer_LookupSubscription_KeyExists(t *testing.T) {
-	balancer := New(&Config{}, &mockBalancerClientWithStates{}, nil, log.NewNop())
+	balancer := New(&Config{}, &mockBalancerClientWithStates{}, nil, &metrics.NopProvider{}, log.NewNop())
 
 	// [BEGIN] Fill up balancer state.
 	balancerKey := defaultKey()
@@ -236,7 +239,7 @@ func TestBalancer_LookupSubscription_KeyExists(t *testing.T) {
 // when a key is added to the balancer state. It verifies that the subscription
 // receives a notification when the state is updated.
 func TestBalancer_LookupSubscription_KeyAdded(t *testing.T) {
-	balancer := New(&Config{}, &mockBalancerClientWithStates{}, nil, log.NewNop())
+	balancer := New(&Config{}, &mockBalancerClientWithStates{}, nil, &metrics.NopProvider{}, log.NewNop())
 
 	// [BEGIN] Construct balancer state update.
 	balancerKey := defaultKey()
@@ -292,7 +295,7 @@ func TestBalancer_LookupSubscription_KeyAdded(t *testing.T) {
 // method when the context is canceled before the key is added to the balancer
 // state. It verifies that the context cancellation is handled properly.
 func TestBalancer_LookupSubscription_ContextCancel(t *testing.T) {
-	balancer := New(&Config{}, &mockBalancerClientWithStates{}, nil, log.NewNop())
+	balancer := New(&Config{}, &mockBalancerClientWithStates{}, nil, &metrics.NopProvider{}, log.NewNop())
 
 	notExistingKey := defaultKey()
 	notExistingKey.Service.Addr = netip.MustParseAddr("127.0.1.1")
@@ -320,7 +323,7 @@ func TestBalancer_LookupSubscription_ContextCancel(t *testing.T) {
 // verifies that the context remains valid and no errors are returned after the
 // balancer stops.
 func TestBalancer_LookupSubscription_BalancerStopped(t *testing.T) {
-	balancer := New(&Config{}, &mockBalancerClientWithStates{}, nil, log.NewNop())
+	balancer := New(&Config{}, &mockBalancerClientWithStates{}, nil, &metrics.NopProvider{}, log.NewNop())
 
 	notExistingKey := defaultKey()
 	notExistingKey.Service.Addr = netip.MustParseAddr("127.0.1.1")
@@ -353,10 +356,233 @@ func TestBalancer_LookupSubscription_BalancerStopped(t *testing.T) {
 // subscription returns nil, indicating no notifications are available for
 // non-existing keys.
 func TestBalancer_LookupSubscription_WithoutState(t *testing.T) {
-	balancer := New(&Config{}, &mockBalancerClient{}, nil, log.NewNop())
+	balancer := New(&Config{}, &mockBalancerClient{}, nil, &metrics.NopProvider{}, log.NewNop())
 
 	ctx := context.Background()
 	// Test subscription lookup for a key when no state is present.
 	notify := balancer.LookupSubscription(ctx, defaultKey())
 	require.Nil(t, notify)
 }
+
+// batchBalancerClient is a mock implementation of the LoadBalancerClient
+// interface recording the applied batches. The updates of the keys listed in
+// fail are reported as failed.
+type batchBalancerClient struct {
+	LoadBalancerClient
+	batches  [][]RealUpdate
+	fail     map[key.Balancer]bool
+	flushed  int
+	flushErr error
+}
+
+func (m *batchBalancerClient) ApplyReals(_ context.Context, updates []RealUpdate) ([]key.Balancer, error) {
+	m.batches = append(m.batches, updates)
+	var failed []key.Balancer
+	for _, update := range updates {
+		if m.fail[update.Key] {
+			failed = append(failed, update.Key)
+		}
+	}
+	if len(failed) > 0 {
+		return failed, errors.New("failed to apply reals")
+	}
+	return nil, nil
+}
+
+func (m *batchBalancerClient) Flush(context.Context) error {
+	m.flushed++
+	return m.flushErr
+}
+
+// TestBalancer_UpdateBalancer_Batch tests that the events are applied in
+// batches of the configured size and only the failed events are re-queued.
+func TestBalancer_UpdateBalancer_Batch(t *testing.T) {
+	client := &batchBalancerClient{fail: make(map[key.Balancer]bool)}
+	announcer := announcer.New(&announcer.Config{}, nil, &metrics.NopProvider{}, log.NewNop())
+	balancer := New(&Config{MaxBatchSize: 2}, client, announcer, &metrics.NopProvider{}, log.NewNop())
+
+	keys := make([]key.Balancer, 5)
+	for i := range keys {
+		keys[i] = defaultKey()
+		keys[i].Real.Addr = netip.AddrFrom4([4]byte{127, 0, 1, byte(i)})
+		balancer.HandleEvent(&xevent.Event{Balancer: keys[i], New: xevent.Status{Enable: true, Weight: 1}})
+	}
+	client.fail[keys[3]] = true
+
+	balancer.updateBalancer(context.Background())
+	require.Len(t, client.batches, 3)
+	assert.Len(t, client.batches[0], 2)
+	assert.Len(t, client.batches[2], 1)
+	assert.Equal(t, 1, client.flushed)
+
+	// Only the failed event is left to be applied.
+	events := balancer.events.Events()
+	require.Len(t, events, 1)
+	assert.Contains(t, events, keys[3])
+
+	client.fail = nil
+	balancer.updateBalancer(context.Background())
+	require.Len(t, client.batches, 4)
+	assert.Equal(t, []RealUpdate{{Key: keys[3], Enable: true, Weight: 1}}, client.batches[3])
+	assert.Empty(t, balancer.events.Events())
+}
+
+// moduleBalancerClient is a mock implementation of the LoadBalancerClient,
+// Stater and ModuleResolver interfaces reporting the fixed set of modules.
+type moduleBalancerClient struct {
+	LoadBalancerClient
+	modules []string
+}
+
+func (m *moduleBalancerClient) GetState(context.Context) (map[string]services, error) {
+	state := make(map[string]services, len(m.modules))
+	for _, module := range m.modules {
+		state[module] = services{}
+	}
+	return state, nil
+}
+
+func (m *moduleBalancerClient) ResolveModule(module string) string {
+	if module == "" {
+		return "balancer0"
+	}
+	return module
+}
+
+// TestBalancer_ValidateModules tests that the modules are validated against the
+// modules reported by the load balancer, and the empty module is resolved to
+// the default one.
+func TestBalancer_ValidateModules(t *testing.T) {
+	client := &moduleBalancerClient{modules: []string{"balancer0", "balancer1"}}
+	balancer := New(&Config{}, client, nil, &metrics.NopProvider{}, log.NewNop())
+
+	assert.NoError(t, balancer.ValidateModules(context.Background(), []string{"", "balancer1"}))
+	assert.ErrorIs(t, balancer.ValidateModules(context.Background(), []string{"balancer2"}), ErrUnknownModule)
+
+	client.modules = []string{"balancer1"}
+	assert.ErrorIs(t, balancer.ValidateModules(context.Background(), []string{""}), ErrUnknownModule)
+
+	// The empty module and the default one are the same module.
+	assert.Equal(t, balancer.ResolveModule("balancer0"), balancer.ResolveModule(""))
+
+	// The events are stored with the resolved module.
+	balancer.HandleEvent(&xevent.Event{Balancer: defaultKey(), New: xevent.Status{Enable: true}})
+	resolved := defaultKey()
+	resolved.Module = "balancer0"
+	assert.Contains(t, balancer.events.Events(), resolved)
+}
+
+// TestBalancer_RepairDrift tests that the reals whose state in the load
+// balancer differs from the applied one are detected and re-queued, while the
+// reals applied after the state is requested are skipped.
+func TestBalancer_RepairDrift(t *testing.T) {
+	client := &batchBalancerClient{}
+	announcer := announcer.New(&announcer.Config{}, nil, &metrics.NopProvider{}, log.NewNop())
+	balancer := New(&Config{}, client, announcer, &metrics.NopProvider{}, log.NewNop())
+
+	keys := make([]key.Balancer, 3)
+	for i := range keys {
+		keys[i] = defaultKey()
+		keys[i].Module = "balancer0"
+		keys[i].Real.Addr = netip.AddrFrom4([4]byte{127, 0, 1, byte(i)})
+		balancer.HandleEvent(&xevent.Event{Type: xevent.Enable, Balancer: keys[i], New: xevent.Status{Enable: true, Weight: 2}})
+	}
+	balancer.updateBalancer(context.Background())
+	require.Empty(t, balancer.events.Events())
+	requested := time.Now()
+
+	state := map[string]services{
+		"balancer0": {
+			keys[0].Service: reals{
+				// Matches the applied state.
+				keys[0].Real: {Enabled: true, Weight: 2},
+				// Disabled in the load balancer.
+				keys[1].Real: {Enabled: false, Weight: 2},
+				// Has another weight in the load balancer.
+				keys[2].Real: {Enabled: true, Weight: 1},
+			},
+		},
+	}
+	balancer.repairDrift(state, requested)
+
+	drift, synced := balancer.Drift()
+	assert.Equal(t, requested, synced)
+	require.Len(t, drift, 2)
+	assert.Equal(t, keys[1], drift[0].Key)
+	assert.Equal(t, RealState{Enabled: false, Weight: 2}, drift[0].Actual)
+	assert.Equal(t, keys[2], drift[1].Key)
+	assert.Equal(t, xevent.Status{Enable: true, Weight: 2}, drift[1].Desired)
+
+	events := balancer.events.Events()
+	require.Len(t, events, 2)
+	assert.Equal(t, xevent.Status{Enable: true, Weight: 2}, events[keys[1]].New)
+	assert.Equal(t, xevent.Status{Enable: true, Weight: 2}, events[keys[2]].New)
+
+	// The repaired reals are applied again.
+	balancer.updateBalancer(context.Background())
+	assert.Empty(t, balancer.events.Events())
+
+	// The state requested before the repair is applied does not report the
+	// drift, while the later one does.
+	balancer.repairDrift(state, requested)
+	drift, _ = balancer.Drift()
+	assert.Empty(t, drift)
+	assert.Empty(t, balancer.events.Events())
+
+	balancer.repairDrift(state, time.Now())
+	drift, _ = balancer.Drift()
+	assert.Len(t, drift, 2)
+
+	// The reals removed from the configuration are not reconciled anymore.
+	balancer.SetReals([]key.Balancer{keys[0], keys[1]})
+	drift, _ = balancer.Drift()
+	require.Len(t, drift, 1)
+	assert.Equal(t, keys[1], drift[0].Key)
+	balancer.updateBalancer(context.Background())
+	balancer.repairDrift(state, time.Now())
+	drift, _ = balancer.Drift()
+	assert.Len(t, drift, 1)
+}
+
+// TestBalancer_UpdateBalancer_FlushFailed tests that the states of the reals
+// are not considered applied if the changes fail to be flushed.
+func TestBalancer_UpdateBalancer_FlushFailed(t *testing.T) {
+	client := &batchBalancerClient{flushErr: errors.New("failed to flush")}
+	announcer := announcer.New(&announcer.Config{}, nil, &metrics.NopProvider{}, log.NewNop())
+	balancer := New(&Config{}, client, announcer, &metrics.NopProvider{}, log.NewNop())
+
+	key := defaultKey()
+	balancer.HandleEvent(&xevent.Event{Type: xevent.Enable, Balancer: key, New: xevent.Status{Enable: true, Weight: 2}})
+	balancer.updateBalancer(context.Background())
+
+	state := map[string]services{
+		"": {key.Service: reals{key.Real: {Enabled: false}}},
+	}
+	balancer.repairDrift(state, time.Now())
+	drift, _ := balancer.Drift()
+	assert.Empty(t, drift)
+
+	client.flushErr = nil
+	balancer.HandleEvent(&xevent.Event{Type: xevent.Enable, Balancer: key, New: xevent.Status{Enable: true, Weight: 2}})
+	balancer.updateBalancer(context.Background())
+	balancer.repairDrift(state, time.Now())
+	drift, _ = balancer.Drift()
+	assert.Len(t, drift, 1)
+}
+
+// TestBalancer_Freeze tests that the frozen balancer ignores the new events
+// and keeps the events stored before.
+func TestBalancer_Freeze(t *testing.T) {
+	balancer := New(&Config{}, &mockBalancerClient{}, nil, &metrics.NopProvider{}, log.NewNop())
+
+	key := defaultKey()
+	enable := &xevent.Event{Balancer: key, New: xevent.Status{Enable: true, Weight: 1}}
+	balancer.HandleEvent(enable)
+
+	balancer.Freeze()
+	balancer.HandleEvent(&xevent.Event{Balancer: key, Type: xevent.Shutdown, Init: enable.New})
+
+	events := balancer.events.Events()
+	require.Contains(t, events, key)
+	assert.Equal(t, enable.New, events[key].New)
+}
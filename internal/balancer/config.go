@@ -4,18 +4,58 @@ import (
 	"time"
 )
 
+// Backend is the load balancer managed by Monalive.
+type Backend string
+
+const (
+	// BackendYANET manages the reals of the YANET load balancer.
+	BackendYANET Backend = "yanet"
+	// BackendIPVS manages the virtual services of the Linux IPVS.
+	BackendIPVS Backend = "ipvs"
+	// BackendShadow records the decisions of Monalive instead of applying them
+	// and compares them with the live YANET state.
+	BackendShadow Backend = "shadow"
+)
+
 // Config represents the configuration of the balancer.
 type Config struct {
+	// Backend is the load balancer managed by Monalive. Defaults to YANET.
+	Backend Backend `yaml:"backend"`
 	// FlushPeriod is the time interval between applying new events to the load
 	// balancer.
 	FlushPeriod time.Duration `yaml:"flush_period"`
 	// SyncPeriod is the time interval between requesting the load balancer
 	// state.
 	SyncPeriod time.Duration `yaml:"sync_states_period"`
+	// MaxBatchSize is the maximal number of real server updates sent to the
+	// load balancer in a single request.
+	MaxBatchSize int `yaml:"max_batch_size"`
 }
 
+// defaultMaxBatchSize is the maximal batch size used if it is not configured.
+const defaultMaxBatchSize = 1000
+
 // Default sets the default values for the configuration.
 func (m *Config) Default() {
 	m.FlushPeriod = 50 * time.Millisecond
 	m.SyncPeriod = 5 * time.Second
+	m.MaxBatchSize = defaultMaxBatchSize
+	m.Backend = BackendYANET
+}
+
+// GetBackend returns the load balancer managed by Monalive.
+func (m *Config) GetBackend() Backend {
+	if m.Backend == "" {
+		return BackendYANET
+	}
+	return m.Backend
+}
+
+// GetMaxBatchSize returns the maximal number of real server updates sent to the
+// load balancer in a single request.
+func (m *Config) GetMaxBatchSize() int {
+	if m.MaxBatchSize <= 0 {
+		return defaultMaxBatchSize
+	}
+	return m.MaxBatchSize
 }
@@ -0,0 +1,372 @@
+// Package ipvs provides an implementation of the load balancer client managing
+// the virtual services of the Linux IPVS.
+package ipvs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/netip"
+	"strings"
+	"sync"
+	"syscall"
+
+	"github.com/yanet-platform/monalive/internal/balancer"
+	"github.com/yanet-platform/monalive/internal/types/key"
+	"github.com/yanet-platform/monalive/internal/types/port"
+	"github.com/yanet-platform/monalive/internal/types/weight"
+	"github.com/yanet-platform/monalive/pkg/ipvs"
+)
+
+// Module is the only load balancer module of IPVS.
+const Module = "ipvs"
+
+// defaultScheduler is the scheduler of the virtual services that do not set it.
+const defaultScheduler = "wrr"
+
+// ErrUnknownService is returned when the real of the virtual service not
+// configured by the client is enabled.
+var ErrUnknownService = errors.New("unknown virtual service")
+
+// Client manages the virtual services of IPVS and their destinations.
+//
+// The virtual services are created and updated according to the services
+// configuration. The enabled reals are added to the virtual services as the
+// destinations with their weights, and the disabled ones are removed. IPVS
+// applies the changes immediately, so no flush is needed.
+type Client struct {
+	client   *ipvs.Client
+	services map[key.Service]*virtualService // virtual services configured by the client
+	mu       sync.Mutex                      // to protect services
+}
+
+// virtualService is the virtual service configured by the client.
+type virtualService struct {
+	service ipvs.Service
+	// reals maps the configured reals to their destinations without weights.
+	reals map[key.Real]ipvs.Destination
+	// defaultDest is the template of the destinations of the reals missing in
+	// the configuration.
+	defaultDest ipvs.Destination
+}
+
+// NewClient creates a new IPVS client. It fails if the IPVS kernel module is
+// not loaded.
+func NewClient() (*Client, error) {
+	client, err := ipvs.New()
+	if err != nil {
+		return nil, err
+	}
+	return newClient(client), nil
+}
+
+// newClient creates a new IPVS client using the IPVS table client.
+func newClient(client *ipvs.Client) *Client {
+	return &Client{
+		client:   client,
+		services: make(map[key.Service]*virtualService),
+	}
+}
+
+// ResolveModule returns the passed module or the IPVS module if it is empty.
+func (m *Client) ResolveModule(module string) string {
+	if module == "" {
+		return Module
+	}
+	return module
+}
+
+// ConfigureServices creates the virtual services missing in IPVS and updates
+// the scheduler and the flags of the existing ones. The virtual services
+// configured by the previous call and missing in the passed ones are removed,
+// while the virtual services not configured by the client are left intact.
+func (m *Client) ConfigureServices(_ context.Context, services []balancer.VirtualService) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	current, err := m.client.Services()
+	if err != nil {
+		return err
+	}
+	existing := make(map[ipvsKey]ipvs.Service, len(current))
+	for _, service := range current {
+		existing[keyOf(service)] = service
+	}
+
+	var errs []error
+	configured := make(map[key.Service]*virtualService, len(services))
+	for _, config := range services {
+		service, err := newVirtualService(config)
+		if err == nil {
+			err = m.applyService(service.service, existing)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("service %s: %w", config.Key, err))
+			if known, exists := m.services[config.Key]; exists {
+				configured[config.Key] = known
+			}
+			continue
+		}
+		configured[config.Key] = service
+	}
+
+	// Remove the virtual services no longer configured.
+	for serviceKey, service := range m.services {
+		if _, exists := configured[serviceKey]; exists {
+			continue
+		}
+		if _, exists := existing[keyOf(service.service)]; !exists {
+			continue
+		}
+		if err := m.client.RemoveService(service.service); err != nil {
+			errs = append(errs, err)
+			configured[serviceKey] = service
+		}
+	}
+	m.services = configured
+
+	return errors.Join(errs...)
+}
+
+// EnableReal adds the real to the virtual service with the specified weight or
+// updates its weight.
+func (m *Client) EnableReal(_ context.Context, balancerKey key.Balancer, weight weight.Weight) error {
+	return m.applyReal(balancerKey, true, weight)
+}
+
+// DisableReal removes the real from the virtual service.
+func (m *Client) DisableReal(_ context.Context, balancerKey key.Balancer) error {
+	return m.applyReal(balancerKey, false, weight.Omitted)
+}
+
+// ApplyReals applies the updates of the reals one by one. The keys of the
+// failed updates are reported along with the joined errors.
+func (m *Client) ApplyReals(_ context.Context, updates []balancer.RealUpdate) (failed []key.Balancer, err error) {
+	var errs []error
+	for _, update := range updates {
+		if err := m.applyReal(update.Key, update.Enable, update.Weight); err != nil {
+			failed = append(failed, update.Key)
+			errs = append(errs, err)
+		}
+	}
+	return failed, errors.Join(errs...)
+}
+
+// Flush does nothing, as IPVS applies the updates immediately.
+func (m *Client) Flush(context.Context) error {
+	return nil
+}
+
+type (
+	services = map[key.Service]reals
+	reals    = map[key.Real]balancer.RealState
+)
+
+// GetState retrieves the virtual services of IPVS with their destinations as
+// the state of the single IPVS module. The destinations are reported enabled
+// with their weights, and the configured reals missing in IPVS are reported
+// disabled.
+func (m *Client) GetState(context.Context) (map[string]services, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	current, err := m.client.Services()
+	if err != nil {
+		return nil, err
+	}
+
+	state := make(services, len(current))
+	for _, service := range current {
+		serviceKey, ok := fromService(service)
+		if !ok {
+			continue
+		}
+		dests, err := m.client.Destinations(service)
+		if err != nil {
+			return nil, err
+		}
+
+		configured := m.services[serviceKey]
+		// Map the destinations back to the keys of the configured reals,
+		// as the reals without port use the port of the service.
+		realKeys := make(map[netip.AddrPort]key.Real)
+		realsState := make(reals, len(dests))
+		if configured != nil {
+			for realKey, dest := range configured.reals {
+				realKeys[netip.AddrPortFrom(dest.Addr, dest.Port)] = realKey
+				realsState[realKey] = balancer.RealState{}
+			}
+		}
+		for _, dest := range dests {
+			realKey, exists := realKeys[netip.AddrPortFrom(dest.Addr, dest.Port)]
+			if !exists {
+				realKey = key.Real{Addr: dest.Addr, Port: port.Port(dest.Port)}
+			}
+			realsState[realKey] = balancer.RealState{
+				Enabled: true,
+				Weight:  weight.Weight(dest.Weight),
+			}
+		}
+		state[serviceKey] = realsState
+	}
+
+	return map[string]services{Module: state}, nil
+}
+
+// applyService creates the virtual service if it does not exist or updates it
+// if its settings differ. It assumes the mutex is held.
+func (m *Client) applyService(service ipvs.Service, existing map[ipvsKey]ipvs.Service) error {
+	known, exists := existing[keyOf(service)]
+	switch {
+	case !exists:
+		return m.client.NewService(service)
+	case known.Scheduler != service.Scheduler || known.Flags&^ipvs.ServiceHashed != service.Flags:
+		return m.client.UpdateService(service)
+	default:
+		return nil
+	}
+}
+
+// applyReal adds, updates or removes the destination of the real.
+func (m *Client) applyReal(balancerKey key.Balancer, enable bool, weight weight.Weight) error {
+	m.mu.Lock()
+	service, exists := m.services[balancerKey.Service]
+	m.mu.Unlock()
+	if !exists {
+		if !enable {
+			// The virtual service is removed with all its destinations.
+			return nil
+		}
+		return fmt.Errorf("%w: %s", ErrUnknownService, balancerKey.Service)
+	}
+
+	dest, exists := service.reals[balancerKey.Real]
+	if !exists {
+		dest = service.defaultDest
+		dest.Addr = balancerKey.Real.Addr
+		dest.Port = destPort(balancerKey.Real.Port, service.service.Port)
+	}
+	if !enable {
+		return m.client.RemoveDestination(service.service, dest)
+	}
+	dest.Weight = weight.Uint32()
+	return m.client.SetDestination(service.service, dest)
+}
+
+// newVirtualService converts the virtual service configuration.
+func newVirtualService(config balancer.VirtualService) (*virtualService, error) {
+	if config.Module != Module {
+		return nil, fmt.Errorf("%w: %q", balancer.ErrUnknownModule, config.Module)
+	}
+	if config.Key.Port == port.Omitted {
+		return nil, errors.New("virtual port is required")
+	}
+	protocol, err := protocolNumber(config.Key.Proto)
+	if err != nil {
+		return nil, err
+	}
+
+	service := ipvs.Service{
+		Addr:      config.Key.Addr,
+		Port:      uint16(config.Key.Port.Value()),
+		Protocol:  protocol,
+		Scheduler: config.Scheduler,
+	}
+	if service.Scheduler == "" {
+		service.Scheduler = defaultScheduler
+	}
+	if config.OnePacket {
+		service.Flags |= ipvs.ServiceOnePacket
+	}
+
+	defaultDest, err := newDestination(config.ForwardingMethod)
+	if err != nil {
+		return nil, err
+	}
+	reals := make(map[key.Real]ipvs.Destination, len(config.Reals))
+	for _, real := range config.Reals {
+		dest := defaultDest
+		if real.ForwardingMethod != "" {
+			if dest, err = newDestination(real.ForwardingMethod); err != nil {
+				return nil, fmt.Errorf("real %s: %w", real.Key, err)
+			}
+		}
+		dest.Addr = real.Key.Addr
+		dest.Port = destPort(real.Key.Port, service.Port)
+		reals[real.Key] = dest
+	}
+
+	return &virtualService{
+		service:     service,
+		reals:       reals,
+		defaultDest: defaultDest,
+	}, nil
+}
+
+// newDestination creates the template of the destination forwarded with the
+// method: TUN (default), GRE, DR or NAT.
+func newDestination(method string) (ipvs.Destination, error) {
+	switch strings.ToUpper(method) {
+	case "", "TUN":
+		return ipvs.Destination{ForwardMethod: ipvs.ForwardTunnel}, nil
+	case "GRE":
+		return ipvs.Destination{ForwardMethod: ipvs.ForwardTunnel, TunnelType: ipvs.TunnelGRE}, nil
+	case "DR":
+		return ipvs.Destination{ForwardMethod: ipvs.ForwardDirect}, nil
+	case "NAT":
+		return ipvs.Destination{ForwardMethod: ipvs.ForwardMasquerade}, nil
+	default:
+		return ipvs.Destination{}, fmt.Errorf("unsupported forwarding method %q", method)
+	}
+}
+
+// destPort returns the port of the destination. The reals without port use
+// the port of the service.
+func destPort(realPort port.Port, servicePort uint16) uint16 {
+	if realPort == port.Omitted {
+		return servicePort
+	}
+	return uint16(realPort.Value())
+}
+
+// protocolNumber returns the IP protocol number of the protocol.
+func protocolNumber(protocol string) (uint16, error) {
+	switch strings.ToUpper(protocol) {
+	case "TCP":
+		return syscall.IPPROTO_TCP, nil
+	case "UDP":
+		return syscall.IPPROTO_UDP, nil
+	default:
+		return 0, fmt.Errorf("unsupported protocol %q", protocol)
+	}
+}
+
+// ipvsKey identifies the virtual service in IPVS.
+type ipvsKey struct {
+	addr     netip.AddrPort
+	protocol uint16
+}
+
+// keyOf returns the identifier of the virtual service.
+func keyOf(service ipvs.Service) ipvsKey {
+	return ipvsKey{addr: netip.AddrPortFrom(service.Addr, service.Port), protocol: service.Protocol}
+}
+
+// fromService converts the IPVS virtual service to the service key. It reports
+// false for the services of the unsupported protocols.
+func fromService(service ipvs.Service) (key.Service, bool) {
+	var protocol string
+	switch service.Protocol {
+	case syscall.IPPROTO_TCP:
+		protocol = "TCP"
+	case syscall.IPPROTO_UDP:
+		protocol = "UDP"
+	default:
+		return key.Service{}, false
+	}
+	return key.Service{
+		Addr:  service.Addr,
+		Port:  port.Port(service.Port),
+		Proto: protocol,
+	}, true
+}
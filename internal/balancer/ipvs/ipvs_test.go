@@ -0,0 +1,69 @@
+package ipvs
+
+import (
+	"net/netip"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yanet-platform/monalive/internal/balancer"
+	"github.com/yanet-platform/monalive/internal/types/key"
+	"github.com/yanet-platform/monalive/internal/types/port"
+	"github.com/yanet-platform/monalive/pkg/ipvs"
+)
+
+// TestNewVirtualService tests the conversion of the virtual service
+// configuration to the IPVS service and the templates of its destinations.
+func TestNewVirtualService(t *testing.T) {
+	serviceKey := key.Service{Addr: netip.MustParseAddr("10.0.0.1"), Port: 443, Proto: "UDP"}
+	tunReal := key.Real{Addr: netip.MustParseAddr("10.1.0.1"), Port: port.Omitted}
+	natReal := key.Real{Addr: netip.MustParseAddr("10.1.0.2"), Port: 8443}
+
+	service, err := newVirtualService(balancer.VirtualService{
+		Key:       serviceKey,
+		Module:    Module,
+		OnePacket: true,
+		Reals: []balancer.VirtualReal{
+			{Key: tunReal},
+			{Key: natReal, ForwardingMethod: "nat"},
+		},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, ipvs.Service{
+		Addr:      serviceKey.Addr,
+		Port:      443,
+		Protocol:  17,
+		Scheduler: "wrr",
+		Flags:     ipvs.ServiceOnePacket,
+	}, service.service)
+	assert.Equal(t, map[key.Real]ipvs.Destination{
+		tunReal: {Addr: tunReal.Addr, Port: 443, ForwardMethod: ipvs.ForwardTunnel, TunnelType: ipvs.TunnelIPIP},
+		natReal: {Addr: natReal.Addr, Port: 8443, ForwardMethod: ipvs.ForwardMasquerade},
+	}, service.reals)
+}
+
+// TestNewVirtualService_Invalid tests that the virtual services IPVS cannot
+// serve are rejected.
+func TestNewVirtualService_Invalid(t *testing.T) {
+	valid := balancer.VirtualService{
+		Key:    key.Service{Addr: netip.MustParseAddr("10.0.0.1"), Port: 80, Proto: "TCP"},
+		Module: Module,
+	}
+
+	withoutPort := valid
+	withoutPort.Key.Port = port.Omitted
+	_, err := newVirtualService(withoutPort)
+	assert.Error(t, err, "service without port")
+
+	unknownModule := valid
+	unknownModule.Module = "balancer0"
+	_, err = newVirtualService(unknownModule)
+	assert.ErrorIs(t, err, balancer.ErrUnknownModule)
+
+	unknownMethod := valid
+	unknownMethod.ForwardingMethod = "IPIP6"
+	_, err = newVirtualService(unknownMethod)
+	assert.Error(t, err, "unsupported forwarding method")
+}
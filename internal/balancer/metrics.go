@@ -0,0 +1,44 @@
+package balancer
+
+import (
+	"github.com/yanet-platform/monalive/internal/monitoring/metrics"
+)
+
+// Metrics holds the metrics of the balancer.
+type Metrics struct {
+	driftedReals metrics.GaugeVec
+	driftRepairs metrics.CounterVec
+	connected    metrics.Gauge
+}
+
+// NewMetrics creates balancer metrics using the passed provider.
+func NewMetrics(provider metrics.Provider) *Metrics {
+	return &Metrics{
+		driftedReals: provider.GetGaugeVec(
+			"balancer_drifted_reals",
+			[]string{"module"},
+			metrics.WithDescription("number of reals whose state in the load balancer differs from the decided one"),
+		),
+		driftRepairs: provider.GetCounterVec(
+			"balancer_drift_repairs",
+			[]string{"module"},
+			metrics.WithDescription("number of real updates re-issued to repair the load balancer state drift"),
+		),
+		connected: provider.GetGauge(
+			"balancer_connected",
+			metrics.WithDescription("whether the last request to the load balancer succeeded"),
+		),
+	}
+}
+
+func (m *Metrics) DriftedReals(module string) metrics.Gauge {
+	return m.driftedReals.GetMetricWith(metrics.Labels{"module": module})
+}
+
+func (m *Metrics) DriftRepairs(module string) metrics.Counter {
+	return m.driftRepairs.GetMetricWith(metrics.Labels{"module": module})
+}
+
+func (m *Metrics) Connected() metrics.Gauge {
+	return m.connected
+}
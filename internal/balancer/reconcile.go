@@ -0,0 +1,178 @@
+package balancer
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/yanet-platform/monalive/internal/types/key"
+	"github.com/yanet-platform/monalive/internal/types/xevent"
+)
+
+// Drift represents the mismatch between the state of a real server decided by
+// Monalive and its state in the load balancer.
+type Drift struct {
+	Key key.Balancer
+	// Desired is the state of the real server last applied to the load
+	// balancer.
+	Desired xevent.Status
+	// Actual is the state of the real server reported by the load balancer.
+	Actual RealState
+	// Detected is the time the drift is detected first.
+	Detected time.Time
+}
+
+// reconciler keeps the states of the real servers applied to the load balancer
+// and detects the drift of the load balancer state from them.
+type reconciler struct {
+	desired map[key.Balancer]desiredReal // states of the reals applied to the load balancer
+	known   map[key.Balancer]struct{}    // configured reals, nil until the reals are set
+	drift   map[key.Balancer]Drift       // drifted reals detected by the last reconciliation
+	synced  time.Time                    // time of the last reconciliation
+	mu      sync.Mutex                   // to protect concurent access to the maps
+}
+
+// desiredReal is the state of the real server applied to the load balancer.
+type desiredReal struct {
+	status  xevent.Status
+	applied time.Time // the time the status is applied at
+}
+
+// newReconciler creates a new instance of reconciler.
+func newReconciler() *reconciler {
+	return &reconciler{
+		desired: make(map[key.Balancer]desiredReal),
+		drift:   make(map[key.Balancer]Drift),
+	}
+}
+
+// Applied records the states of the real servers applied to the load balancer.
+// The reals no longer configured are not recorded.
+func (m *reconciler) Applied(updates []RealUpdate, now time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, update := range updates {
+		if _, exists := m.known[update.Key]; m.known != nil && !exists {
+			continue
+		}
+		m.desired[update.Key] = desiredReal{
+			status:  xevent.Status{Enable: update.Enable, Weight: update.Weight},
+			applied: now,
+		}
+	}
+}
+
+// SetReals sets the configured real servers. The states of the reals removed
+// from the configuration are forgotten, so they are not reconciled anymore.
+func (m *reconciler) SetReals(reals []key.Balancer) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.known = make(map[key.Balancer]struct{}, len(reals))
+	for _, real := range reals {
+		m.known[real] = struct{}{}
+	}
+	for key := range m.desired {
+		if _, exists := m.known[key]; !exists {
+			delete(m.desired, key)
+		}
+	}
+	for key := range m.drift {
+		if _, exists := m.known[key]; !exists {
+			delete(m.drift, key)
+		}
+	}
+}
+
+// Reconcile compares the load balancer state requested at the given time with
+// the applied states of the real servers and returns the drifted ones. The
+// reals applied after the state is requested are skipped, as the state may
+// not reflect them yet. The reals missing in the load balancer state are not
+// considered as drifted.
+func (m *reconciler) Reconcile(state map[string]services, requested time.Time) []Drift {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	drift := make(map[key.Balancer]Drift)
+	for key, desired := range m.desired {
+		if desired.applied.After(requested) {
+			continue
+		}
+		actual, found := lookupReal(state, key)
+		if !found || matches(desired.status, actual) {
+			continue
+		}
+
+		detected := requested
+		if known, exists := m.drift[key]; exists {
+			detected = known.Detected
+		}
+		drift[key] = Drift{
+			Key:      key,
+			Desired:  desired.status,
+			Actual:   actual,
+			Detected: detected,
+		}
+	}
+	m.drift = drift
+	m.synced = requested
+
+	return sortDrift(drift)
+}
+
+// Diff compares the decided states of the real servers with the load balancer
+// state and returns the differing ones marked as detected at the given time.
+// Unlike the reconciliation, the reals missing in the load balancer state are
+// considered disabled there.
+func Diff(decided map[key.Balancer]xevent.Status, state map[string]services, detected time.Time) []Drift {
+	drift := make(map[key.Balancer]Drift)
+	for key, status := range decided {
+		actual, _ := lookupReal(state, key)
+		if matches(status, actual) {
+			continue
+		}
+		drift[key] = Drift{
+			Key:      key,
+			Desired:  status,
+			Actual:   actual,
+			Detected: detected,
+		}
+	}
+	return sortDrift(drift)
+}
+
+// Drift returns the drifted reals detected by the last reconciliation and the
+// time of the reconciliation.
+func (m *reconciler) Drift() (drift []Drift, synced time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return sortDrift(m.drift), m.synced
+}
+
+// matches reports whether the state of the real server in the load balancer
+// matches the desired one. The weight of the disabled reals is not compared.
+func matches(desired xevent.Status, actual RealState) bool {
+	if desired.Enable != actual.Enabled {
+		return false
+	}
+	return !desired.Enable || desired.Weight == actual.Weight
+}
+
+// sortDrift returns the drifted reals sorted by module, service and real.
+func sortDrift(drift map[key.Balancer]Drift) []Drift {
+	result := make([]Drift, 0, len(drift))
+	for _, real := range drift {
+		result = append(result, real)
+	}
+	slices.SortFunc(result, func(a, b Drift) int {
+		return cmp.Or(
+			strings.Compare(a.Key.Module, b.Key.Module),
+			a.Key.Service.Compare(b.Key.Service),
+			a.Key.Real.Addr.Compare(b.Key.Real.Addr),
+			cmp.Compare(a.Key.Real.Port, b.Key.Real.Port),
+		)
+	})
+	return result
+}
@@ -4,13 +4,20 @@ import (
 	"sync"
 
 	"github.com/yanet-platform/monalive/internal/types/key"
+	"github.com/yanet-platform/monalive/internal/types/weight"
 )
 
 type (
 	services = map[key.Service]reals
-	reals    = map[key.Real]struct{}
+	reals    = map[key.Real]RealState
 )
 
+// RealState represents the state of a real server in the load balancer.
+type RealState struct {
+	Enabled bool
+	Weight  weight.Weight
+}
+
 // State manages the load balancer state and notifies subscribers on any
 // updates.
 type State struct {
@@ -40,21 +47,41 @@ func (m *State) Update(state map[string]services) {
 	m.notification()
 }
 
-// Lookup checks if a given balancer key exists in the current state.
+// Lookup checks if a given balancer key exists in the current state. The key
+// is looked up in its module only, or in all modules if the module of the key
+// is not set.
 func (m *State) Lookup(key key.Balancer) (found bool) {
 	m.stateMu.RLock()
 	defer m.stateMu.RUnlock()
+	_, found = lookupReal(m.state, key)
+	return found
+}
 
-	// Iterate over the services in the state to check if the provided key
-	// exists.
-	for _, services := range m.state {
-		if reals, ok := services[key.Service]; ok {
-			if _, ok := reals[key.Real]; ok {
-				return true
-			}
+// lookupReal returns the state of the real server of the given key. The key is
+// looked up in its module only, or in all modules if the module of the key is
+// not set.
+func lookupReal(state map[string]services, key key.Balancer) (real RealState, found bool) {
+	for module, services := range state {
+		if key.Module != "" && key.Module != module {
+			continue
 		}
+		if real, found := services[key.Service][key.Real]; found {
+			return real, true
+		}
+	}
+	return RealState{}, false
+}
+
+// Modules returns the set of the load balancer modules in the current state.
+func (m *State) Modules() map[string]struct{} {
+	m.stateMu.RLock()
+	defer m.stateMu.RUnlock()
+
+	modules := make(map[string]struct{}, len(m.state))
+	for module := range m.state {
+		modules[module] = struct{}{}
 	}
-	return false
+	return modules
 }
 
 // Subscribe returns a channel that is closed when the state is updated. This
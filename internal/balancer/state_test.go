@@ -110,3 +110,27 @@ func TestState_Subscribe_TwoSubscription(t *testing.T) {
 	// Wait for both goroutines to complete.
 	wg.Wait()
 }
+
+// TestState_Lookup_Module tests that the key with the module set is looked up
+// in its module only.
+func TestState_Lookup_Module(t *testing.T) {
+	state := NewState()
+	balancerKey := defaultKey()
+	state.Update(map[string]services{
+		"balancer0": {},
+		"balancer1": {
+			balancerKey.Service: {balancerKey.Real: {}},
+		},
+	})
+
+	// The key without module is looked up in all modules.
+	assert.True(t, state.Lookup(balancerKey))
+
+	balancerKey.Module = "balancer1"
+	assert.True(t, state.Lookup(balancerKey))
+
+	balancerKey.Module = "balancer0"
+	assert.False(t, state.Lookup(balancerKey))
+
+	assert.Equal(t, map[string]struct{}{"balancer0": {}, "balancer1": {}}, state.Modules())
+}
@@ -1,12 +1,62 @@
 package yanet
 
+import "time"
+
 // Config represents the configuration of YANET load ballancer client.
 type Config struct {
 	// SockPath is the path to the YANET control plane socket.
 	SockPath string `yaml:"control_plane_sock_path"`
+	// Module is the balancer module the reals are configured in unless the
+	// service sets its own module.
+	Module string `yaml:"module"`
+	// Timeout is the timeout of the requests to the control plane. Zero value
+	// means the default timeout.
+	Timeout time.Duration `yaml:"timeout"`
+	// PoolSize is the number of connections to the control plane per method.
+	// The calls are distributed over the connections in round-robin order.
+	PoolSize int `yaml:"pool_size"`
 }
 
+const (
+	// defaultModule is the balancer module used if it is not configured.
+	defaultModule = "balancer0"
+	// defaultTimeout is the timeout of the requests used if it is not
+	// configured.
+	defaultTimeout = 5 * time.Second
+	// defaultPoolSize is the number of connections per method used if it is
+	// not configured.
+	defaultPoolSize = 1
+)
+
 // Default sets the default values for the configuration.
 func (m *Config) Default() {
 	m.SockPath = "/var/run/yanet/control_plane.sock"
+	m.Module = defaultModule
+	m.Timeout = defaultTimeout
+	m.PoolSize = defaultPoolSize
+}
+
+// GetModule returns the default balancer module.
+func (m *Config) GetModule() string {
+	if m.Module == "" {
+		return defaultModule
+	}
+	return m.Module
+}
+
+// GetTimeout returns the timeout of the requests to the control plane.
+func (m *Config) GetTimeout() time.Duration {
+	if m.Timeout <= 0 {
+		return defaultTimeout
+	}
+	return m.Timeout
+}
+
+// GetPoolSize returns the number of connections to the control plane per
+// method.
+func (m *Config) GetPoolSize() int {
+	if m.PoolSize <= 0 {
+		return defaultPoolSize
+	}
+	return m.PoolSize
 }
@@ -6,6 +6,7 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/yanet-platform/monalive/internal/balancer"
 	"github.com/yanet-platform/monalive/internal/types/key"
 	"github.com/yanet-platform/monalive/internal/types/port"
 	"github.com/yanet-platform/monalive/internal/types/weight"
@@ -17,20 +18,35 @@ import (
 // Client wraps a YANET client for interacting with the balancer service.
 type Client struct {
 	client *yanet.Client
+	module string // default balancer module
 }
 
 // NewClient creates a new YANET client instance.
 func NewClient(config *Config) (*Client, error) {
 	// Create a new YANET client with the specified socket path.
-	client, err := yanet.NewClient(yanet.WithControlPlaneSockPath(config.SockPath))
+	opts := []yanet.ClientOption{
+		yanet.WithControlPlaneSockPath(config.SockPath),
+		yanet.WithTimeout(config.GetTimeout()),
+		yanet.WithPoolSize(config.GetPoolSize()),
+	}
+	client, err := yanet.NewClient(opts...)
 	if err != nil {
 		return nil, err
 	}
 	return &Client{
 		client: client,
+		module: config.GetModule(),
 	}, nil
 }
 
+// ResolveModule returns the passed module or the default one if it is empty.
+func (m *Client) ResolveModule(module string) string {
+	if module == "" {
+		return m.module
+	}
+	return module
+}
+
 // EnableReal activates a real server in the YANET load balancer with the
 // specified weight.
 func (m *Client) EnableReal(ctx context.Context, balancerKey key.Balancer, weight weight.Weight) error {
@@ -45,21 +61,59 @@ func (m *Client) DisableReal(ctx context.Context, balancerKey key.Balancer) erro
 	return m.updateReal(ctx, balancerKey, false, weight.Omitted)
 }
 
+// ApplyReals updates the states of the real servers in the YANET load balancer
+// with a single request. The request is applied as a whole, so on failure all
+// keys of the batch are reported as failed.
+func (m *Client) ApplyReals(ctx context.Context, updates []balancer.RealUpdate) (failed []key.Balancer, err error) {
+	if len(updates) == 0 {
+		return nil, nil
+	}
+
+	reals := make([]*yanetpb.BalancerRealRequest_Real, 0, len(updates))
+	for _, update := range updates {
+		reals = append(reals, m.realRequest(update.Key, update.Enable, update.Weight))
+	}
+
+	// Send the request to update the real servers in the load balancer.
+	if _, err := m.client.Real(ctx, &yanetpb.BalancerRealRequest{Reals: reals}); err != nil {
+		failed = make([]key.Balancer, 0, len(updates))
+		for _, update := range updates {
+			failed = append(failed, update.Key)
+		}
+		return failed, fmt.Errorf("failed to process the request: %w", err)
+	}
+	return nil, nil
+}
+
 // Flush applies all cached events.
 func (m *Client) Flush(ctx context.Context) error {
 	// Send a request to apply all cached real servers events.
-	if _, err := m.client.RealFlush(context.Background(), &yanetpb.Empty{}); err != nil {
+	if _, err := m.client.RealFlush(ctx, &yanetpb.Empty{}); err != nil {
 		return fmt.Errorf("failed to process the request: %w", err)
 	}
 	return nil
 }
 
+// Health returns the health of the connection to the YANET control plane.
+func (m *Client) Health() balancer.Health {
+	health := m.client.Health()
+	result := balancer.Health{
+		Connected: health.Connected,
+		Since:     health.Since,
+	}
+	if health.LastError != nil {
+		result.LastError = health.LastError.Error()
+	}
+	return result
+}
+
 type (
 	services = map[key.Service]reals
-	reals    = map[key.Real]struct{}
+	reals    = map[key.Real]balancer.RealState
 )
 
-// GetState retrieves the current state of the YANET load balancer.
+// GetState retrieves the current state of the YANET load balancer, including
+// the enabled flag and the weight of each real server.
 func (m *Client) GetState(ctx context.Context) (map[string]services, error) {
 	// Prepare a request to fetch the current state of the real servers.
 	req := yanetpb.BalancerRealFindRequest{}
@@ -70,10 +124,10 @@ func (m *Client) GetState(ctx context.Context) (map[string]services, error) {
 
 	// Map to hold the state of balancers.
 	state := make(map[string]services, len(resp.Balancers))
-	for _, balancer := range resp.Balancers {
+	for _, moduleState := range resp.Balancers {
 		// Map to hold services for each balancer.
-		servicesState := make(services, len(balancer.Services))
-		for _, service := range balancer.Services {
+		servicesState := make(services, len(moduleState.Services))
+		for _, service := range moduleState.Services {
 			// Convert protobuf service key to internal key.Service type.
 			serviceKey, err := fmtFromProtoService(service.Key)
 			if err != nil {
@@ -88,14 +142,16 @@ func (m *Client) GetState(ctx context.Context) (map[string]services, error) {
 				if err != nil {
 					return nil, err
 				}
-				realsState[realKey] = struct{}{}
+				realsState[realKey] = balancer.RealState{
+					Enabled: real.Enabled,
+					Weight:  weight.Weight(real.Weight),
+				}
 			}
 			servicesState[serviceKey] = realsState
 		}
 
 		// Add the state of services for each balancer module.
-		module := balancer.Module
-		state[module] = servicesState
+		state[moduleState.Module] = servicesState
 	}
 
 	return state, nil
@@ -104,12 +160,27 @@ func (m *Client) GetState(ctx context.Context) (map[string]services, error) {
 // updateReal updates the status and weight of a real server in the YANET load
 // balancer.
 func (m *Client) updateReal(ctx context.Context, balancerKey key.Balancer, enable bool, weight weight.Weight) error {
+	// Pack up request to the required format.
+	reqs := &yanetpb.BalancerRealRequest{
+		Reals: []*yanetpb.BalancerRealRequest_Real{m.realRequest(balancerKey, enable, weight)},
+	}
+
+	// Send the request to update the real server in the load balancer.
+	if _, err := m.client.Real(ctx, reqs); err != nil {
+		return fmt.Errorf("failed to process the request: %w", err)
+	}
+	return nil
+}
+
+// realRequest prepares the request to update the status and weight of a real
+// server.
+func (m *Client) realRequest(balancerKey key.Balancer, enable bool, weight weight.Weight) *yanetpb.BalancerRealRequest_Real {
 	service := balancerKey.Service
 	real := balancerKey.Real
 
 	// Prepare a request to update the real server.
 	req := &yanetpb.BalancerRealRequest_Real{
-		Module:    "balancer0",
+		Module:    m.ResolveModule(balancerKey.Module),
 		VirtualIp: fmtToProtoAddr(service.Addr),
 		Proto:     fmtToProtoProtocol(service.Proto),
 		RealIp:    fmtToProtoAddr(real.Addr),
@@ -127,14 +198,5 @@ func (m *Client) updateReal(ctx context.Context, balancerKey key.Balancer, enabl
 		req.WeightOpt = &yanetpb.BalancerRealRequest_Real_Weight{Weight: weight.Uint32()}
 	}
 
-	// Pack up request to the required format.
-	reqs := &yanetpb.BalancerRealRequest{
-		Reals: []*yanetpb.BalancerRealRequest_Real{req},
-	}
-
-	// Send the request to update the real server in the load balancer.
-	if _, err := m.client.Real(ctx, reqs); err != nil {
-		return fmt.Errorf("failed to process the request: %w", err)
-	}
-	return nil
+	return req
 }
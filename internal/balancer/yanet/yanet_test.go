@@ -0,0 +1,61 @@
+package yanet
+
+import (
+	"context"
+	"net/netip"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yanet-platform/monalive/internal/balancer"
+	"github.com/yanet-platform/monalive/internal/types/key"
+	"github.com/yanet-platform/monalive/internal/types/port"
+	"github.com/yanet-platform/monalive/pkg/yanet/fake"
+
+	yanetpb "github.com/yanet-platform/monalive/gen/yanet"
+)
+
+// TestClient_FakeControlPlane tests that the real updates applied through the
+// client are reported by the state of the fake control plane.
+func TestClient_FakeControlPlane(t *testing.T) {
+	sockPath := filepath.Join(t.TempDir(), "yanet.sock")
+	server, err := fake.Start(sockPath, fake.WithModules("balancer0", "balancer1"))
+	require.NoError(t, err)
+	defer server.Close()
+
+	client, err := NewClient(&Config{SockPath: sockPath})
+	require.NoError(t, err)
+
+	service := key.Service{Addr: netip.MustParseAddr("10.0.0.1"), Port: 443, Proto: "TCP"}
+	enabled := key.Balancer{Service: service, Real: key.Real{Addr: netip.MustParseAddr("10.1.0.1"), Port: port.Omitted}}
+	disabled := key.Balancer{Module: "balancer1", Service: service, Real: key.Real{Addr: netip.MustParseAddr("10.1.0.2"), Port: 8443}}
+
+	ctx := context.Background()
+	failed, err := client.ApplyReals(ctx, []balancer.RealUpdate{
+		{Key: enabled, Enable: true, Weight: 3},
+		{Key: disabled, Enable: false},
+	})
+	require.NoError(t, err)
+	assert.Empty(t, failed)
+	require.NoError(t, client.Flush(ctx))
+
+	real, exists := server.Real(fake.RealKey{
+		Module:      "balancer0",
+		VirtualIP:   service.Addr,
+		VirtualPort: 443,
+		Proto:       yanetpb.NetProto_tcp,
+		RealIP:      enabled.Real.Addr,
+	})
+	require.True(t, exists)
+	assert.Equal(t, fake.RealState{Enabled: true, Weight: 3}, real)
+
+	state, err := client.GetState(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]services{
+		"balancer0": {service: {enabled.Real: {Enabled: true, Weight: 3}}},
+		"balancer1": {service: {disabled.Real: {Enabled: false}}},
+	}, state)
+	assert.True(t, client.Health().Connected)
+}
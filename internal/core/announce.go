@@ -0,0 +1,129 @@
+package core
+
+import (
+	"fmt"
+	"net/netip"
+
+	"github.com/yanet-platform/monalive/internal/announcer"
+	"github.com/yanet-platform/monalive/internal/core/service"
+	"github.com/yanet-platform/monalive/internal/types/key"
+)
+
+// AnnouncePrefixConfig represents the explicitly declared announce prefix
+// aggregating the host prefixes of the services.
+type AnnouncePrefixConfig struct {
+	// Announced prefix.
+	Prefix netip.Prefix `keepalive_pos:"0"`
+	// Announce group of the prefix. If not set, the prefix applies to the
+	// services of any group that explicitly refer to it.
+	AnnounceGroup string `keepalive:"announce_group"`
+	// Policy defines how the states of the member services are combined into
+	// the prefix state: "all", "any", "at_least" or "critical". If not set,
+	// the policy of the announce group is used.
+	Policy string `keepalive:"policy"`
+	// Minimal number of enabled member services for the "at_least" policy.
+	MinServices int `keepalive:"min_services"`
+}
+
+// policy returns the announce policy of the prefix.
+func (m *AnnouncePrefixConfig) policy() announcer.Policy {
+	return announcer.Policy{
+		Mode: announcer.PolicyMode(m.Policy),
+		Min:  m.MinServices,
+	}
+}
+
+// validate checks the declared prefix.
+func (m *AnnouncePrefixConfig) validate() error {
+	if !m.Prefix.IsValid() {
+		return fmt.Errorf("invalid announce prefix")
+	}
+	if m.Prefix.Masked() != m.Prefix {
+		return fmt.Errorf("announce prefix %s has host bits set", m.Prefix)
+	}
+	if m.Policy == "" {
+		// The policy of the announce group is used.
+		return nil
+	}
+	if err := m.policy().Validate(); err != nil {
+		return fmt.Errorf("announce prefix %s: %w", m.Prefix, err)
+	}
+	return nil
+}
+
+// announcePrefixFor returns the declared prefix the service is aggregated
+// into. If the service refers to the prefix explicitly, it is used. Otherwise,
+// the most specific prefix of the service announce group containing its VIP is
+// used. If there are no prefixes declared for the group, the service is
+// announced with its host prefix and nil is returned.
+func (m *Config) announcePrefixFor(cfg *service.Config, declared map[netip.Prefix]*AnnouncePrefixConfig) (*AnnouncePrefixConfig, error) {
+	vip := cfg.Key().Addr
+
+	if cfg.AnnouncePrefix != nil {
+		prefix := cfg.AnnouncePrefix.Masked()
+		if !prefix.Contains(vip) {
+			return nil, fmt.Errorf("service %s is outside of its announce prefix %s", cfg.Key(), prefix)
+		}
+		if known, exists := declared[prefix]; exists {
+			return known, nil
+		}
+		// The prefix is not declared, so the default policy is used.
+		return &AnnouncePrefixConfig{Prefix: prefix, AnnounceGroup: cfg.AnnounceGroup}, nil
+	}
+
+	var (
+		found        *AnnouncePrefixConfig
+		groupDeclare bool
+	)
+	for _, prefix := range m.AnnouncePrefixes {
+		if prefix.AnnounceGroup != cfg.AnnounceGroup {
+			continue
+		}
+		groupDeclare = true
+		if !prefix.Prefix.Contains(vip) {
+			continue
+		}
+		if found == nil || prefix.Prefix.Bits() > found.Prefix.Bits() {
+			found = prefix
+		}
+	}
+	if groupDeclare && found == nil {
+		return nil, fmt.Errorf(
+			"service %s is outside of the announce prefixes declared for group %q",
+			cfg.Key(), cfg.AnnounceGroup,
+		)
+	}
+	return found, nil
+}
+
+// announces returns the mapping of the services to the way they affect the
+// prefix announces. Services without announce group are omitted.
+//
+// The policy declared for the prefix takes precedence over the policy of the
+// announce group set in the announcer configuration.
+func (m *Config) announces() map[key.Service]announcer.ServiceAnnounce {
+	policies := make(map[netip.Prefix]announcer.Policy, len(m.AnnouncePrefixes))
+	for _, prefix := range m.AnnouncePrefixes {
+		if prefix.Policy != "" {
+			policies[prefix.Prefix] = prefix.policy()
+		}
+	}
+	announces := make(map[key.Service]announcer.ServiceAnnounce)
+	for _, cfg := range m.Services {
+		if cfg.AnnounceGroup == "" {
+			continue
+		}
+		announce := announcer.ServiceAnnounce{
+			Group:    cfg.AnnounceGroup,
+			Critical: cfg.AnnounceCritical,
+		}
+		if cfg.AnnouncePrefix != nil {
+			announce.Prefix = *cfg.AnnouncePrefix
+			if policy, exists := policies[announce.Prefix]; exists {
+				announce.Policy = policy
+			}
+		}
+		announces[cfg.Key()] = announce
+	}
+	return announces
+}
@@ -2,6 +2,8 @@
 package check
 
 import (
+	"time"
+
 	"github.com/yanet-platform/monalive/internal/types/weight"
 )
 
@@ -9,6 +11,11 @@ import (
 type Metadata struct {
 	Alive  bool
 	Weight weight.Weight
+	// InvalidWeight indicates that the checked service reported the weight,
+	// but its value could not be parsed.
+	InvalidWeight bool
+	// Latency is the response time of the checked service.
+	Latency time.Duration
 	// Force indicates whether the check result shouls be processed in any
 	// scenario.
 	Force bool
@@ -19,4 +26,5 @@ type Metadata struct {
 func (m *Metadata) SetInactive() {
 	m.Alive = false
 	m.Weight = weight.Omitted
+	m.InvalidWeight = false
 }
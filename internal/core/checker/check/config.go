@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/yanet-platform/monalive/internal/types/port"
+	"github.com/yanet-platform/monalive/internal/types/weight"
 )
 
 // Config represents the full configuration for a health check. It includes URL
@@ -46,6 +47,83 @@ type WeightControl struct {
 	// adjustments. It's a percentage that determines how much the weight should
 	// change based on check results.
 	DynamicWeightCoeff uint `keepalive:"dynamic_weight_coefficient"`
+	// DynamicWeightHeaderName overrides the name of the header (or gRPC
+	// metadata key) carrying the weight. Defaults to "RS-Weight" for HTTP
+	// checks and "X-RS-Weight" for gRPC checks.
+	DynamicWeightHeaderName string `keepalive:"dynamic_weight_header_name"` // optional
+	// DynamicWeightBodyKey overrides the key of the "key=value" pair in the
+	// first line of the response body carrying the weight. Defaults to
+	// "rs_weight".
+	DynamicWeightBodyKey string `keepalive:"dynamic_weight_body_key"` // optional
+	// DynamicWeightJSONField is a dot-separated path to the field of the JSON
+	// response body carrying the weight. If set, the body is parsed as JSON
+	// instead of looking for the "key=value" pair.
+	DynamicWeightJSONField string `keepalive:"dynamic_weight_json_field"` // optional
+	// DynamicWeightMin is the lower bound of the dynamic weight specified as a
+	// percentage of the configured weight of the real.
+	DynamicWeightMin *uint `keepalive:"dynamic_weight_min"` // optional
+	// DynamicWeightMax is the upper bound of the dynamic weight specified as a
+	// percentage of the configured weight of the real.
+	DynamicWeightMax *uint `keepalive:"dynamic_weight_max"` // optional
+	// DynamicWeightSmoothing is the factor of the exponentially weighted moving
+	// average applied to the received weights in range (0, 1]. The lower the
+	// value, the smoother the weight changes. Not set or zero value disables
+	// smoothing.
+	DynamicWeightSmoothing float64 `keepalive:"dynamic_weight_smoothing"` // optional
+}
+
+const (
+	// DefaultHTTPWeightHeader is the default name of the HTTP header carrying
+	// the dynamic weight.
+	DefaultHTTPWeightHeader = "RS-Weight"
+	// DefaultGRPCWeightHeader is the default gRPC metadata key carrying the
+	// dynamic weight.
+	DefaultGRPCWeightHeader = "X-RS-Weight"
+	// DefaultWeightBodyKey is the default key of the "key=value" pair in the
+	// response body carrying the dynamic weight.
+	DefaultWeightBodyKey = "rs_weight"
+)
+
+// GetDynamicWeightHeaderName returns the name of the header carrying the
+// weight, or the given default one if it is not configured.
+func (m *WeightControl) GetDynamicWeightHeaderName(defaultName string) string {
+	if m.DynamicWeightHeaderName == "" {
+		return defaultName
+	}
+	return m.DynamicWeightHeaderName
+}
+
+// GetDynamicWeightBodyKey returns the key of the "key=value" pair in the
+// response body carrying the weight.
+func (m *WeightControl) GetDynamicWeightBodyKey() string {
+	if m.DynamicWeightBodyKey == "" {
+		return DefaultWeightBodyKey
+	}
+	return m.DynamicWeightBodyKey
+}
+
+// GetDynamicWeightSmoothing returns the smoothing factor of the dynamic weight.
+// Zero value means that smoothing is disabled.
+func (m *WeightControl) GetDynamicWeightSmoothing() float64 {
+	if m.DynamicWeightSmoothing <= 0 || m.DynamicWeightSmoothing >= 1 {
+		return 0
+	}
+	return m.DynamicWeightSmoothing
+}
+
+// Clamp limits the weight to the configured bounds calculated relative to the
+// given base weight. Omitted weight is returned as is.
+func (m *WeightControl) Clamp(w, base weight.Weight) weight.Weight {
+	if w < 0 || base < 0 {
+		return w
+	}
+	if m.DynamicWeightMin != nil {
+		w = max(w, weight.Weight(int(base)*int(*m.DynamicWeightMin)/100))
+	}
+	if m.DynamicWeightMax != nil {
+		w = min(w, weight.Weight(int(base)*int(*m.DynamicWeightMax)/100))
+	}
+	return w
 }
 
 // Net contains network configuration for the health check, including IP
@@ -137,7 +137,7 @@ func (m *GRPCCheck) handle(md *Metadata, response *healthpb.HealthCheckResponse,
 	// Update metadata to indicate the connection is alive.
 	md.Alive = true
 	// Update metadata with the weight from response.
-	md.Weight = m.getWeightFrom(header)
+	md.Weight, md.InvalidWeight = m.getWeightFrom(header)
 
 	return nil
 }
@@ -148,22 +148,24 @@ func (m *GRPCCheck) matchStatus(status healthpb.HealthCheckResponse_ServingStatu
 }
 
 // getWeightFrom extracts the weight from the gRPC response metadata based on
-// the configured dynamic weight settings.
-func (m *GRPCCheck) getWeightFrom(header metadata.MD) weight.Weight {
+// the configured dynamic weight settings. The returned flag reports whether the
+// weight is present in the metadata but could not be parsed.
+func (m *GRPCCheck) getWeightFrom(header metadata.MD) (w weight.Weight, invalid bool) {
 	if !m.config.DynamicWeight {
 		// Return omitted if dynamic weight is not enabled.
-		return weight.Omitted
+		return weight.Omitted, false
 	}
 
-	values := header.Get("X-RS-Weight")
-	if len(values) != 1 {
-		// Return omitted if weight header is missing or invalid.
-		return weight.Omitted
+	values := header.Get(m.config.GetDynamicWeightHeaderName(DefaultGRPCWeightHeader))
+	if len(values) == 0 {
+		// Return omitted if weight header is missing.
+		return weight.Omitted, false
+	}
+	if len(values) > 1 {
+		// Ambiguous weight is treated as invalid.
+		return weight.Omitted, true
 	}
 
-	var weight weight.Weight
-	// Attempt to unmarshal weight from header.
-	_ = weight.UnmarshalText([]byte(values[0]))
-
-	return weight
+	w, valid := parseWeight([]byte(values[0]))
+	return w, !valid
 }
@@ -1,7 +1,6 @@
 package check
 
 import (
-	"bytes"
 	"context"
 	"crypto/md5"
 	"crypto/tls"
@@ -197,7 +196,7 @@ func (m *HTTPCheck) handle(md *Metadata, response *http.Response) error {
 	// Update metadata to indicate the connection is alive.
 	md.Alive = true
 	// Update metadata with the weight from response.
-	md.Weight = m.getWeightFrom(response, body)
+	md.Weight, md.InvalidWeight = m.getWeightFrom(response, body)
 
 	return nil
 }
@@ -225,37 +224,33 @@ func (m *HTTPCheck) matchDigest(body []byte) bool {
 }
 
 // getWeightFrom extracts the weight from the response based on the configured
-// dynamic weight settings.
-func (m *HTTPCheck) getWeightFrom(response *http.Response, body []byte) weight.Weight {
+// dynamic weight settings. The returned flag reports whether the weight is
+// present in the response but could not be parsed.
+func (m *HTTPCheck) getWeightFrom(response *http.Response, body []byte) (w weight.Weight, invalid bool) {
 	if !m.config.DynamicWeight {
 		// Return omitted if dynamic weight is not enabled.
-		return weight.Omitted
+		return weight.Omitted, false
 	}
 
-	var weightBuf []byte
 	if m.config.DynamicWeightHeader {
-		weightBuf = []byte(response.Header.Get("RS-Weight"))
+		weightBuf := []byte(response.Header.Get(m.config.GetDynamicWeightHeaderName(DefaultHTTPWeightHeader)))
 		if len(weightBuf) == 0 {
 			// Return omitted if weight header is missing.
-			return weight.Omitted
+			return weight.Omitted, false
 		}
-	} else {
-		// Get only the first line of the body.
-		firstBodyLine, _, _ := bytes.Cut(body, []byte("\n"))
-
-		// Cut the weight prefix from the line if so.
-		var found bool
-		if weightBuf, found = bytes.CutPrefix(firstBodyLine, []byte("rs_weight=")); !found {
-			// Return omitted if weight prefix is not found in body.
-			return weight.Omitted
-		}
-		// Additionally trim spaces.
-		weightBuf = bytes.TrimSpace(weightBuf)
+		w, valid := parseWeight(weightBuf)
+		return w, !valid
 	}
 
-	var weight weight.Weight
-	// Attempt to unmarshal weight from buffer.
-	_ = weight.UnmarshalText(weightBuf)
-
-	return weight
+	var found, valid bool
+	if m.config.DynamicWeightJSONField != "" {
+		w, found, valid = parseJSONWeight(body, m.config.DynamicWeightJSONField)
+	} else {
+		w, found, valid = parseBodyWeight(body, m.config.GetDynamicWeightBodyKey())
+	}
+	if !found {
+		// Return omitted if weight is not found in body.
+		return weight.Omitted, false
+	}
+	return w, !valid
 }
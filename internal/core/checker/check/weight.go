@@ -0,0 +1,71 @@
+package check
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	"github.com/yanet-platform/monalive/internal/types/weight"
+)
+
+// parseWeight parses the textual representation of the weight. The returned
+// flag reports whether the value is a valid non-negative integer.
+func parseWeight(text []byte) (weight.Weight, bool) {
+	w, err := strconv.Atoi(string(bytes.TrimSpace(text)))
+	if err != nil || w < 0 {
+		return weight.Omitted, false
+	}
+	return weight.Weight(w), true
+}
+
+// parseBodyWeight extracts the weight from the first line of the body
+// formatted as "key=value". Found flag reports whether the key is present.
+func parseBodyWeight(body []byte, key string) (w weight.Weight, found, valid bool) {
+	// Get only the first line of the body.
+	firstBodyLine, _, _ := bytes.Cut(body, []byte("\n"))
+
+	// Cut the weight prefix from the line if so.
+	weightBuf, found := bytes.CutPrefix(firstBodyLine, []byte(key+"="))
+	if !found {
+		return weight.Omitted, false, false
+	}
+
+	w, valid = parseWeight(weightBuf)
+	return w, true, valid
+}
+
+// parseJSONWeight extracts the weight from the field of the JSON body
+// specified by the dot-separated path. The field can hold either a number or a
+// string. Found flag reports whether the field is present.
+func parseJSONWeight(body []byte, path string) (w weight.Weight, found, valid bool) {
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		// The body is not a valid JSON, so the weight is considered to be
+		// present but invalid.
+		return weight.Omitted, true, false
+	}
+
+	for _, field := range strings.Split(path, ".") {
+		object, ok := value.(map[string]any)
+		if !ok {
+			return weight.Omitted, false, false
+		}
+		if value, ok = object[field]; !ok {
+			return weight.Omitted, false, false
+		}
+	}
+
+	switch value := value.(type) {
+	case json.Number:
+		w, valid = parseWeight([]byte(value.String()))
+	case string:
+		w, valid = parseWeight([]byte(value))
+	default:
+		w, valid = weight.Omitted, false
+	}
+	return w, true, valid
+}
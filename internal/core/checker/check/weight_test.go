@@ -0,0 +1,60 @@
+package check
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/yanet-platform/monalive/internal/types/weight"
+)
+
+// TestParseBodyWeight tests the extraction of the weight from the first line
+// of the response body.
+func TestParseBodyWeight(t *testing.T) {
+	tests := []struct {
+		body   string
+		key    string
+		weight weight.Weight
+		found  bool
+		valid  bool
+	}{
+		{"rs_weight=10\nother", "rs_weight", 10, true, true},
+		{"weight= 7 ", "weight", 7, true, true},
+		{"rs_weight=abc", "rs_weight", weight.Omitted, true, false},
+		{"rs_weight=-1", "rs_weight", weight.Omitted, true, false},
+		{"other\nrs_weight=10", "rs_weight", weight.Omitted, false, false},
+	}
+
+	for _, tt := range tests {
+		w, found, valid := parseBodyWeight([]byte(tt.body), tt.key)
+		assert.Equal(t, tt.weight, w, tt.body)
+		assert.Equal(t, tt.found, found, tt.body)
+		assert.Equal(t, tt.valid, valid, tt.body)
+	}
+}
+
+// TestParseJSONWeight tests the extraction of the weight from the field of the
+// JSON response body.
+func TestParseJSONWeight(t *testing.T) {
+	tests := []struct {
+		body   string
+		path   string
+		weight weight.Weight
+		found  bool
+		valid  bool
+	}{
+		{`{"weight": 10}`, "weight", 10, true, true},
+		{`{"status": {"weight": "5"}}`, "status.weight", 5, true, true},
+		{`{"status": {"weight": 1.5}}`, "status.weight", weight.Omitted, true, false},
+		{`{"status": {"weight": null}}`, "status.weight", weight.Omitted, true, false},
+		{`{"status": "ok"}`, "status.weight", weight.Omitted, false, false},
+		{`not a json`, "weight", weight.Omitted, true, false},
+	}
+
+	for _, tt := range tests {
+		w, found, valid := parseJSONWeight([]byte(tt.body), tt.path)
+		assert.Equal(t, tt.weight, w, tt.body)
+		assert.Equal(t, tt.found, found, tt.body)
+		assert.Equal(t, tt.valid, valid, tt.body)
+	}
+}
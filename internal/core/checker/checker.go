@@ -25,6 +25,14 @@ type Check interface {
 	Do(ctx context.Context, md *check.Metadata) error
 }
 
+// Limiter interface defines the behavior required to limit the concurrency and
+// the rate of the health checks.
+type Limiter interface {
+	// Acquire blocks until the check is allowed to run. Returned release
+	// function must be called once the check is finished.
+	Acquire(ctx context.Context) (release func(), err error)
+}
+
 // ErrShutdown is an error that indicates a shutdown event has occurred.
 var ErrShutdown = errors.New("shutdown")
 
@@ -37,9 +45,18 @@ type Checker struct {
 	state   State        // current state of the checker
 	stateMu sync.RWMutex // to protect concurent access to the state
 
+	baseWeight     weight.Weight // configured weight of the real the dynamic weight bounds are relative to
+	smoothedWeight float64       // moving average of the received dynamic weights, negative if unset
+
+	latencies        []time.Duration // response times of the recent successful checks
+	reportedLatency  time.Duration   // latency percentile reported to the real last time
+	reportedDegraded bool            // degradation flag reported to the real last time
+
 	handler  xevent.Handler // callback event handler function provided by the parent real
 	eventsWG sync.WaitGroup // to manage goroutines handling events
 
+	limiter Limiter // limits the concurrency and the rate of the checks, optional
+
 	metrics *Metrics
 
 	shutdown *shutdown.Shutdown
@@ -56,10 +73,27 @@ type State struct {
 	// ManualChanged is a flag indicates that configuration of checker has
 	// changed manually.
 	ManualChanged bool
+
+	// Latency is the percentile of the response time over the recent
+	// successful checks.
+	Latency time.Duration
+	// Degraded indicates that the latency exceeds the degradation threshold.
+	Degraded bool
+}
+
+// Option represents a function that configures a Checker instance.
+type Option func(*Checker)
+
+// WithLimiter returns an Option that sets the limiter used to obtain the
+// permission before performing each check.
+func WithLimiter(limiter Limiter) Option {
+	return func(m *Checker) {
+		m.limiter = limiter
+	}
 }
 
 // New creates a new Checker instance.
-func New(config *Config, handler xevent.Handler, weight weight.Weight, forwardingData xnet.ForwardingData, logger *log.Logger) *Checker {
+func New(config *Config, handler xevent.Handler, weight weight.Weight, forwardingData xnet.ForwardingData, logger *log.Logger, opts ...Option) *Checker {
 	checker := &Checker{
 		config:   config,
 		handler:  handler,
@@ -68,6 +102,13 @@ func New(config *Config, handler xevent.Handler, weight weight.Weight, forwardin
 	}
 
 	checker.state.Weight = weight
+	checker.baseWeight = weight
+	checker.smoothedWeight = -1
+
+	// Apply optional configurations.
+	for _, opt := range opts {
+		opt(checker)
+	}
 
 	// These values are used in the logger.
 	var uri, meta string
@@ -164,10 +205,23 @@ func (m *Checker) Run(ctx context.Context) {
 			Weight: currState.Weight,
 		}
 
+		// Wait for the permission to perform the check if the limiter is set.
+		release := func() {}
+		if m.limiter != nil {
+			var err error
+			if release, err = m.limiter.Acquire(ctx); err != nil {
+				// The context is canceled, so the checker is stopping.
+				return err
+			}
+		}
+
 		start := time.Now()
-		// Perform the check operation.
+		// Perform the check operation. The permission covers the network
+		// check only, so it is released before the result is processed.
 		opErr := m.check.Do(ctx, &md)
-		m.metrics.ResponseTime().Observe(time.Since(start).Seconds())
+		md.Latency = time.Since(start)
+		release()
+		m.metrics.ResponseTime().Observe(md.Latency.Seconds())
 
 		// Force check result processing if the configuration has changed
 		// manually.
@@ -199,6 +253,9 @@ func (m *Checker) UpdateWeight(weight weight.Weight) {
 	)
 	m.state.Weight = weight
 	m.state.ManualChanged = true
+	m.baseWeight = weight
+	// Start smoothing over from the new weight.
+	m.smoothedWeight = -1
 }
 
 // State returns the current state of the checker.
@@ -65,17 +65,30 @@ type Key struct {
 	dynamicWeightHeader bool
 	dynamicWeightCoeff  uint
 
+	dynamicWeightHeaderName string
+	dynamicWeightBodyKey    string
+	dynamicWeightJSONField  string
+	dynamicWeightMin        int
+	dynamicWeightMax        int
+	dynamicWeightSmoothing  float64
+
 	delayLoop  time.Duration
 	retries    int
 	retryDelay time.Duration
+
+	latencyWindow     int
+	latencyPercentile float64
+	latencyDegraded   time.Duration
+	latencyFailed     time.Duration
 }
 
 // Config holds the configuration for a checker, including its type and various
 // settings.
 type Config struct {
-	Type        Type
-	CheckConfig `keepalive_nested:"check"`
-	Scheduler   `keepalive_nested:"scheduler"`
+	Type           Type
+	CheckConfig    `keepalive_nested:"check"`
+	Scheduler      `keepalive_nested:"scheduler"`
+	LatencyControl `keepalive_nested:"latency"`
 }
 
 // Default initializes the Config with default values.
@@ -103,6 +116,15 @@ func (m *Config) Key() Key {
 		virtualhost = *m.Virtualhost
 	}
 
+	// Unset bounds are distinguished from the zero ones.
+	dynamicWeightMin, dynamicWeightMax := -1, -1
+	if m.DynamicWeightMin != nil {
+		dynamicWeightMin = int(*m.DynamicWeightMin)
+	}
+	if m.DynamicWeightMax != nil {
+		dynamicWeightMax = int(*m.DynamicWeightMax)
+	}
+
 	return Key{
 		ty: m.Type,
 
@@ -122,9 +144,21 @@ func (m *Config) Key() Key {
 		dynamicWeightHeader: m.DynamicWeightHeader,
 		dynamicWeightCoeff:  m.DynamicWeightCoeff,
 
+		dynamicWeightHeaderName: m.DynamicWeightHeaderName,
+		dynamicWeightBodyKey:    m.DynamicWeightBodyKey,
+		dynamicWeightJSONField:  m.DynamicWeightJSONField,
+		dynamicWeightMin:        dynamicWeightMin,
+		dynamicWeightMax:        dynamicWeightMax,
+		dynamicWeightSmoothing:  m.GetDynamicWeightSmoothing(),
+
 		delayLoop:  m.GetDelayLoop(),
 		retries:    m.GetRetries(),
 		retryDelay: m.GetRetryDelay(),
+
+		latencyWindow:     m.GetLatencyWindow(),
+		latencyPercentile: m.GetLatencyPercentile(),
+		latencyDegraded:   m.GetLatencyDegraded(),
+		latencyFailed:     m.GetLatencyFailed(),
 	}
 }
 
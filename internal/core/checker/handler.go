@@ -2,6 +2,7 @@ package checker
 
 import (
 	"errors"
+	"math"
 	"time"
 
 	log "go.uber.org/zap"
@@ -22,6 +23,11 @@ func (m *Checker) ProcessCheck(md check.Metadata, opErr error) {
 
 	switch md.Alive {
 	case true:
+		// The check succeeded, but the real may respond too slowly.
+		if err := m.observeLatency(md.Latency); err != nil {
+			m.processFail(err)
+			return
+		}
 		// If the check succeeds, opErr will be nil, so no need to pass it to
 		// the handler.
 		m.processSucceed(md)
@@ -47,12 +53,18 @@ func (m *Checker) processSucceed(md check.Metadata) {
 	// Attempt to enable the checker if it was previously disabled.
 	statusChanged := m.enableChecker()
 
+	if md.InvalidWeight {
+		m.metrics.InvalidWeight().Inc()
+	}
+
 	// Recalculate the weight based on the current and new weight values.
-	newWeight := md.Weight.Recalculate(m.state.Weight, m.config.DynamicWeightCoeff)
+	newWeight := m.adjustWeight(md.Weight).Recalculate(m.state.Weight, m.config.DynamicWeightCoeff)
 	weightChanged := m.updateWeight(newWeight)
+	latencyChanged := m.latencyChanged()
 
-	if !statusChanged && !weightChanged && !md.Force {
-		// If neither the status nor the weight changed, no event is triggered.
+	if !statusChanged && !weightChanged && !latencyChanged && !md.Force {
+		// If neither the status, the weight nor the latency changed, no event
+		// is triggered.
 		return
 	}
 
@@ -67,7 +79,10 @@ func (m *Checker) processSucceed(md check.Metadata) {
 		New: xevent.Status{
 			Weight: m.state.Weight,
 		},
+		Latency:  m.state.Latency,
+		Degraded: m.state.Degraded,
 	}
+	m.reportedLatency, m.reportedDegraded = m.state.Latency, m.state.Degraded
 	m.handler(event)
 }
 
@@ -170,6 +185,28 @@ func (m *Checker) failedAttempt(opErr error) (exceeded bool) {
 	return m.state.FailedAttempts > m.config.GetRetries()
 }
 
+// adjustWeight limits the received dynamic weight to the configured bounds and
+// smoothes it with the exponentially weighted moving average of the previously
+// received weights. Omitted weight is returned as is.
+func (m *Checker) adjustWeight(w weight.Weight) weight.Weight {
+	if w < 0 {
+		return w
+	}
+	w = m.config.Clamp(w, m.baseWeight)
+
+	alpha := m.config.GetDynamicWeightSmoothing()
+	if alpha == 0 {
+		return w
+	}
+	if m.smoothedWeight < 0 {
+		// The first received weight is taken as is.
+		m.smoothedWeight = float64(w)
+	} else {
+		m.smoothedWeight = alpha*float64(w) + (1-alpha)*m.smoothedWeight
+	}
+	return weight.Weight(math.Round(m.smoothedWeight))
+}
+
 // updateWeight updates the checker's weight if dynamic weight adjustment is
 // enabled.
 //
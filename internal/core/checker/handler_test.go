@@ -400,3 +400,57 @@ func TestProcessCheck_ShutdownDisabled(t *testing.T) {
 	assert.Equal(t, xevent.Shutdown, event.Type)
 	assert.Equal(t, weight.Omitted, event.New.Weight)
 }
+
+// TestProcessCheck_ClampWeight tests that the received dynamic weight is
+// limited to the configured bounds calculated relative to the initial weight.
+func TestProcessCheck_ClampWeight(t *testing.T) {
+	handler := &testHandler{}
+	initWeight := weight.Weight(10)
+	checker := defaultChecker(handler.Handle, initWeight)
+
+	minBound, maxBound := uint(50), uint(150)
+	checker.config.DynamicWeightCoeff = 0
+	checker.config.DynamicWeightMin = &minBound
+	checker.config.DynamicWeightMax = &maxBound
+
+	tests := []struct {
+		weight         weight.Weight
+		expectedWeight weight.Weight
+	}{
+		{weight.Weight(100), weight.Weight(15)}, // capped at 150% of 10
+		{weight.Weight(1), weight.Weight(5)},    // raised to 50% of 10
+		{weight.Weight(12), weight.Weight(12)},  // within the bounds
+		{weight.Omitted, weight.Weight(12)},     // omitted weight keeps the current one
+	}
+
+	for _, tt := range tests {
+		checker.ProcessCheck(check.Metadata{Alive: true, Weight: tt.weight}, nil)
+		assert.Equal(t, tt.expectedWeight, checker.State().Weight)
+	}
+}
+
+// TestProcessCheck_SmoothWeight tests that the received dynamic weights are
+// smoothed with the exponentially weighted moving average.
+func TestProcessCheck_SmoothWeight(t *testing.T) {
+	handler := &testHandler{}
+	initWeight := weight.Weight(10)
+	checker := defaultChecker(handler.Handle, initWeight)
+
+	checker.config.DynamicWeightCoeff = 0
+	checker.config.DynamicWeightSmoothing = 0.5
+
+	tests := []struct {
+		weight         weight.Weight
+		expectedWeight weight.Weight
+	}{
+		{weight.Weight(10), weight.Weight(10)}, // the first weight is taken as is
+		{weight.Weight(20), weight.Weight(15)}, // 0.5 * 20 + 0.5 * 10
+		{weight.Weight(20), weight.Weight(18)}, // 0.5 * 20 + 0.5 * 15 = 17.5
+		{weight.Weight(0), weight.Weight(9)},   // 0.5 * 0 + 0.5 * 17.5 = 8.75
+	}
+
+	for _, tt := range tests {
+		checker.ProcessCheck(check.Metadata{Alive: true, Weight: tt.weight}, nil)
+		assert.Equal(t, tt.expectedWeight, checker.State().Weight)
+	}
+}
@@ -0,0 +1,147 @@
+package checker
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"time"
+
+	"github.com/yanet-platform/monalive/internal/core/checker/check"
+	"github.com/yanet-platform/monalive/internal/monitoring/metrics"
+)
+
+const (
+	// defaultLatencyWindow is the default number of the recent checks the
+	// latency percentile is calculated over.
+	defaultLatencyWindow = 10
+	// defaultLatencyPercentile is the default percentile of the response time
+	// compared to the thresholds.
+	defaultLatencyPercentile = 90
+	// latencyReportThreshold is the relative change of the latency percentile
+	// that is reported to the real even if the checker status has not
+	// changed.
+	latencyReportThreshold = 0.1
+)
+
+// LatencyControl holds configuration of the latency-based evaluation of the
+// check results.
+type LatencyControl struct {
+	// Number of the recent successful checks the latency percentile is
+	// calculated over. Defaults to 10.
+	LatencyWindow *int `keepalive:"latency_window"` // optional
+	// Percentile of the response time compared to the thresholds. Defaults to
+	// 90.
+	LatencyPercentile *float64 `keepalive:"latency_percentile"` // optional
+	// Threshold in seconds above which the real is considered degraded: it
+	// stays enabled, but its weight is reduced.
+	LatencyDegraded *float64 `keepalive:"latency_degraded"` // optional
+	// Threshold in seconds above which the check is considered failed.
+	LatencyFailed *float64 `keepalive:"latency_failed"` // optional
+}
+
+// GetLatencyWindow returns the number of the recent checks the latency
+// percentile is calculated over.
+func (m *LatencyControl) GetLatencyWindow() int {
+	if m.LatencyWindow == nil || *m.LatencyWindow <= 0 {
+		return defaultLatencyWindow
+	}
+	return *m.LatencyWindow
+}
+
+// GetLatencyPercentile returns the percentile of the response time compared to
+// the thresholds.
+func (m *LatencyControl) GetLatencyPercentile() float64 {
+	if m.LatencyPercentile == nil || *m.LatencyPercentile <= 0 || *m.LatencyPercentile > 100 {
+		return defaultLatencyPercentile
+	}
+	return *m.LatencyPercentile
+}
+
+// GetLatencyDegraded returns the degradation threshold. Zero value means that
+// the threshold is not set.
+func (m *LatencyControl) GetLatencyDegraded() time.Duration {
+	return secondsToDuration(m.LatencyDegraded)
+}
+
+// GetLatencyFailed returns the failure threshold. Zero value means that the
+// threshold is not set.
+func (m *LatencyControl) GetLatencyFailed() time.Duration {
+	return secondsToDuration(m.LatencyFailed)
+}
+
+// secondsToDuration converts the optional number of seconds to
+// [time.Duration]. Not set or non-positive value is converted to zero.
+func secondsToDuration(seconds *float64) time.Duration {
+	if seconds == nil || *seconds <= 0 {
+		return 0
+	}
+	return time.Duration(*seconds * float64(time.Second))
+}
+
+// latencyError is returned when the latency percentile of the real exceeds the
+// failure threshold.
+type latencyError struct {
+	latency   time.Duration
+	threshold time.Duration
+}
+
+// Error implements the error interface.
+func (m latencyError) Error() string {
+	return fmt.Sprintf("latency %s exceeds threshold %s", m.latency, m.threshold)
+}
+
+// Label implements the [check.LabeledError] interface.
+func (m latencyError) Label() metrics.Labels {
+	return metrics.Labels{check.ErrorLabel: "latency"}
+}
+
+// observeLatency adds the response time of the successful check to the window
+// and updates the latency percentile and the degradation flag of the checker.
+// It returns an error if the percentile exceeds the failure threshold.
+func (m *Checker) observeLatency(latency time.Duration) error {
+	if latency <= 0 {
+		// The response time is not measured.
+		return nil
+	}
+
+	m.stateMu.Lock()
+	defer m.stateMu.Unlock()
+
+	window := m.config.GetLatencyWindow()
+	m.latencies = append(m.latencies, latency)
+	if len(m.latencies) > window {
+		m.latencies = m.latencies[len(m.latencies)-window:]
+	}
+
+	m.state.Latency = percentile(m.latencies, m.config.GetLatencyPercentile())
+
+	degraded := m.config.GetLatencyDegraded()
+	m.state.Degraded = degraded > 0 && m.state.Latency > degraded
+
+	if failed := m.config.GetLatencyFailed(); failed > 0 && m.state.Latency > failed {
+		return latencyError{latency: m.state.Latency, threshold: failed}
+	}
+	return nil
+}
+
+// latencyChanged reports whether the latency state of the checker differs
+// enough from the one reported to the real last time.
+func (m *Checker) latencyChanged() bool {
+	if m.state.Degraded != m.reportedDegraded {
+		return true
+	}
+	diff := math.Abs(float64(m.state.Latency - m.reportedLatency))
+	return diff > float64(m.reportedLatency)*latencyReportThreshold
+}
+
+// percentile returns the p-th percentile of the given durations using the
+// nearest-rank method.
+func percentile(durations []time.Duration, p float64) time.Duration {
+	if len(durations) == 0 {
+		return 0
+	}
+	sorted := slices.Clone(durations)
+	slices.Sort(sorted)
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	return sorted[max(rank-1, 0)]
+}
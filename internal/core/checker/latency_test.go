@@ -0,0 +1,82 @@
+package checker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yanet-platform/monalive/internal/core/checker/check"
+	"github.com/yanet-platform/monalive/internal/types/weight"
+	"github.com/yanet-platform/monalive/internal/types/xevent"
+)
+
+// TestPercentile tests the nearest-rank percentile calculation.
+func TestPercentile(t *testing.T) {
+	durations := []time.Duration{5, 1, 4, 2, 3, 10, 9, 8, 7, 6}
+
+	assert.Equal(t, time.Duration(9), percentile(durations, 90))
+	assert.Equal(t, time.Duration(5), percentile(durations, 50))
+	assert.Equal(t, time.Duration(10), percentile(durations, 100))
+	assert.Equal(t, time.Duration(0), percentile(nil, 90))
+}
+
+// TestProcessCheck_LatencyDegraded tests that the checker reports the
+// degradation once the latency percentile exceeds the threshold and recovers
+// once it drops back.
+func TestProcessCheck_LatencyDegraded(t *testing.T) {
+	handler := &testHandler{}
+	checker := defaultChecker(handler.Handle, weight.Weight(1))
+
+	window, degraded := 2, 0.1
+	checker.config.LatencyWindow = &window
+	checker.config.LatencyDegraded = &degraded
+
+	md := check.Metadata{Alive: true, Weight: weight.Omitted, Latency: 10 * time.Millisecond}
+	checker.ProcessCheck(md, nil)
+	require.NotNil(t, handler.Event())
+
+	// The slow response makes the checker degraded.
+	md.Latency = time.Second
+	checker.ProcessCheck(md, nil)
+	event := handler.Event()
+	require.NotNil(t, event)
+	assert.Equal(t, xevent.Enable, event.Type)
+	assert.True(t, event.Degraded)
+	assert.Equal(t, time.Second, event.Latency)
+
+	// The slow response is still in the window.
+	md.Latency = 10 * time.Millisecond
+	checker.ProcessCheck(md, nil)
+	assert.True(t, checker.State().Degraded)
+	require.Nil(t, handler.Event())
+
+	// The window contains only fast responses now.
+	checker.ProcessCheck(md, nil)
+	event = handler.Event()
+	require.NotNil(t, event)
+	assert.False(t, event.Degraded)
+}
+
+// TestProcessCheck_LatencyFailed tests that the check is considered failed
+// once the latency percentile exceeds the failure threshold.
+func TestProcessCheck_LatencyFailed(t *testing.T) {
+	handler := &testHandler{}
+	checker := defaultChecker(handler.Handle, weight.Weight(1))
+
+	failed := 0.5
+	checker.config.LatencyFailed = &failed
+
+	md := check.Metadata{Alive: true, Weight: weight.Omitted, Latency: 10 * time.Millisecond}
+	checker.ProcessCheck(md, nil)
+	require.NotNil(t, handler.Event())
+	assert.True(t, checker.State().Alive)
+
+	md.Latency = time.Second
+	checker.ProcessCheck(md, nil)
+	event := handler.Event()
+	require.NotNil(t, event)
+	assert.Equal(t, xevent.Disable, event.Type)
+	assert.False(t, checker.State().Alive)
+}
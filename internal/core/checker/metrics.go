@@ -11,6 +11,9 @@ type SetMetricFunc func(m *Metrics)
 type Metrics struct {
 	responseTime metrics.Histogram
 	errors       metrics.CounterVec
+	// invalidWeight counts the checks reported the weight that could not be
+	// parsed.
+	invalidWeight metrics.Counter
 
 	isBlocked bool
 	mu        sync.Mutex
@@ -20,6 +23,8 @@ func NewMetrics() *Metrics {
 	return &Metrics{
 		responseTime: &metrics.NopHistogram{},
 		errors:       &metrics.NopCounterVec{},
+
+		invalidWeight: &metrics.NopCounter{},
 	}
 }
 
@@ -31,6 +36,10 @@ func (m *Metrics) Errors() metrics.CounterVec {
 	return m.errors
 }
 
+func (m *Metrics) InvalidWeight() metrics.Counter {
+	return m.invalidWeight
+}
+
 func (m *Metrics) Block() {
 	m.mu.Lock()
 	defer m.mu.Unlock()
@@ -57,3 +66,13 @@ func SetErrorsMetric(counterVec metrics.CounterVec) SetMetricFunc {
 		}
 	}
 }
+
+func SetInvalidWeightMetric(counter metrics.Counter) SetMetricFunc {
+	return func(m *Metrics) {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		if !m.isBlocked {
+			m.invalidWeight = counter
+		}
+	}
+}
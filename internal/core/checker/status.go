@@ -34,6 +34,13 @@ func (m *Checker) Status() *monalivepb.CheckerStatus {
 		DynamicWeightHeader: m.config.DynamicWeightHeader,
 		DynamicWeightCoeff:  uint32(m.config.DynamicWeightCoeff),
 
+		DynamicWeightHeaderName: m.config.DynamicWeightHeaderName,
+		DynamicWeightBodyKey:    m.config.DynamicWeightBodyKey,
+		DynamicWeightJsonField:  m.config.DynamicWeightJSONField,
+		DynamicWeightMin:        dynamicWeightBound(m.config.DynamicWeightMin),
+		DynamicWeightMax:        dynamicWeightBound(m.config.DynamicWeightMax),
+		DynamicWeightSmoothing:  m.config.GetDynamicWeightSmoothing(),
+
 		DelayLoop:  durationpb.New(m.config.GetDelayLoop()),
 		Retries:    uint32(m.config.GetRetries()),
 		RetryDelay: durationpb.New(m.config.GetRetryDelay()),
@@ -41,5 +48,17 @@ func (m *Checker) Status() *monalivepb.CheckerStatus {
 		Alive:          alive,
 		FailedAttempts: uint32(state.FailedAttempts),
 		LastCheckTs:    timestamppb.New(state.Timestamp),
+		Latency:        durationpb.New(state.Latency),
+		Degraded:       state.Degraded,
+	}
+}
+
+// dynamicWeightBound converts the optional dynamic weight bound to its protobuf
+// representation.
+func dynamicWeightBound(bound *uint) *uint32 {
+	if bound == nil {
+		return nil
 	}
+	value := uint32(*bound)
+	return &value
 }
@@ -7,7 +7,9 @@ import (
 	"os"
 	"path/filepath"
 
+	"github.com/yanet-platform/monalive/internal/balancer"
 	"github.com/yanet-platform/monalive/internal/core/service"
+	"github.com/yanet-platform/monalive/internal/types/key"
 	"github.com/yanet-platform/monalive/pkg/keepalived"
 )
 
@@ -42,6 +44,9 @@ func JSONConfigLoader(path string, config *Config) error {
 type Config struct {
 	// List of virtual servers configurations.
 	Services []*service.Config `keepalive:"virtual_server"`
+	// List of explicitly declared announce prefixes aggregating the host
+	// prefixes of the virtual servers.
+	AnnouncePrefixes []*AnnouncePrefixConfig `keepalive:"announce_prefix"`
 }
 
 // Prepare processes the configuration by performing validation, propagating
@@ -54,27 +59,32 @@ func (m *Config) Prepare() error {
 		}
 	}
 	// Validate announce groups.
-	m.validateAnnounceGroups()
-	return nil
+	return m.validateAnnounceGroups()
 }
 
 // Dump serializes the configuration to a JSON file at the specified path. It
 // creates a temporary file to ensure atomic write operations.
 func (m *Config) Dump(path string) error {
-	// Marshal the services configuration to JSON with indentation.
-	jsonCfg, err := json.MarshalIndent(m.Services, "", "  ")
+	return dumpJSON(path, m.Services)
+}
+
+// dumpJSON serializes the value to a JSON file at the specified path. It
+// creates a temporary file to ensure atomic write operations.
+func dumpJSON(path string, value any) error {
+	// Marshal the value to JSON with indentation.
+	data, err := json.MarshalIndent(value, "", "  ")
 	if err != nil {
-		return fmt.Errorf("failed to marshal services config: %w", err)
+		return fmt.Errorf("failed to marshal: %w", err)
 	}
 
-	// Create a temporary file to write the configuration.
+	// Create a temporary file to write the data.
 	tmpFile, err := os.CreateTemp(filepath.Split(path))
 	if err != nil {
 		return fmt.Errorf("failed to create file: %w", err)
 	}
 
-	// Write the JSON configuration to the temporary file.
-	if _, err := tmpFile.Write(jsonCfg); err != nil {
+	// Write the JSON data to the temporary file.
+	if _, err := tmpFile.Write(data); err != nil {
 		return fmt.Errorf("failed to write: %w", err)
 	}
 
@@ -98,18 +108,121 @@ func (m *Config) Dump(path string) error {
 
 // validateAnnounceGroups ensures that each service in the configuration uses
 // the correct announce group for its prefix. It maps prefixes to their
-// respective announce groups and ensures consistency across the services.
-func (m *Config) validateAnnounceGroups() {
+// respective announce groups and ensures consistency across the services: the
+// services of the same host prefix get the group of the first one.
+//
+// If announce prefixes are declared, it also resolves the prefix each service
+// is aggregated into and ensures that every VIP falls inside it. The services
+// of the declared prefix must belong to its group.
+func (m *Config) validateAnnounceGroups() error {
+	declared := make(map[netip.Prefix]*AnnouncePrefixConfig, len(m.AnnouncePrefixes))
+	for _, prefix := range m.AnnouncePrefixes {
+		if err := prefix.validate(); err != nil {
+			return err
+		}
+		if _, exists := declared[prefix.Prefix]; exists {
+			return fmt.Errorf("duplicate announce prefix: %s", prefix.Prefix)
+		}
+		declared[prefix.Prefix] = prefix
+	}
+
 	prefixes := make(map[netip.Prefix]string)
 	for _, service := range m.Services {
 		if service.AnnounceGroup == "" {
 			continue
 		}
 
+		// Resolve the aggregate prefix of the service, if any.
+		announcePrefix, err := m.announcePrefixFor(service, declared)
+		if err != nil {
+			return err
+		}
+
 		prefix := service.Key().Prefix()
-		if _, exists := prefixes[prefix]; !exists {
+		if announcePrefix != nil {
+			prefix = announcePrefix.Prefix
+			service.AnnouncePrefix = &prefix
+			if group := announcePrefix.AnnounceGroup; group != "" && group != service.AnnounceGroup {
+				return fmt.Errorf(
+					"service %s of announce group %q refers to announce prefix %s of group %q",
+					service.Key(), service.AnnounceGroup, prefix, group,
+				)
+			}
+		}
+
+		group, exists := prefixes[prefix]
+		if !exists {
 			prefixes[prefix] = service.AnnounceGroup
+			continue
+		}
+		if group == service.AnnounceGroup {
+			continue
+		}
+		if announcePrefix != nil {
+			// The services of the declared prefix must belong to the same
+			// group.
+			return fmt.Errorf(
+				"service %s of announce group %q shares announce prefix %s with announce group %q",
+				service.Key(), service.AnnounceGroup, prefix, group,
+			)
+		}
+		service.AnnounceGroup = group
+	}
+
+	return nil
+}
+
+// virtualServices returns the virtual services of the load balancer described
+// by the services configuration.
+func (m *Config) virtualServices() []balancer.VirtualService {
+	services := make([]balancer.VirtualService, 0, len(m.Services))
+	for _, service := range m.Services {
+		reals := make([]balancer.VirtualReal, 0, len(service.Reals))
+		for _, real := range service.Reals {
+			reals = append(reals, balancer.VirtualReal{
+				Key:              real.Key(),
+				ForwardingMethod: real.ForwardingMethod,
+			})
+		}
+		services = append(services, balancer.VirtualService{
+			Key:              service.Key(),
+			Module:           service.BalancerModule,
+			Scheduler:        service.LVSSheduler,
+			ForwardingMethod: service.ForwardingMethod,
+			OnePacket:        service.OnePacketScheduler,
+			Reals:            reals,
+		})
+	}
+	return services
+}
+
+// balancerReals returns the keys of the real servers of the services in the
+// load balancer.
+func (m *Config) balancerReals() []key.Balancer {
+	var reals []key.Balancer
+	for _, service := range m.Services {
+		for _, real := range service.Reals {
+			reals = append(reals, key.Balancer{
+				Service: service.Key(),
+				Real:    real.Key(),
+				Module:  service.BalancerModule,
+			})
+		}
+	}
+	return reals
+}
+
+// balancerModules returns the distinct load balancer modules of the services.
+// The empty module stands for the default one.
+func (m *Config) balancerModules() []string {
+	seen := make(map[string]struct{})
+	var modules []string
+	for _, service := range m.Services {
+		if _, exists := seen[service.BalancerModule]; exists {
+			continue
 		}
-		service.AnnounceGroup = prefixes[prefix]
+		seen[service.BalancerModule] = struct{}{}
+		modules = append(modules, service.BalancerModule)
 	}
+	return modules
 }
@@ -0,0 +1,140 @@
+package core
+
+import (
+	"net/netip"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yanet-platform/monalive/internal/announcer"
+	"github.com/yanet-platform/monalive/internal/core/service"
+)
+
+// testService returns a prepared service config with the given VIP and
+// announce group.
+func testService(t *testing.T, vip, group string) *service.Config {
+	cfg := service.DefaultConfig()
+	cfg.VIP = netip.MustParseAddr(vip)
+	cfg.AnnounceGroup = group
+	require.NoError(t, cfg.Prepare())
+	return cfg
+}
+
+// TestValidateAnnounceGroups_GroupPrefix tests that the services of the group
+// are aggregated into the declared prefix of the group containing their VIPs.
+func TestValidateAnnounceGroups_GroupPrefix(t *testing.T) {
+	config := &Config{
+		Services: []*service.Config{
+			testService(t, "2001:db8:10::1", "g-1"),
+			testService(t, "2001:db8:10::2", "g-1"),
+			testService(t, "2001:db8:20::1", "g-2"),
+		},
+		AnnouncePrefixes: []*AnnouncePrefixConfig{
+			{
+				Prefix:        netip.MustParsePrefix("2001:db8:10::/64"),
+				AnnounceGroup: "g-1",
+				Policy:        "at_least",
+				MinServices:   1,
+			},
+		},
+	}
+	require.NoError(t, config.Prepare())
+
+	announces := config.announces()
+	expected := announcer.ServiceAnnounce{
+		Group:  "g-1",
+		Prefix: netip.MustParsePrefix("2001:db8:10::/64"),
+		Policy: announcer.Policy{Mode: announcer.PolicyAtLeast, Min: 1},
+	}
+	assert.Equal(t, expected, announces[config.Services[0].Key()])
+	assert.Equal(t, expected, announces[config.Services[1].Key()])
+	// Group without declared prefixes is announced with host prefixes.
+	assert.Equal(t, announcer.ServiceAnnounce{Group: "g-2"}, announces[config.Services[2].Key()])
+}
+
+// TestValidateAnnounceGroups_OutsidePrefix tests that the VIP outside of the
+// declared prefixes of its group is rejected.
+func TestValidateAnnounceGroups_OutsidePrefix(t *testing.T) {
+	config := &Config{
+		Services: []*service.Config{
+			testService(t, "2001:db8:20::1", "g-1"),
+		},
+		AnnouncePrefixes: []*AnnouncePrefixConfig{
+			{Prefix: netip.MustParsePrefix("2001:db8:10::/64"), AnnounceGroup: "g-1"},
+		},
+	}
+	assert.Error(t, config.Prepare())
+
+	// Explicit service prefix must contain the VIP as well.
+	cfg := testService(t, "2001:db8:20::1", "g-1")
+	prefix := netip.MustParsePrefix("2001:db8:10::/64")
+	cfg.AnnouncePrefix = &prefix
+	config = &Config{Services: []*service.Config{cfg}}
+	assert.Error(t, config.Prepare())
+}
+
+// TestValidateAnnounceGroups_GroupMismatch tests that the services of the same
+// host prefix get the announce group of the first one, while the services of
+// the declared prefix belonging to different announce groups are rejected.
+func TestValidateAnnounceGroups_GroupMismatch(t *testing.T) {
+	// The services share the host prefix.
+	other := testService(t, "2001:db8:10::1", "g-2")
+	other.VPort = 443
+	config := &Config{
+		Services: []*service.Config{
+			testService(t, "2001:db8:10::1", "g-1"),
+			other,
+		},
+	}
+	require.NoError(t, config.Prepare())
+	assert.Equal(t, "g-1", other.AnnounceGroup)
+
+	// The services share the declared prefix.
+	prefix := netip.MustParsePrefix("2001:db8:10::/64")
+	first := testService(t, "2001:db8:10::1", "g-1")
+	first.AnnouncePrefix = &prefix
+	second := testService(t, "2001:db8:10::2", "g-2")
+	second.AnnouncePrefix = &prefix
+	config = &Config{
+		Services:         []*service.Config{first, second},
+		AnnouncePrefixes: []*AnnouncePrefixConfig{{Prefix: prefix}},
+	}
+	assert.ErrorContains(t, config.Prepare(), `announce group "g-2"`)
+
+	// The service refers to the prefix declared for another group.
+	cfg := testService(t, "2001:db8:10::1", "g-1")
+	cfg.AnnouncePrefix = &prefix
+	config = &Config{
+		Services: []*service.Config{cfg},
+		AnnouncePrefixes: []*AnnouncePrefixConfig{
+			{Prefix: prefix, AnnounceGroup: "g-2"},
+		},
+	}
+	assert.ErrorContains(t, config.Prepare(), `group "g-2"`)
+}
+
+// TestAnnounces_PrefixPolicy tests that the policy declared for the prefix is
+// used, and the services of the prefixes without one are left with the default
+// policy of their announce group.
+func TestAnnounces_PrefixPolicy(t *testing.T) {
+	critical := testService(t, "2001:db8:10::1", "g-1")
+	critical.AnnounceCritical = true
+	config := &Config{
+		Services: []*service.Config{
+			critical,
+			testService(t, "2001:db8:20::1", "g-2"),
+		},
+		AnnouncePrefixes: []*AnnouncePrefixConfig{
+			{Prefix: netip.MustParsePrefix("2001:db8:20::/64"), AnnounceGroup: "g-2", Policy: "any"},
+		},
+	}
+	require.NoError(t, config.Prepare())
+
+	announces := config.announces()
+	assert.Equal(t, announcer.ServiceAnnounce{
+		Group:    "g-1",
+		Critical: true,
+	}, announces[config.Services[0].Key()])
+	assert.Equal(t, announcer.Policy{Mode: announcer.PolicyAny}, announces[config.Services[1].Key()].Policy)
+}
@@ -6,11 +6,13 @@ import (
 	"context"
 	"fmt"
 	"sync"
+	"time"
 
 	log "go.uber.org/zap"
 
 	"github.com/yanet-platform/monalive/internal/announcer"
 	"github.com/yanet-platform/monalive/internal/balancer"
+	"github.com/yanet-platform/monalive/internal/core/limiter"
 	"github.com/yanet-platform/monalive/internal/core/service"
 	"github.com/yanet-platform/monalive/internal/monitoring/metrics"
 	"github.com/yanet-platform/monalive/internal/types/key"
@@ -23,9 +25,16 @@ type Core struct {
 	announcer *announcer.Announcer // to reload announcer config to keep it in sync with known virtual servers
 	balancer  *balancer.Balancer   // only to pass it to the new services
 
-	services     map[key.Service]*service.Service // current services mapped by their unique [key.Service]
-	servicesMu   sync.Mutex                       // to protect concurent access to the services map
-	servicesPool *workerpool.Pool
+	checkLimiter *limiter.Limiter // only to pass it to the new services, optional
+
+	services        map[key.Service]*service.Service // current services mapped by their unique [key.Service]
+	virtualServices []balancer.VirtualService        // virtual services of the last accepted configuration
+	servicesMu      sync.Mutex                       // to protect concurent access to the services map
+	servicesPool    *workerpool.Pool
+
+	restoredOverrides map[key.Service]ServiceOverrides // overrides applied to the services once they are created
+	restoredSnapshots map[key.Service]RealSnapshots    // states restored by the reals once they are created
+	snapshotsExpires  time.Time                        // time the restored states are trusted until
 
 	metrics *Metrics
 
@@ -33,9 +42,20 @@ type Core struct {
 	log      *log.Logger
 }
 
+// Option represents a function that configures a Core instance.
+type Option func(*Core)
+
+// WithCheckLimiter returns an Option that sets the process-wide limiter of the
+// health checks.
+func WithCheckLimiter(limiter *limiter.Limiter) Option {
+	return func(m *Core) {
+		m.checkLimiter = limiter
+	}
+}
+
 // New creates a new Core instance.
-func New(announcer *announcer.Announcer, balancer *balancer.Balancer, metrics *metrics.ScopedMetrics, logger *log.Logger) *Core {
-	return &Core{
+func New(announcer *announcer.Announcer, balancer *balancer.Balancer, metrics *metrics.ScopedMetrics, logger *log.Logger, opts ...Option) *Core {
+	core := &Core{
 		announcer: announcer,
 		balancer:  balancer,
 
@@ -47,6 +67,13 @@ func New(announcer *announcer.Announcer, balancer *balancer.Balancer, metrics *m
 		shutdown: shutdown.New(),
 		log:      logger,
 	}
+
+	// Apply optional configurations.
+	for _, opt := range opts {
+		opt(core)
+	}
+
+	return core
 }
 
 // Run starts the Core service.
@@ -62,30 +89,49 @@ func (m *Core) Run(ctx context.Context) {
 
 // Reload updates the Core with a new configuration.
 //
-// It first updates the announcer with the new services' announce groups,
+// It first validates the new services against the announcer and configures the
+// virtual services of the load balancer, which are restored if the reload
+// fails. Then it updates the announcer with the new services' announce groups,
 // ensuring the announcer is in sync with the latest configuration. Then, it
 // either updates existing services or adds new ones based on the new
 // configuration. Finally, it stops services that are no longer present in the
 // new configuration and replaces the old services with the new ones.
-func (m *Core) Reload(config *Config) error {
+func (m *Core) Reload(ctx context.Context, config *Config) error {
+	// Check that the load balancer has the modules of the services. It
+	// requests the load balancer, so it is done before taking the lock.
+	if err := m.balancer.ValidateModules(ctx, config.balancerModules()); err != nil {
+		return fmt.Errorf("failed to validate balancer modules: %w", err)
+	}
+
 	// Lock the services mutex to ensure thread-safe access.
 	m.servicesMu.Lock()
 	defer m.servicesMu.Unlock()
 
+	// Construct mapping of services to their announce groups and prefixes.
+	servicesForAnnouncer := config.announces()
+	// Check that the announcer accepts the new services before the load
+	// balancer is changed.
+	if err := m.announcer.ValidateServices(servicesForAnnouncer); err != nil {
+		return fmt.Errorf("failed to reload announcer: %w", err)
+	}
+
+	// Create or update the virtual services if the load balancer client
+	// manages them.
+	virtualServices := config.virtualServices()
+	if err := m.balancer.ConfigureServices(ctx, virtualServices); err != nil {
+		m.restoreVirtualServices(ctx)
+		return fmt.Errorf("failed to configure balancer services: %w", err)
+	}
+
 	// It is crutial to update the announcer first, as it will immediately
 	// remove announces of the deleted services.
-	//
-	// Construct mapping of services to their announce groups.
-	servicesForAnnouncer := make(map[key.Service]string)
-	for _, cfg := range config.Services {
-		if cfg.AnnounceGroup != "" {
-			servicesForAnnouncer[cfg.Key()] = cfg.AnnounceGroup
-		}
-	}
-	// Reload the announcer with new services.
 	if err := m.announcer.ReloadServices(servicesForAnnouncer); err != nil {
+		m.restoreVirtualServices(ctx)
 		return fmt.Errorf("failed to reload announcer: %w", err)
 	}
+	m.virtualServices = virtualServices
+	// Stop reconciling the reals removed from the configuration.
+	m.balancer.SetReals(config.balancerReals())
 
 	// Prepare a new map to hold the new set of services.
 	// This map will eventually replace the existing services map.
@@ -101,7 +147,16 @@ func (m *Core) Reload(config *Config) error {
 			// Extract the unique [key.Service] for the current service
 			// configuration.
 			key := cfg.Key()
-			switch knownService, exists := m.services[key]; exists {
+			knownService, exists := m.services[key]
+			if exists && m.balancer.ResolveModule(knownService.Module()) != m.balancer.ResolveModule(cfg.BalancerModule) {
+				// The reals of the service move to another load balancer
+				// module, so the service is re-created to disable the reals
+				// in the previous one.
+				knownService.Stop()
+				delete(m.services, key)
+				exists = false
+			}
+			switch exists {
 			case true:
 				// If the service already exists in the current services map, it
 				// means we're updating an existing service with new
@@ -118,7 +173,21 @@ func (m *Core) Reload(config *Config) error {
 				// If the service doesn't exist in the current services map,
 				// it's a new service. Create a new service instance with the
 				// provided configuration.
-				newService := service.New(cfg, m.announcer, m.balancer, m.log)
+				var serviceOpts []service.Option
+				if m.checkLimiter != nil {
+					serviceOpts = append(serviceOpts, service.WithCheckLimiter(m.checkLimiter))
+				}
+				if restored, exists := m.restoredOverrides[key]; exists {
+					// Restore the overrides of the service and its reals.
+					serviceOpts = append(serviceOpts, service.WithOverrides(restored.Service, restored.Reals))
+					delete(m.restoredOverrides, key)
+				}
+				if restored, exists := m.restoredSnapshots[key]; exists {
+					// Restore the states of the reals of the service.
+					serviceOpts = append(serviceOpts, service.WithSnapshots(restored, m.snapshotsExpires))
+					delete(m.restoredSnapshots, key)
+				}
+				newService := service.New(cfg, m.announcer, m.balancer, m.log, serviceOpts...)
 				serviceLabels := key.Labels()
 				newService.SetMetrics(
 					service.SetRealsEnabledMetric(m.metrics.RealsEnabledForService(serviceLabels)),
@@ -126,6 +195,7 @@ func (m *Core) Reload(config *Config) error {
 					service.SetRealsTransitionPeriodMetric(m.metrics.RealsTrasitionPeriodForService(serviceLabels)),
 					service.SetRealsResponseTimeMetric(m.metrics.RealsResponseTimeForService(serviceLabels)),
 					service.SetRealsErrorsMetric(m.metrics.RealsErrorsForService(serviceLabels)),
+					service.SetRealsInvalidWeightMetric(m.metrics.RealsInvalidWeightForService(serviceLabels)),
 				)
 
 				// Add the new service to the new services map.
@@ -147,10 +217,23 @@ func (m *Core) Reload(config *Config) error {
 	// Finally, replace the old services map with the new one that contains the
 	// updated set of services.
 	m.services = newServices
+	// All services are created, so overrides of the services missing in the
+	// config are discarded along with their restored states.
+	m.restoredOverrides = nil
+	m.restoredSnapshots = nil
 
 	return nil
 }
 
+// restoreVirtualServices configures the virtual services of the last accepted
+// configuration back, as the current services keep running after the failed
+// reload. It assumes the services mutex is held.
+func (m *Core) restoreVirtualServices(ctx context.Context) {
+	if err := m.balancer.ConfigureServices(ctx, m.virtualServices); err != nil {
+		m.log.Error("failed to restore balancer services", log.Error(err))
+	}
+}
+
 // Stop initiates a graceful shutdown of the Core and all its services.
 // It ensures that no new services are started, and existing services are
 // stopped. It uses the shutdown mechanism to signal that the Core should no
@@ -0,0 +1,61 @@
+package limiter
+
+import "fmt"
+
+// Scope determines how checks are grouped when per-scope limits are applied.
+type Scope string
+
+const (
+	// GlobalScope disables per-scope limits. Only process-wide limits are
+	// applied.
+	GlobalScope Scope = "global"
+	// ServiceScope applies per-scope limits to each virtual service
+	// separately.
+	ServiceScope Scope = "service"
+	// AnnounceGroupScope applies per-scope limits to each announce group
+	// separately. Services without announce group are limited as separate
+	// scopes.
+	AnnounceGroupScope Scope = "announce_group"
+)
+
+// Config represents the configuration of the health checks limiter.
+type Config struct {
+	// MaxConcurrent is the maximum number of health checks performed
+	// simultaneously across the whole process. Zero value means unlimited.
+	MaxConcurrent int `yaml:"max_concurrent"`
+	// MaxRate is the maximum number of new health checks started per second
+	// across the whole process. Zero value means unlimited.
+	MaxRate float64 `yaml:"max_rate"`
+	// Burst is the number of checks that can be started at once exceeding the
+	// MaxRate. Defaults to MaxRate rounded up if not set.
+	Burst int `yaml:"burst"`
+
+	// Scope determines how checks are grouped to apply per-scope limits.
+	Scope Scope `yaml:"scope"`
+	// MaxConcurrentPerScope is the maximum number of health checks performed
+	// simultaneously within a single scope. Zero value means unlimited.
+	MaxConcurrentPerScope int `yaml:"max_concurrent_per_scope"`
+	// MaxRatePerScope is the maximum number of new health checks started per
+	// second within a single scope. Zero value means unlimited.
+	MaxRatePerScope float64 `yaml:"max_rate_per_scope"`
+}
+
+// Validate checks the configuration.
+func (m *Config) Validate() error {
+	switch m.Scope {
+	case "", GlobalScope, ServiceScope, AnnounceGroupScope:
+	default:
+		return fmt.Errorf("unknown limiter scope: %q", m.Scope)
+	}
+	return nil
+}
+
+// Default sets the default values for the configuration.
+func (m *Config) Default() {
+	m.MaxConcurrent = 0
+	m.MaxRate = 0
+	m.Burst = 0
+	m.Scope = GlobalScope
+	m.MaxConcurrentPerScope = 0
+	m.MaxRatePerScope = 0
+}
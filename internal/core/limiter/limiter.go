@@ -0,0 +1,411 @@
+// Package limiter implements a process-wide limiter of health checks. It bounds
+// the number of checks performed simultaneously and the rate at which new
+// checks are started, optionally per service or announce group, and serves the
+// waiting checks in a round-robin manner so that a single service can not
+// starve the rest.
+package limiter
+
+import (
+	"context"
+	"math"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/yanet-platform/monalive/internal/monitoring/metrics"
+	"github.com/yanet-platform/monalive/internal/types/key"
+)
+
+// Limiter limits the concurrency and the rate of health checks.
+//
+// Each service obtains its own [Queue] to wait for the permission to run a
+// check. Pending queues are served in a round-robin manner: on every pass each
+// queue may start at most one check.
+type Limiter struct {
+	config *Config
+
+	bucket   *bucket                // process-wide rate limit, nil if unlimited
+	inflight int                    // number of checks currently running
+	scopes   map[string]*scopeState // per-scope accounting
+
+	ring  []*Queue    // queues with pending waiters in round-robin order
+	next  int         // index of the queue to be served first on the next pass
+	timer *time.Timer // wakes up the dispatcher when new tokens are available
+
+	mu sync.Mutex // to protect concurent access to the limiter state
+
+	metrics *Metrics
+}
+
+// New creates a new Limiter instance. Returns nil if the config is not set,
+// nil Limiter does not limit anything.
+func New(config *Config, provider metrics.Provider) *Limiter {
+	if config == nil {
+		return nil
+	}
+	return &Limiter{
+		config:  config,
+		bucket:  newBucket(config.MaxRate, config.Burst),
+		scopes:  make(map[string]*scopeState),
+		metrics: NewMetrics(provider),
+	}
+}
+
+// Queue creates a new queue of checks for the given service. The group is the
+// announce group of the service, it is used only if the limiter is configured
+// with [AnnounceGroupScope].
+func (m *Limiter) Queue(service key.Service, group string) *Queue {
+	if m == nil {
+		return nil
+	}
+	queue := &Queue{
+		limiter: m,
+		service: service,
+	}
+	queue.scope = m.scopeFor(service, group)
+	return queue
+}
+
+// scopeFor returns the name of the scope used for per-scope limits. Empty
+// string means that per-scope limits are not applied.
+func (m *Limiter) scopeFor(service key.Service, group string) string {
+	switch m.config.Scope {
+	case ServiceScope:
+		return service.String()
+	case AnnounceGroupScope:
+		if group == "" {
+			return service.String()
+		}
+		return "group:" + group
+	default:
+		return ""
+	}
+}
+
+// dispatch grants permissions to the pending waiters while the limits allow it.
+// It assumes the limiter mutex is already held.
+func (m *Limiter) dispatch() {
+	now := time.Now()
+
+	for progressed := true; progressed && len(m.ring) > 0; {
+		progressed = false
+		for served := len(m.ring); served > 0 && len(m.ring) > 0; served-- {
+			if !m.allowGlobal(now) {
+				// Process-wide limits are exhausted, so keep the cursor on the
+				// current queue to serve it first next time.
+				break
+			}
+
+			idx := m.next % len(m.ring)
+			queue := m.ring[idx]
+
+			if len(queue.waiters) == 0 {
+				// The queue has no more waiters, so remove it from the ring
+				// without advancing the cursor.
+				m.ring = slices.Delete(m.ring, idx, idx+1)
+				m.next = idx
+				queue.inRing = false
+				continue
+			}
+			m.next = idx + 1
+
+			w := queue.waiters[0]
+			if !m.allowScope(w.scope, now) {
+				// The scope of the queue is exhausted, try the next one.
+				continue
+			}
+
+			queue.waiters = queue.waiters[1:]
+			m.grant(w, now)
+			progressed = true
+		}
+	}
+
+	if len(m.ring) == 0 {
+		m.next = 0
+	}
+	m.schedule(now)
+}
+
+// allowGlobal checks whether a new check can be started within the
+// process-wide limits.
+func (m *Limiter) allowGlobal(now time.Time) bool {
+	if m.config.MaxConcurrent > 0 && m.inflight >= m.config.MaxConcurrent {
+		return false
+	}
+	return m.bucket.allow(now)
+}
+
+// allowScope checks whether a new check can be started within the given scope.
+func (m *Limiter) allowScope(scope string, now time.Time) bool {
+	if scope == "" {
+		return true
+	}
+
+	state := m.scope(scope)
+	if m.config.MaxConcurrentPerScope > 0 && state.inflight >= m.config.MaxConcurrentPerScope {
+		return false
+	}
+	return state.bucket.allow(now)
+}
+
+// grant marks the waiter as granted and consumes all the limits.
+func (m *Limiter) grant(w *waiter, now time.Time) {
+	m.inflight++
+	m.bucket.take(now)
+	if w.scope != "" {
+		state := m.scope(w.scope)
+		state.inflight++
+		state.bucket.take(now)
+	}
+
+	m.metrics.Queued().Sub(1)
+	m.metrics.InFlight().Add(1)
+
+	w.granted = true
+	close(w.ready)
+}
+
+// release returns the permission obtained by the waiter back to the limiter.
+func (m *Limiter) release(w *waiter) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.inflight--
+	if w.scope != "" {
+		state := m.scope(w.scope)
+		state.inflight--
+		if state.idle(time.Now()) {
+			// Do not keep the state of unused scopes.
+			delete(m.scopes, w.scope)
+		}
+	}
+	m.metrics.InFlight().Sub(1)
+
+	m.dispatch()
+}
+
+// schedule sets up a timer to resume dispatching if the pending waiters are
+// blocked by the rate limits only.
+func (m *Limiter) schedule(now time.Time) {
+	if len(m.ring) == 0 || m.timer != nil {
+		return
+	}
+
+	wait := m.bucket.wait(now)
+	if m.config.MaxRatePerScope > 0 {
+		// Find the earliest moment when any of the blocked queues can be
+		// served.
+		scopeWait := time.Duration(math.MaxInt64)
+		for _, queue := range m.ring {
+			if len(queue.waiters) == 0 || queue.waiters[0].scope == "" {
+				continue
+			}
+			scopeWait = min(scopeWait, m.scope(queue.waiters[0].scope).bucket.wait(now))
+		}
+		if scopeWait != time.Duration(math.MaxInt64) {
+			wait = max(wait, scopeWait)
+		}
+	}
+
+	if wait <= 0 {
+		// Waiters are blocked by the concurrency limits, they will be served
+		// on release.
+		return
+	}
+
+	m.timer = time.AfterFunc(wait, func() {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		m.timer = nil
+		m.dispatch()
+	})
+}
+
+// scope returns the state of the given scope, creating it if necessary.
+func (m *Limiter) scope(name string) *scopeState {
+	state, exists := m.scopes[name]
+	if !exists {
+		state = &scopeState{
+			bucket: newBucket(m.config.MaxRatePerScope, 0),
+		}
+		m.scopes[name] = state
+	}
+	return state
+}
+
+// Queue is a queue of checks of a single service waiting for the permission to
+// run.
+type Queue struct {
+	limiter *Limiter
+	service key.Service
+	scope   string    // name of the scope to apply per-scope limits
+	waiters []*waiter // checks waiting for the permission in FIFO order
+	inRing  bool      // whether the queue is presented in the limiter ring
+}
+
+// SetGroup updates the announce group of the service the queue belongs to.
+func (m *Queue) SetGroup(group string) {
+	if m == nil {
+		return
+	}
+	m.limiter.mu.Lock()
+	defer m.limiter.mu.Unlock()
+	m.scope = m.limiter.scopeFor(m.service, group)
+}
+
+// Acquire blocks until the check is allowed to run or the context is canceled.
+// On success, returned release function must be called once the check is
+// finished.
+func (m *Queue) Acquire(ctx context.Context) (release func(), err error) {
+	if m == nil {
+		return func() {}, nil
+	}
+	limiter := m.limiter
+
+	limiter.mu.Lock()
+	w := &waiter{
+		ready: make(chan struct{}),
+		scope: m.scope,
+	}
+	m.waiters = append(m.waiters, w)
+	if !m.inRing {
+		limiter.ring = append(limiter.ring, m)
+		m.inRing = true
+	}
+	limiter.metrics.Queued().Add(1)
+	limiter.dispatch()
+	granted := w.granted
+	limiter.mu.Unlock()
+
+	release = w.releaseFunc(limiter)
+	if granted {
+		return release, nil
+	}
+
+	// The check has not been started immediately, so it is throttled.
+	limiter.metrics.Throttled().Inc()
+
+	select {
+	case <-w.ready:
+		return release, nil
+
+	case <-ctx.Done():
+		limiter.mu.Lock()
+		if w.granted {
+			// The permission was granted concurrently with cancellation, so
+			// it must be returned back.
+			limiter.mu.Unlock()
+			release()
+			return nil, ctx.Err()
+		}
+
+		// Remove the waiter from the queue.
+		m.waiters = slices.DeleteFunc(m.waiters, func(other *waiter) bool {
+			return other == w
+		})
+		limiter.metrics.Queued().Sub(1)
+		limiter.mu.Unlock()
+
+		return nil, ctx.Err()
+	}
+}
+
+// waiter represents a single check waiting for the permission to run.
+type waiter struct {
+	ready   chan struct{} // closed when the permission is granted
+	scope   string        // name of the scope the waiter belongs to
+	granted bool
+}
+
+// releaseFunc returns a function that releases the permission only once.
+func (m *waiter) releaseFunc(limiter *Limiter) func() {
+	var once sync.Once
+	return func() {
+		once.Do(func() { limiter.release(m) })
+	}
+}
+
+// scopeState holds the accounting of a single scope.
+type scopeState struct {
+	inflight int
+	bucket   *bucket
+}
+
+// idle reports whether the scope has no running checks and its rate limit is
+// fully restored.
+func (m *scopeState) idle(now time.Time) bool {
+	return m.inflight == 0 && m.bucket.full(now)
+}
+
+// bucket implements the token bucket algorithm. Nil bucket does not limit
+// anything.
+type bucket struct {
+	rate   float64 // tokens added per second
+	burst  float64 // maximum number of tokens
+	tokens float64 // currently available tokens
+	last   time.Time
+}
+
+// newBucket creates a new bucket. Returns nil if the rate is not positive.
+func newBucket(rate float64, burst int) *bucket {
+	if rate <= 0 {
+		return nil
+	}
+	if burst <= 0 {
+		burst = int(math.Ceil(rate))
+	}
+	return &bucket{
+		rate:   rate,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+// refill adds the tokens accumulated since the last refill.
+func (m *bucket) refill(now time.Time) {
+	if elapsed := now.Sub(m.last); elapsed > 0 {
+		m.tokens = min(m.burst, m.tokens+elapsed.Seconds()*m.rate)
+		m.last = now
+	}
+}
+
+// allow reports whether a token is available.
+func (m *bucket) allow(now time.Time) bool {
+	if m == nil {
+		return true
+	}
+	m.refill(now)
+	return m.tokens >= 1
+}
+
+// take consumes a single token.
+func (m *bucket) take(now time.Time) {
+	if m == nil {
+		return
+	}
+	m.refill(now)
+	m.tokens--
+}
+
+// wait returns the duration until a token becomes available.
+func (m *bucket) wait(now time.Time) time.Duration {
+	if m == nil {
+		return 0
+	}
+	m.refill(now)
+	if m.tokens >= 1 {
+		return 0
+	}
+	return time.Duration((1 - m.tokens) / m.rate * float64(time.Second))
+}
+
+// full reports whether the bucket is fully restored.
+func (m *bucket) full(now time.Time) bool {
+	if m == nil {
+		return true
+	}
+	m.refill(now)
+	return m.tokens >= m.burst
+}
@@ -0,0 +1,194 @@
+package limiter
+
+import (
+	"context"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yanet-platform/monalive/internal/monitoring/metrics"
+	"github.com/yanet-platform/monalive/internal/types/key"
+	"github.com/yanet-platform/monalive/internal/types/port"
+)
+
+// testService returns a service key with the given last octet of the virtual
+// IP address.
+func testService(octet byte) key.Service {
+	return key.Service{
+		Addr:  netip.AddrFrom4([4]byte{10, 0, 0, octet}),
+		Port:  port.Port(80),
+		Proto: "tcp",
+	}
+}
+
+// TestLimiter_Nil tests that nil limiter and its queues do not limit anything.
+func TestLimiter_Nil(t *testing.T) {
+	limiter := New(nil, &metrics.NopProvider{})
+	require.Nil(t, limiter)
+
+	queue := limiter.Queue(testService(1), "")
+	require.Nil(t, queue)
+
+	// Acquiring the permission from nil queue must succeed immediately.
+	release, err := queue.Acquire(context.Background())
+	require.NoError(t, err)
+	release()
+}
+
+// TestLimiter_MaxConcurrent tests that the number of simultaneously granted
+// permissions does not exceed the configured limit and the waiting check is
+// granted once a running one is released.
+func TestLimiter_MaxConcurrent(t *testing.T) {
+	limiter := New(&Config{MaxConcurrent: 1}, &metrics.NopProvider{})
+	queue := limiter.Queue(testService(1), "")
+
+	release, err := queue.Acquire(context.Background())
+	require.NoError(t, err)
+
+	// The second check must wait until the first one is released.
+	acquired := make(chan func())
+	go func() {
+		release, err := queue.Acquire(context.Background())
+		assert.NoError(t, err)
+		acquired <- release
+	}()
+
+	select {
+	case <-acquired:
+		t.Fatal("permission granted exceeding the concurrency limit")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	release()
+
+	select {
+	case release := <-acquired:
+		release()
+	case <-time.After(time.Second):
+		t.Fatal("permission was not granted after release")
+	}
+}
+
+// TestLimiter_Cancel tests that the waiting check is removed from the queue
+// when its context is canceled.
+func TestLimiter_Cancel(t *testing.T) {
+	limiter := New(&Config{MaxConcurrent: 1}, &metrics.NopProvider{})
+	queue := limiter.Queue(testService(1), "")
+
+	release, err := queue.Acquire(context.Background())
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = queue.Acquire(ctx)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	release()
+
+	// The canceled waiter must not hold the permission, so the next check is
+	// granted immediately.
+	limiter.mu.Lock()
+	assert.Empty(t, queue.waiters)
+	assert.Equal(t, 0, limiter.inflight)
+	limiter.mu.Unlock()
+}
+
+// TestLimiter_RoundRobin tests that the waiting checks of different services
+// are served in a round-robin manner.
+func TestLimiter_RoundRobin(t *testing.T) {
+	limiter := New(&Config{MaxConcurrent: 1}, &metrics.NopProvider{})
+	queueA := limiter.Queue(testService(1), "")
+	queueB := limiter.Queue(testService(2), "")
+	queueC := limiter.Queue(testService(3), "")
+
+	// Occupy the only permission by the check of service C.
+	release, err := queueC.Acquire(context.Background())
+	require.NoError(t, err)
+
+	order := make(chan string, 3)
+	acquire := func(queue *Queue, name string) {
+		release, err := queue.Acquire(context.Background())
+		assert.NoError(t, err)
+		order <- name
+		release()
+	}
+
+	// Enqueue two checks of service A followed by one check of service B.
+	go acquire(queueA, "a")
+	require.Eventually(t, func() bool { return waiting(limiter, queueA) == 1 }, time.Second, time.Millisecond)
+	go acquire(queueA, "a")
+	require.Eventually(t, func() bool { return waiting(limiter, queueA) == 2 }, time.Second, time.Millisecond)
+	go acquire(queueB, "b")
+	require.Eventually(t, func() bool { return waiting(limiter, queueB) == 1 }, time.Second, time.Millisecond)
+
+	release()
+
+	// Service B must not wait for all the checks of service A.
+	assert.Equal(t, "a", <-order)
+	assert.Equal(t, "b", <-order)
+	assert.Equal(t, "a", <-order)
+}
+
+// TestLimiter_PerScope tests that per-scope concurrency limit is applied to the
+// services of the same announce group only.
+func TestLimiter_PerScope(t *testing.T) {
+	limiter := New(&Config{Scope: AnnounceGroupScope, MaxConcurrentPerScope: 1}, &metrics.NopProvider{})
+	queueA := limiter.Queue(testService(1), "group-1")
+	queueB := limiter.Queue(testService(2), "group-1")
+	queueC := limiter.Queue(testService(3), "group-2")
+
+	releaseA, err := queueA.Acquire(context.Background())
+	require.NoError(t, err)
+
+	// Service of another group is not limited.
+	releaseC, err := queueC.Acquire(context.Background())
+	require.NoError(t, err)
+	releaseC()
+
+	// Service of the same group must wait.
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = queueB.Acquire(ctx)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	releaseA()
+}
+
+// TestLimiter_MaxRate tests that the rate of granted permissions does not
+// exceed the configured limit.
+func TestLimiter_MaxRate(t *testing.T) {
+	limiter := New(&Config{MaxRate: 100, Burst: 1}, &metrics.NopProvider{})
+	queue := limiter.Queue(testService(1), "")
+
+	start := time.Now()
+	for range 5 {
+		release, err := queue.Acquire(context.Background())
+		require.NoError(t, err)
+		release()
+	}
+
+	// The first permission is granted from the burst, the rest four require
+	// at least 10ms each.
+	assert.GreaterOrEqual(t, time.Since(start), 35*time.Millisecond)
+}
+
+// TestConfig_Validate tests that unknown scopes are rejected.
+func TestConfig_Validate(t *testing.T) {
+	for _, scope := range []Scope{"", GlobalScope, ServiceScope, AnnounceGroupScope} {
+		config := &Config{Scope: scope}
+		assert.NoError(t, config.Validate())
+	}
+
+	config := &Config{Scope: "services"}
+	assert.Error(t, config.Validate())
+}
+
+// waiting returns the number of checks waiting in the queue.
+func waiting(limiter *Limiter, queue *Queue) int {
+	limiter.mu.Lock()
+	defer limiter.mu.Unlock()
+	return len(queue.waiters)
+}
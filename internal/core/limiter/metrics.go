@@ -0,0 +1,42 @@
+package limiter
+
+import (
+	"github.com/yanet-platform/monalive/internal/monitoring/metrics"
+)
+
+// Metrics holds the metrics of the health checks limiter.
+type Metrics struct {
+	queued    metrics.Gauge
+	inFlight  metrics.Gauge
+	throttled metrics.Counter
+}
+
+// NewMetrics creates limiter metrics using the passed provider.
+func NewMetrics(provider metrics.Provider) *Metrics {
+	return &Metrics{
+		queued: provider.GetGauge(
+			"checks_queued",
+			metrics.WithDescription("number of checks waiting for the limiter permission"),
+		),
+		inFlight: provider.GetGauge(
+			"checks_in_flight",
+			metrics.WithDescription("number of checks currently running"),
+		),
+		throttled: provider.GetCounter(
+			"checks_throttled",
+			metrics.WithDescription("number of checks delayed by the limiter"),
+		),
+	}
+}
+
+func (m *Metrics) Queued() metrics.Gauge {
+	return m.queued
+}
+
+func (m *Metrics) InFlight() metrics.Gauge {
+	return m.inFlight
+}
+
+func (m *Metrics) Throttled() metrics.Counter {
+	return m.throttled
+}
@@ -2,17 +2,26 @@ package core
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net/netip"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	log "go.uber.org/zap"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
 	"google.golang.org/protobuf/types/known/timestamppb"
 
 	monalivepb "github.com/yanet-platform/monalive/gen/manager"
+	"github.com/yanet-platform/monalive/internal/balancer"
 	"github.com/yanet-platform/monalive/internal/core/service"
 	"github.com/yanet-platform/monalive/internal/monitoring/metrics"
+	"github.com/yanet-platform/monalive/internal/types/key"
+	"github.com/yanet-platform/monalive/internal/types/override"
+	"github.com/yanet-platform/monalive/internal/types/port"
 	"github.com/yanet-platform/monalive/internal/types/requestid"
 )
 
@@ -34,6 +43,29 @@ type ServicesConfig struct {
 type ManagerConfig struct {
 	// Embedded ServicesConfig for configuration.
 	Services ServicesConfig `yaml:"services_config"`
+	// Path to the file where the manual overrides are persisted. If not set,
+	// overrides do not survive restarts.
+	OverridesPath string `yaml:"overrides_path"`
+	// Path to the file where the states of the reals are persisted on
+	// shutdown. If set, the reals resume with their last known states after
+	// the restart instead of waiting for the first check.
+	StatesPath string `yaml:"states_path"`
+	// StatesTrustPeriod is the time since the states are persisted during which
+	// they are trusted. The older states are not restored, and the restored
+	// states of the reals not checked within the period are discarded.
+	StatesTrustPeriod time.Duration `yaml:"states_trust_period"`
+}
+
+// defaultStatesTrustPeriod is the trust period of the persisted states used if
+// it is not configured.
+const defaultStatesTrustPeriod = time.Minute
+
+// GetStatesTrustPeriod returns the time the persisted states are trusted.
+func (m *ManagerConfig) GetStatesTrustPeriod() time.Duration {
+	if m.StatesTrustPeriod <= 0 {
+		return defaultStatesTrustPeriod
+	}
+	return m.StatesTrustPeriod
 }
 
 // Manager is a wrapper around the Core to facilitate external communication.
@@ -43,8 +75,13 @@ type Manager struct {
 	core     *Core        // core instance that managing all health checking logic
 	loader   ConfigLoader // this function is used to load the services configuration
 	updateTS time.Time    // last configuration update timestamp
-	metrics  metrics.Provider
-	logger   *log.Logger
+
+	overridesMu sync.Mutex // to serialize the overrides file updates
+
+	keepState atomic.Bool // whether the state is kept on shutdown
+
+	metrics metrics.Provider
+	logger  *log.Logger
 }
 
 // NewManager creates a new Manager instance. It selects the appropriate
@@ -60,6 +97,24 @@ func NewManager(config *ManagerConfig, core *Core, metrics metrics.Provider, log
 		return nil, fmt.Errorf("unknown services configuration format: %s", format)
 	}
 
+	// Restore the overrides persisted before the restart.
+	if config.OverridesPath != "" {
+		overrides, err := loadOverrides(config.OverridesPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load overrides: %w", err)
+		}
+		core.RestoreOverrides(overrides)
+	}
+
+	// Restore the states of the reals persisted before the restart.
+	if config.StatesPath != "" {
+		snapshots, expires, err := loadSnapshots(config.StatesPath, config.GetStatesTrustPeriod())
+		if err != nil {
+			return nil, fmt.Errorf("failed to load real states: %w", err)
+		}
+		core.RestoreSnapshots(snapshots, expires)
+	}
+
 	return &Manager{
 		config:  config,
 		core:    core,
@@ -93,7 +148,7 @@ func (m *Manager) Reload(ctx context.Context, _ *monalivepb.ReloadRequest) (*mon
 		return nil, status.Error(codes.Internal, err.Error())
 	}
 
-	if err := m.core.Reload(config); err != nil {
+	if err := m.core.Reload(ctx, config); err != nil {
 		logger.Error("failed to process reload", log.Error(err))
 		return nil, status.Error(codes.Internal, fmt.Sprintf("failed to process reload: %v", err))
 	}
@@ -105,9 +160,88 @@ func (m *Manager) Reload(ctx context.Context, _ *monalivepb.ReloadRequest) (*mon
 		return nil, status.Error(codes.Internal, fmt.Sprintf("failed to dump services config: %v", err))
 	}
 
+	// Overrides of the removed services and reals are discarded, so the
+	// persisted overrides must be updated.
+	if err := m.dumpOverrides(); err != nil {
+		logger.Error("failed to dump overrides", log.Error(err))
+		return nil, status.Error(codes.Internal, fmt.Sprintf("failed to dump overrides: %v", err))
+	}
+
 	return &monalivepb.ReloadResponse{}, nil
 }
 
+// SetServiceOverride handles the RPC method to set the manual override of the
+// service. The override is persisted if the overrides path is configured.
+//
+// Implements the SetServiceOverride method defined in monalivepb.
+func (m *Manager) SetServiceOverride(ctx context.Context, req *monalivepb.SetServiceOverrideRequest) (*monalivepb.SetOverrideResponse, error) {
+	reqID, _ := requestid.FromContext(ctx)
+	logger := m.logger.With(log.String("request_id", string(reqID)))
+
+	serviceKey, err := serviceKeyFromRequest(req.GetVip(), req.Port, req.GetProtocol())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	newOverride := overrideFromRequest(req.GetMode(), req.GetTtl(), req.GetReason())
+
+	logger.Info(
+		"setting service override",
+		log.String("service", serviceKey.String()),
+		log.String("mode", string(newOverride.Mode)),
+		log.String("reason", newOverride.Reason),
+	)
+
+	if err := m.core.SetServiceOverride(serviceKey, newOverride); err != nil {
+		return nil, overrideError(err)
+	}
+
+	if err := m.dumpOverrides(); err != nil {
+		logger.Error("failed to dump overrides", log.Error(err))
+		return nil, status.Error(codes.Internal, fmt.Sprintf("failed to dump overrides: %v", err))
+	}
+
+	return &monalivepb.SetOverrideResponse{}, nil
+}
+
+// SetRealOverride handles the RPC method to set the manual override of the
+// real. The override is persisted if the overrides path is configured.
+//
+// Implements the SetRealOverride method defined in monalivepb.
+func (m *Manager) SetRealOverride(ctx context.Context, req *monalivepb.SetRealOverrideRequest) (*monalivepb.SetOverrideResponse, error) {
+	reqID, _ := requestid.FromContext(ctx)
+	logger := m.logger.With(log.String("request_id", string(reqID)))
+
+	serviceKey, err := serviceKeyFromRequest(req.GetVip(), req.Port, req.GetProtocol())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	realKey, err := realKeyFromRequest(req.GetRealIp(), req.RealPort)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	newOverride := overrideFromRequest(req.GetMode(), req.GetTtl(), req.GetReason())
+
+	logger.Info(
+		"setting real override",
+		log.String("service", serviceKey.String()),
+		log.String("real_ip", realKey.Addr.String()),
+		log.String("real_port", realKey.Port.String()),
+		log.String("mode", string(newOverride.Mode)),
+		log.String("reason", newOverride.Reason),
+	)
+
+	if err := m.core.SetRealOverride(serviceKey, realKey, newOverride); err != nil {
+		return nil, overrideError(err)
+	}
+
+	if err := m.dumpOverrides(); err != nil {
+		logger.Error("failed to dump overrides", log.Error(err))
+		return nil, status.Error(codes.Internal, fmt.Sprintf("failed to dump overrides: %v", err))
+	}
+
+	return &monalivepb.SetOverrideResponse{}, nil
+}
+
 // GetStatus handles the RPC method to retrieve the current status of the
 // service. It implements the GetStatus method defined in monalivepb. It returns
 // a [monalivepb.GetStatusResponse] message containing the update timestamp and
@@ -118,9 +252,91 @@ func (m *Manager) GetStatus(ctx context.Context, _ *monalivepb.GetStatusRequest)
 	return &monalivepb.GetStatusResponse{
 		UpdateTimestamp: timestamppb.New(m.updateTS),
 		Status:          m.core.Status(),
+		Prefixes:        m.core.PrefixStatus(),
+		Balancer:        m.core.BalancerConnection(),
+	}, nil
+}
+
+// GetAnnounces retrieves the current state of the prefix announces.
+func (m *Manager) GetAnnounces(ctx context.Context, _ *monalivepb.GetAnnouncesRequest) (*monalivepb.GetAnnouncesResponse, error) {
+	m.logger.Info("starting retrieve announces state")
+	defer m.logger.Info("retrieve announces state finished")
+	return &monalivepb.GetAnnouncesResponse{
+		Announces: m.core.Announces(),
 	}, nil
 }
 
+// GetBalancerDrift retrieves the real servers whose state in the load balancer
+// differs from the state applied by Monalive.
+func (m *Manager) GetBalancerDrift(ctx context.Context, _ *monalivepb.GetBalancerDriftRequest) (*monalivepb.GetBalancerDriftResponse, error) {
+	drifts, synced := m.core.BalancerDrift()
+	response := &monalivepb.GetBalancerDriftResponse{
+		Drifts: drifts,
+	}
+	if !synced.IsZero() {
+		response.LastSync = timestamppb.New(synced)
+	}
+	return response, nil
+}
+
+// GetShadowDiff compares the decisions recorded in the shadow mode with the
+// live load balancer state.
+func (m *Manager) GetShadowDiff(ctx context.Context, _ *monalivepb.GetShadowDiffRequest) (*monalivepb.GetShadowDiffResponse, error) {
+	diffs, err := m.core.ShadowDiff(ctx)
+	switch {
+	case errors.Is(err, balancer.ErrNotComparable):
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
+	case err != nil:
+		return nil, status.Error(codes.Unavailable, fmt.Sprintf("failed to compare with balancer state: %v", err))
+	}
+	return &monalivepb.GetShadowDiffResponse{Diffs: diffs}, nil
+}
+
+// SetShutdownMode chooses whether the load balancer and announcer state is
+// kept on the next shutdown.
+func (m *Manager) SetShutdownMode(_ context.Context, request *monalivepb.SetShutdownModeRequest) (*monalivepb.SetShutdownModeResponse, error) {
+	m.SetKeepState(request.GetKeepState())
+	return &monalivepb.SetShutdownModeResponse{}, nil
+}
+
+// SetKeepState sets whether the load balancer and announcer state is kept on
+// the next shutdown.
+func (m *Manager) SetKeepState(keep bool) {
+	m.keepState.Store(keep)
+	m.logger.Info("shutdown mode changed", log.Bool("keep_state", keep))
+	if keep && m.config.StatesPath == "" {
+		m.logger.Warn("states path is not configured, the kept states of the reals will not be resumed after restart")
+	}
+}
+
+// KeepState reports whether the load balancer and announcer state is kept on
+// shutdown.
+func (m *Manager) KeepState() bool {
+	return m.keepState.Load()
+}
+
+// DumpStates persists the states of the reals if the states path is
+// configured. It is expected to be called on shutdown before the core is
+// stopped, as stopping disables all reals.
+func (m *Manager) DumpStates() error {
+	if m.config.StatesPath == "" {
+		return nil
+	}
+	return dumpSnapshots(m.config.StatesPath, m.core.Snapshots(), time.Now())
+}
+
+// dumpOverrides persists the current overrides if the overrides path is
+// configured.
+func (m *Manager) dumpOverrides() error {
+	if m.config.OverridesPath == "" {
+		return nil
+	}
+
+	m.overridesMu.Lock()
+	defer m.overridesMu.Unlock()
+	return dumpOverrides(m.config.OverridesPath, m.core.Overrides())
+}
+
 // loadConfig loads the configuration from the specified path using the selected loader function.
 // It returns the loaded Config instance or an error if loading fails.
 func (m *Manager) loadConfig() (*Config, error) {
@@ -132,3 +348,59 @@ func (m *Manager) loadConfig() (*Config, error) {
 	}
 	return coreConfig, nil
 }
+
+// serviceKeyFromRequest builds the service key from the request fields.
+func serviceKeyFromRequest(vip string, vport *uint32, protocol string) (key.Service, error) {
+	addr, err := netip.ParseAddr(vip)
+	if err != nil {
+		return key.Service{}, fmt.Errorf("invalid virtual ip: %w", err)
+	}
+	servicePort, err := port.ProtoUnmarshaller(vport)
+	if err != nil {
+		return key.Service{}, err
+	}
+	return key.Service{
+		Addr:  addr.Unmap(),
+		Port:  servicePort,
+		Proto: protocol,
+	}, nil
+}
+
+// realKeyFromRequest builds the real key from the request fields.
+func realKeyFromRequest(ip string, rport *uint32) (key.Real, error) {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return key.Real{}, fmt.Errorf("invalid real ip: %w", err)
+	}
+	realPort, err := port.ProtoUnmarshaller(rport)
+	if err != nil {
+		return key.Real{}, err
+	}
+	return key.Real{
+		Addr: addr.Unmap(),
+		Port: realPort,
+	}, nil
+}
+
+// overrideFromRequest builds the override from the request fields.
+func overrideFromRequest(mode monalivepb.OverrideMode, ttl *durationpb.Duration, reason string) override.Override {
+	now := time.Now()
+	newOverride := override.Override{
+		Mode:    override.ModeProtoUnmarshaller(mode),
+		Reason:  reason,
+		Created: now,
+	}
+	if duration := ttl.AsDuration(); duration > 0 {
+		newOverride.Expires = now.Add(duration)
+	}
+	return newOverride
+}
+
+// overrideError converts the error returned while setting the override to the
+// gRPC status error.
+func overrideError(err error) error {
+	if errors.Is(err, ErrServiceNotFound) || errors.Is(err, service.ErrRealNotFound) {
+		return status.Error(codes.NotFound, err.Error())
+	}
+	return status.Error(codes.Internal, err.Error())
+}
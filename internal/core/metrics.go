@@ -21,6 +21,9 @@ type Metrics struct {
 
 	realsErrors           metrics.CounterVec
 	realsErrorsPerService metrics.CounterVec
+
+	realsInvalidWeight           metrics.Counter
+	realsInvalidWeightPerService metrics.CounterVec
 }
 
 // NewMetrics ...
@@ -82,6 +85,16 @@ func NewMetrics(provider *metrics.ScopedMetrics) *Metrics {
 			append(serviceLabelNames, "error"),
 			metrics.WithDescription("observe reals errors for service"),
 		),
+
+		realsInvalidWeight: provider.Scope(metrics.Global).GetCounter(
+			"reals_invalid_weight",
+			metrics.WithDescription("number of checks reported invalid dynamic weight"),
+		),
+		realsInvalidWeightPerService: provider.Scope(metrics.PerService).GetCounterVec(
+			"reals_invalid_weight",
+			serviceLabelNames,
+			metrics.WithDescription("number of checks reported invalid dynamic weight for service"),
+		),
 	}
 }
 
@@ -128,10 +141,18 @@ func (m *Metrics) RealsErrorsForService(serviceLabels metrics.Labels) metrics.Co
 	)
 }
 
+func (m *Metrics) RealsInvalidWeightForService(serviceLabels metrics.Labels) metrics.Counter {
+	return metrics.NewCounterUnion(
+		m.realsInvalidWeightPerService.GetMetricWith(serviceLabels),
+		m.realsInvalidWeight,
+	)
+}
+
 func (m *Metrics) DeleteService(serviceLabels metrics.Labels) {
 	m.realsEnabledPerService.Delete(serviceLabels)
 	m.realsPerService.Delete(serviceLabels)
 	m.realsTrasitionPeriodPerService.Delete(serviceLabels)
 	m.realsResponseTimePerService.Delete(serviceLabels)
 	m.realsErrorsPerService.DeletePartialMatch(serviceLabels)
+	m.realsInvalidWeightPerService.Delete(serviceLabels)
 }
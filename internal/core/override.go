@@ -0,0 +1,185 @@
+package core
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/netip"
+	"os"
+	"time"
+
+	"github.com/yanet-platform/monalive/internal/types/key"
+	"github.com/yanet-platform/monalive/internal/types/override"
+	"github.com/yanet-platform/monalive/internal/types/port"
+)
+
+// ErrServiceNotFound is an error that indicates the requested service is not
+// known to the core.
+var ErrServiceNotFound = errors.New("service not found")
+
+// ServiceOverrides holds the manual overrides of a service and its reals.
+type ServiceOverrides struct {
+	Service override.Override
+	Reals   map[key.Real]override.Override
+}
+
+// SetServiceOverride sets the manual override of the service with the given
+// key. Returns [ErrServiceNotFound] if the service is not known.
+func (m *Core) SetServiceOverride(serviceKey key.Service, newOverride override.Override) error {
+	m.servicesMu.Lock()
+	defer m.servicesMu.Unlock()
+
+	service, exists := m.services[serviceKey]
+	if !exists {
+		return ErrServiceNotFound
+	}
+	service.SetOverride(newOverride)
+	return nil
+}
+
+// SetRealOverride sets the manual override of the real with the given key
+// within the service. Returns [ErrServiceNotFound] if the service is not known
+// or [service.ErrRealNotFound] if the real does not belong to the service.
+func (m *Core) SetRealOverride(serviceKey key.Service, realKey key.Real, newOverride override.Override) error {
+	m.servicesMu.Lock()
+	defer m.servicesMu.Unlock()
+
+	service, exists := m.services[serviceKey]
+	if !exists {
+		return ErrServiceNotFound
+	}
+	return service.SetRealOverride(realKey, newOverride)
+}
+
+// Overrides returns the active manual overrides of all services and their
+// reals.
+func (m *Core) Overrides() map[key.Service]ServiceOverrides {
+	m.servicesMu.Lock()
+	defer m.servicesMu.Unlock()
+
+	overrides := make(map[key.Service]ServiceOverrides)
+	for serviceKey, service := range m.services {
+		serviceOverride, realOverrides := service.Overrides()
+		if !serviceOverride.Active() && len(realOverrides) == 0 {
+			continue
+		}
+		overrides[serviceKey] = ServiceOverrides{
+			Service: serviceOverride,
+			Reals:   realOverrides,
+		}
+	}
+	// Overrides of the services that are not created yet are still valid.
+	for serviceKey, restored := range m.restoredOverrides {
+		if _, exists := overrides[serviceKey]; !exists {
+			overrides[serviceKey] = restored
+		}
+	}
+
+	return overrides
+}
+
+// RestoreOverrides sets the overrides to be applied to the services once they
+// are created. It is expected to be called before the first reload.
+func (m *Core) RestoreOverrides(overrides map[key.Service]ServiceOverrides) {
+	m.servicesMu.Lock()
+	defer m.servicesMu.Unlock()
+	m.restoredOverrides = overrides
+}
+
+// overrideRecord represents a single manual override stored in the overrides
+// file.
+type overrideRecord struct {
+	VIP      netip.Addr  `json:"vip"`
+	Port     *uint32     `json:"port,omitempty"`
+	Protocol string      `json:"protocol"`
+	RealIP   *netip.Addr `json:"real_ip,omitempty"`
+	RealPort *uint32     `json:"real_port,omitempty"`
+
+	override.Override
+}
+
+// dumpOverrides saves the overrides to the file at the specified path.
+func dumpOverrides(path string, overrides map[key.Service]ServiceOverrides) error {
+	records := []overrideRecord{}
+	for serviceKey, serviceOverrides := range overrides {
+		record := overrideRecord{
+			VIP:      serviceKey.Addr,
+			Port:     serviceKey.Port.ProtoMarshaller(),
+			Protocol: serviceKey.Proto,
+		}
+
+		if serviceOverrides.Service.Active() {
+			record.Override = serviceOverrides.Service
+			records = append(records, record)
+		}
+
+		for realKey, realOverride := range serviceOverrides.Reals {
+			record.RealIP = &realKey.Addr
+			record.RealPort = realKey.Port.ProtoMarshaller()
+			record.Override = realOverride
+			records = append(records, record)
+		}
+	}
+
+	return dumpJSON(path, records)
+}
+
+// loadOverrides loads the overrides from the file at the specified path.
+// Expired overrides are skipped. If the file does not exist, no overrides are
+// returned.
+func loadOverrides(path string) (map[key.Service]ServiceOverrides, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read: %w", err)
+	}
+
+	var records []overrideRecord
+	if err := json.Unmarshal(data, &records); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal: %w", err)
+	}
+
+	now := time.Now()
+	overrides := make(map[key.Service]ServiceOverrides)
+	for _, record := range records {
+		if !record.Active() || record.Expired(now) {
+			continue
+		}
+
+		servicePort, err := port.ProtoUnmarshaller(record.Port)
+		if err != nil {
+			return nil, err
+		}
+		serviceKey := key.Service{
+			Addr:  record.VIP,
+			Port:  servicePort,
+			Proto: record.Protocol,
+		}
+
+		serviceOverrides := overrides[serviceKey]
+		if serviceOverrides.Reals == nil {
+			serviceOverrides.Reals = make(map[key.Real]override.Override)
+		}
+
+		switch record.RealIP {
+		case nil:
+			serviceOverrides.Service = record.Override
+		default:
+			realPort, err := port.ProtoUnmarshaller(record.RealPort)
+			if err != nil {
+				return nil, err
+			}
+			realKey := key.Real{
+				Addr: *record.RealIP,
+				Port: realPort,
+			}
+			serviceOverrides.Reals[realKey] = record.Override
+		}
+
+		overrides[serviceKey] = serviceOverrides
+	}
+
+	return overrides, nil
+}
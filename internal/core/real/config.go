@@ -5,6 +5,7 @@ import (
 	"net/netip"
 	"slices"
 	"strconv"
+	"time"
 
 	"github.com/yanet-platform/monalive/internal/core/checker"
 	"github.com/yanet-platform/monalive/internal/scheduler"
@@ -37,6 +38,28 @@ type Config struct {
 	// Forwarding method (TUN, GRE) to send health checks to the service.
 	ForwardingMethod string `keepalive:"lvs_method"` // optional
 
+	// Duration in seconds of the weight ramp after the real is enabled. The
+	// weight sent to the load balancer rises from the SlowStartMinWeight to the
+	// target weight over this period. Not set or zero value disables slow
+	// start.
+	SlowStart *float64 `keepalive:"slow_start"` // optional
+	// Number of equal steps of the weight ramp. Not set or zero value means
+	// that the weight rises linearly.
+	SlowStartSteps *int `keepalive:"slow_start_steps"` // optional
+	// Weight the ramp starts from. Defaults to 1.
+	SlowStartMinWeight *weight.Weight `keepalive:"slow_start_min_weight"` // optional
+
+	// Percentage of the weight applied to the real while its latency exceeds
+	// the degradation threshold of the checker. Zero value sets the weight of
+	// the degraded real to zero. Defaults to 50.
+	LatencyDegradedWeight *uint `keepalive:"latency_degraded_weight"` // optional
+	// Whether the outlier ejection is enabled. If so, the weight of the real
+	// responding slower than the median of the service reals is scaled down in
+	// proportion to its latency.
+	OutlierEjection *bool `keepalive:"outlier_ejection"` // optional
+	// Minimal percentage of the weight left to the outlier. Defaults to 10.
+	OutlierMinWeight *uint `keepalive:"outlier_min_weight"` // optional
+
 	// Embedded scheduler configuration.
 	Scheduler `keepalive_nested:"scheduler"`
 
@@ -103,6 +126,55 @@ func (m *Config) Prepare() error {
 	return nil
 }
 
+// GetSlowStart returns the duration of the weight ramp. Zero value means that
+// slow start is disabled.
+func (m *Config) GetSlowStart() time.Duration {
+	if m.SlowStart == nil || *m.SlowStart <= 0 {
+		return 0
+	}
+	return time.Duration(*m.SlowStart * float64(time.Second))
+}
+
+// GetSlowStartSteps returns the number of steps of the weight ramp. Zero value
+// means that the weight rises linearly.
+func (m *Config) GetSlowStartSteps() int {
+	if m.SlowStartSteps == nil || *m.SlowStartSteps < 0 {
+		return 0
+	}
+	return *m.SlowStartSteps
+}
+
+// GetSlowStartMinWeight returns the weight the ramp starts from.
+func (m *Config) GetSlowStartMinWeight() weight.Weight {
+	if m.SlowStartMinWeight == nil || *m.SlowStartMinWeight < 0 {
+		return 1
+	}
+	return *m.SlowStartMinWeight
+}
+
+// GetLatencyDegradedWeight returns the percentage of the weight applied to the
+// degraded real.
+func (m *Config) GetLatencyDegradedWeight() uint {
+	if m.LatencyDegradedWeight == nil || *m.LatencyDegradedWeight > 100 {
+		return 50
+	}
+	return *m.LatencyDegradedWeight
+}
+
+// GetOutlierEjection reports whether the outlier ejection is enabled.
+func (m *Config) GetOutlierEjection() bool {
+	return m.OutlierEjection != nil && *m.OutlierEjection
+}
+
+// GetOutlierMinWeight returns the minimal percentage of the weight left to the
+// outlier.
+func (m *Config) GetOutlierMinWeight() uint {
+	if m.OutlierMinWeight == nil || *m.OutlierMinWeight > 100 {
+		return 10
+	}
+	return *m.OutlierMinWeight
+}
+
 // MarshalJSON implements json.Marshaler interface.
 //
 // This custom marshaller converts Port and Weight to strings and includes only
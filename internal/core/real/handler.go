@@ -5,6 +5,7 @@ import (
 
 	log "go.uber.org/zap"
 
+	"github.com/yanet-platform/monalive/internal/types/override"
 	"github.com/yanet-platform/monalive/internal/types/weight"
 	"github.com/yanet-platform/monalive/internal/types/xevent"
 )
@@ -37,6 +38,38 @@ func (m *Real) HandleEvent(event *xevent.Event) {
 	m.handler(event)
 }
 
+// updateState applies the update to the real state outside of the checker
+// events processing and notifies the parent service if the status of the real
+// has changed.
+func (m *Real) updateState(update func(state *State)) {
+	// Increment the wait group counter for event processing.
+	// Real won't be stopped until the wait group counter is zero.
+	m.eventsWG.Add(1)
+	defer m.eventsWG.Done()
+
+	m.stateMu.Lock()
+	initStatus := m.state.Status()
+	update(&m.state)
+	newStatus := m.state.Status()
+	m.stateMu.Unlock()
+
+	if statusEqual(initStatus, newStatus) {
+		// The update does not affect the status of the real.
+		return
+	}
+
+	event := &xevent.Event{
+		Type: eventType(newStatus),
+		New:  newStatus,
+		Init: initStatus,
+	}
+	// Assign the current real's key to the event for tracking.
+	event.Real = m.key
+
+	// Pass the event to the service event handler for further processing.
+	m.handler(event)
+}
+
 // processSucceed handles the enable event, updating the real's status and
 // weight.
 func (m *Real) processSucceed(event *xevent.Event) (drop bool) {
@@ -49,18 +82,26 @@ func (m *Real) processSucceed(event *xevent.Event) (drop bool) {
 
 	// If the real is inhibited, ensure it is marked as enabled but with a
 	// weight of 0.
-	if m.state.Inhibited {
+	if m.state.Inhibited && !m.state.EffectiveOverride().Active() {
 		initStatus.Enable = true
 		initStatus.Weight = 0
 	}
 
-	// Determine the new weight of the real, considering dynamic weight if
-	// applicable.
-	newWeight := m.handleDynamicWeight(event.New.Weight)
+	// Determine the new weight of the real, considering dynamic weight and
+	// latency if applicable.
+	newWeight, newLatency := m.handleDynamicWeight(event)
 	// Update the weight and check if it has changed.
-	weightChanged := m.updateWeight(newWeight)
+	weightChanged := m.updateWeight(newWeight, newLatency)
 	// Enable the real and check if its status has changed.
 	statusChanged := m.enableReal()
+	// Mark the real as checked.
+	m.checked = true
+
+	// If the real is overridden, its status is determined by the override
+	// rather than by the checker result.
+	if m.state.EffectiveOverride().Active() {
+		return m.processOverridden(event, initStatus)
+	}
 
 	// If neither the status nor the weight has changed, no further action is
 	// needed.
@@ -84,8 +125,26 @@ func (m *Real) processFail(event *xevent.Event) (drop bool) {
 	// Store the initial status of the real for comparison later.
 	initStatus := m.state.Status()
 
+	overridden := m.state.EffectiveOverride().Active()
+	if overridden && event.Type == xevent.Shutdown && m.stopping() {
+		// The real is being stopped, so its overrides are no longer applied
+		// and the real must be removed from the load balancer.
+		m.state.Override = override.Override{}
+		m.state.ServiceOverride = override.Override{}
+	}
+
 	// Disable the real and check if its status has changed.
-	if statusChanged := m.disableReal(event.Type); !statusChanged {
+	statusChanged := m.disableReal(event.Type)
+	// Mark the real as checked.
+	m.checked = true
+
+	// If the real is (or was until now) overridden, its status is determined
+	// by the override rather than by the checker result.
+	if overridden {
+		return m.processOverridden(event, initStatus)
+	}
+
+	if !statusChanged {
 		// If the status hasn't changed, no further action is needed.
 		return true
 	}
@@ -104,21 +163,33 @@ func (m *Real) processFail(event *xevent.Event) (drop bool) {
 	return false
 }
 
+// stopping reports whether the real is being stopped.
+func (m *Real) stopping() bool {
+	select {
+	case <-m.shutdown.Done():
+		return true
+	default:
+		return false
+	}
+}
+
 // handleDynamicWeight determines the weight of the real service, considering
-// dynamic weight if applicable.
-func (m *Real) handleDynamicWeight(weightFromChecker weight.Weight) weight.Weight {
-	switch {
+// dynamic weight if applicable, and the latency state reducing this weight.
+func (m *Real) handleDynamicWeight(event *xevent.Event) (weight.Weight, Latency) {
+	latency := m.latencyState(event.Latency, event.Degraded)
+
+	switch weightFromChecker := event.New.Weight; {
 	// If dynamic weight is not enabled, return the static config weight.
 	case !m.state.DynWeight:
-		return m.config.Weight
+		return m.config.Weight, latency
 
 	// If the weight from the checker is not omitted, use it as the new weight.
 	case weightFromChecker != weight.Omitted:
-		return weightFromChecker
+		return weightFromChecker, latency
 
 	// Otherwise, return the current weight from the real's state.
 	default:
-		return m.state.Weight
+		return m.state.Weight, latency
 	}
 }
 
@@ -132,6 +203,8 @@ func (m *Real) enableReal() (changed bool) {
 	// Mark the real as enabled and clear any inhibition.
 	m.state.Alive = true
 	m.state.Inhibited = false
+	// Ramp the weight up if slow start is configured.
+	m.startSlowStart()
 	// Increment the transition counter to track status changes.
 	m.state.Transitions++
 	m.log.Info(
@@ -182,6 +255,10 @@ func (m *Real) disableReal(eventType xevent.Type) (changed bool) {
 
 	// Mark the real as disabled.
 	m.state.Alive = false
+	// Cancel the weight ramp if it is in progress.
+	m.state.SlowStart = SlowStart{}
+	// The latency of the disabled real is unknown.
+	m.resetLatency()
 	// Increment the transition counter to track status changes.
 	m.state.Transitions++
 	m.log.Info("real disabled", log.String("event_type", "real update"))
@@ -191,20 +268,25 @@ func (m *Real) disableReal(eventType xevent.Type) (changed bool) {
 	return true
 }
 
-// updateWeight update the weight value of real. Returns true if the new value
-// differs from the previous one, otherwise false.
-func (m *Real) updateWeight(weight weight.Weight) (changed bool) {
-	// If the new weight is the same as the current weight, no changes are
+// updateWeight update the weight value and the latency state of real. Returns
+// true if the resulting weight differs from the previous one, otherwise false.
+func (m *Real) updateWeight(weight weight.Weight, latency Latency) (changed bool) {
+	prevWeight := m.state.Latency.Weight(m.state.Weight)
+
+	// Update the weight and the latency in the real's state.
+	m.state.Weight = weight
+	m.state.Latency = latency
+
+	// If the resulting weight is the same as the previous one, no changes are
 	// needed.
-	if m.state.Weight == weight {
+	newWeight := latency.Weight(weight)
+	if prevWeight == newWeight {
 		return false
 	}
 
-	// Update the weight in the real's state.
-	m.state.Weight = weight
 	m.log.Info(
 		"real weight changed",
-		log.Int("weight", int(m.state.Weight)),
+		log.Int("weight", int(newWeight)),
 		log.String("event_type", "real update"),
 	)
 	return true
@@ -0,0 +1,122 @@
+package real
+
+import (
+	"math"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/yanet-platform/monalive/internal/types/key"
+	"github.com/yanet-platform/monalive/internal/types/weight"
+)
+
+// Latency represents the latency state of the real reported by its checkers.
+type Latency struct {
+	// Value is the response time percentile reported by the checker.
+	Value time.Duration
+	// Degraded indicates that the latency exceeds the degradation threshold of
+	// the checker.
+	Degraded bool
+	// Scale is the factor in range [0, 1) the weight is multiplied by. Nil
+	// value means that the weight is not scaled.
+	Scale *float64
+}
+
+// Weight returns the given weight scaled according to the latency state. The
+// weight scaled by a positive factor is never reduced below 1, so the real
+// keeps receiving some traffic and its latency can recover.
+func (m Latency) Weight(w weight.Weight) weight.Weight {
+	if m.Scale == nil || w <= 0 {
+		return w
+	}
+	scaled := weight.Weight(math.Round(float64(w) * *m.Scale))
+	if *m.Scale > 0 {
+		scaled = max(scaled, 1)
+	}
+	return scaled
+}
+
+// LatencyTracker collects the latencies of the reals of a service to calculate
+// their median used to detect outliers. It is safe for concurrent use.
+type LatencyTracker struct {
+	latencies map[key.Real]time.Duration
+	mu        sync.Mutex
+}
+
+// NewLatencyTracker creates a new LatencyTracker instance.
+func NewLatencyTracker() *LatencyTracker {
+	return &LatencyTracker{
+		latencies: make(map[key.Real]time.Duration),
+	}
+}
+
+// Observe stores the latency of the real.
+func (m *LatencyTracker) Observe(real key.Real, latency time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.latencies[real] = latency
+}
+
+// Forget removes the latency of the real, so it no longer affects the median.
+func (m *LatencyTracker) Forget(real key.Real) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.latencies, real)
+}
+
+// Median returns the median latency of the observed reals.
+func (m *LatencyTracker) Median() time.Duration {
+	m.mu.Lock()
+	latencies := make([]time.Duration, 0, len(m.latencies))
+	for _, latency := range m.latencies {
+		latencies = append(latencies, latency)
+	}
+	m.mu.Unlock()
+
+	if len(latencies) == 0 {
+		return 0
+	}
+	slices.Sort(latencies)
+	middle := len(latencies) / 2
+	if len(latencies)%2 == 0 {
+		return (latencies[middle-1] + latencies[middle]) / 2
+	}
+	return latencies[middle]
+}
+
+// latencyState returns the latency state of the real according to the latency
+// reported by the checker. Weight of the degraded real is reduced by the
+// configured percentage. If outlier ejection is enabled, weight of the real
+// responding slower than the median of the service reals is scaled down in
+// proportion to its latency.
+func (m *Real) latencyState(latency time.Duration, degraded bool) Latency {
+	scale := 1.0
+	if degraded {
+		scale *= float64(m.config.GetLatencyDegradedWeight()) / 100
+	}
+	if m.latencyTracker != nil && m.config.GetOutlierEjection() && latency > 0 {
+		m.latencyTracker.Observe(m.key, latency)
+		if median := m.latencyTracker.Median(); median > 0 && latency > median {
+			minScale := float64(m.config.GetOutlierMinWeight()) / 100
+			scale *= max(float64(median)/float64(latency), minScale)
+		}
+	}
+
+	state := Latency{
+		Value:    latency,
+		Degraded: degraded,
+	}
+	if scale < 1 {
+		state.Scale = &scale
+	}
+	return state
+}
+
+// resetLatency clears the latency state of the disabled real and excludes it
+// from the median calculation.
+func (m *Real) resetLatency() {
+	m.state.Latency = Latency{}
+	if m.latencyTracker != nil {
+		m.latencyTracker.Forget(m.key)
+	}
+}
@@ -0,0 +1,104 @@
+package real
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yanet-platform/monalive/internal/types/key"
+	"github.com/yanet-platform/monalive/internal/types/weight"
+	"github.com/yanet-platform/monalive/internal/types/xevent"
+)
+
+// latencyEvent creates an [xevent.Event] to enable a real with the specified
+// latency state.
+func latencyEvent(latency time.Duration, degraded bool) *xevent.Event {
+	event := enableEvent(weight.Omitted)
+	event.Latency = latency
+	event.Degraded = degraded
+	return event
+}
+
+// TestLatency_Degraded tests that the weight of the degraded real is reduced
+// by the configured percentage.
+func TestLatency_Degraded(t *testing.T) {
+	handler := &testHandler{}
+	real := defaultReal(weight.Weight(10), handler.Handle)
+
+	real.HandleEvent(latencyEvent(time.Millisecond, false))
+	require.NotNil(t, handler.Event())
+
+	real.HandleEvent(latencyEvent(time.Second, true))
+	event := handler.Event()
+	require.NotNil(t, event)
+	assert.Equal(t, weight.Weight(5), event.New.Weight)
+	assert.Equal(t, weight.Weight(10), real.State().Weight)
+
+	real.HandleEvent(latencyEvent(time.Millisecond, false))
+	event = handler.Event()
+	require.NotNil(t, event)
+	assert.Equal(t, weight.Weight(10), event.New.Weight)
+}
+
+// TestLatency_DegradedZeroWeight tests that zero degraded weight percentage
+// sets the weight of the degraded real to zero.
+func TestLatency_DegradedZeroWeight(t *testing.T) {
+	handler := &testHandler{}
+	real := defaultReal(weight.Weight(10), handler.Handle)
+
+	degradedWeight := uint(0)
+	real.config.LatencyDegradedWeight = &degradedWeight
+
+	real.HandleEvent(latencyEvent(time.Millisecond, false))
+	require.NotNil(t, handler.Event())
+
+	real.HandleEvent(latencyEvent(time.Second, true))
+	event := handler.Event()
+	require.NotNil(t, event)
+	assert.True(t, event.New.Enable)
+	assert.Equal(t, weight.Weight(0), event.New.Weight)
+	assert.Equal(t, weight.Weight(10), real.State().Weight)
+
+	real.HandleEvent(latencyEvent(time.Millisecond, false))
+	event = handler.Event()
+	require.NotNil(t, event)
+	assert.Equal(t, weight.Weight(10), event.New.Weight)
+}
+
+// TestLatency_OutlierEjection tests that the weight of the real responding
+// slower than the median of the service reals is scaled down in proportion to
+// its latency.
+func TestLatency_OutlierEjection(t *testing.T) {
+	handler := &testHandler{}
+	tracker := NewLatencyTracker()
+	real := defaultReal(weight.Weight(100), handler.Handle)
+	real.latencyTracker = tracker
+
+	outlier := true
+	real.config.OutlierEjection = &outlier
+
+	// Other reals of the service respond within 10ms.
+	otherReal := key.Real{Port: 1}
+	tracker.Observe(otherReal, 10*time.Millisecond)
+	tracker.Observe(key.Real{Port: 2}, 10*time.Millisecond)
+
+	real.HandleEvent(latencyEvent(40*time.Millisecond, false))
+	event := handler.Event()
+	require.NotNil(t, event)
+	// The median is 10ms, so the weight is scaled to 10 / 40.
+	assert.Equal(t, weight.Weight(25), event.New.Weight)
+
+	// The real is never scaled below the minimal weight percentage.
+	real.HandleEvent(latencyEvent(time.Second, false))
+	event = handler.Event()
+	require.NotNil(t, event)
+	assert.Equal(t, weight.Weight(10), event.New.Weight)
+
+	// The disabled real is excluded from the median calculation.
+	real.HandleEvent(disableEvent())
+	require.NotNil(t, handler.Event())
+	tracker.Forget(otherReal)
+	assert.Equal(t, 10*time.Millisecond, tracker.Median())
+}
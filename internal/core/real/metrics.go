@@ -12,6 +12,7 @@ type Metrics struct {
 	realTransitionPeriod metrics.Histogram
 	realResponseTime     metrics.Histogram
 	realErrors           metrics.CounterVec
+	realInvalidWeight    metrics.Counter
 
 	isBlocked bool
 	mu        sync.Mutex
@@ -22,6 +23,7 @@ func NewMetrics() *Metrics {
 		realTransitionPeriod: &metrics.NopHistogram{},
 		realResponseTime:     &metrics.NopHistogram{},
 		realErrors:           &metrics.NopCounterVec{},
+		realInvalidWeight:    &metrics.NopCounter{},
 	}
 }
 
@@ -29,6 +31,10 @@ func (m *Metrics) RealErrors() metrics.CounterVec {
 	return m.realErrors
 }
 
+func (m *Metrics) RealInvalidWeight() metrics.Counter {
+	return m.realInvalidWeight
+}
+
 func (m *Metrics) RealResponseTime() metrics.Histogram {
 	return m.realResponseTime
 }
@@ -74,3 +80,13 @@ func SetRealTransitionPeriodMetric(hist metrics.Histogram) SetMetricFunc {
 		}
 	}
 }
+
+func SetRealInvalidWeightMetric(counter metrics.Counter) SetMetricFunc {
+	return func(m *Metrics) {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		if !m.isBlocked {
+			m.realInvalidWeight = counter
+		}
+	}
+}
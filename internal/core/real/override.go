@@ -0,0 +1,96 @@
+package real
+
+import (
+	log "go.uber.org/zap"
+
+	"github.com/yanet-platform/monalive/internal/types/override"
+	"github.com/yanet-platform/monalive/internal/types/xevent"
+)
+
+// SetOverride sets the manual override of the real. The override takes
+// precedence over the one inherited from the service. Setting the override
+// with [override.Auto] mode removes it.
+func (m *Real) SetOverride(newOverride override.Override) {
+	m.stateMu.Lock()
+	// Cancel the expiration of the previous override.
+	if m.overrideTimer != nil {
+		m.overrideTimer.Stop()
+		m.overrideTimer = nil
+	}
+	// Schedule the reset of the new override once it expires.
+	m.overrideTimer = newOverride.AfterExpire(func() {
+		m.updateOverride(func(state *State) {
+			// Reset the override only if it has not been replaced since.
+			if state.Override == newOverride {
+				state.Override = override.Override{}
+			}
+		})
+	})
+	m.stateMu.Unlock()
+
+	m.log.Info(
+		"real override set",
+		log.String("mode", string(newOverride.Mode)),
+		log.String("reason", newOverride.Reason),
+		log.Time("expires", newOverride.Expires),
+		log.String("event_type", "real update"),
+	)
+
+	m.updateOverride(func(state *State) {
+		state.Override = newOverride
+	})
+}
+
+// SetServiceOverride sets the manual override inherited from the service. It
+// is applied only if the real does not have its own override. The expiration
+// of the service override is managed by the service.
+func (m *Real) SetServiceOverride(serviceOverride override.Override) {
+	m.updateOverride(func(state *State) {
+		state.ServiceOverride = serviceOverride
+	})
+}
+
+// updateOverride applies the update to the override fields of the real state
+// and notifies the parent service if the status of the real has changed.
+func (m *Real) updateOverride(update func(state *State)) {
+	m.updateState(func(state *State) {
+		update(state)
+		if state.EffectiveOverride().Mode == override.ForceUp && state.Transitions == 0 {
+			// The real has not been checked yet, so its weight is unknown. Use
+			// the configured one.
+			state.Weight = m.config.Weight
+		}
+	})
+}
+
+// processOverridden finalizes the event processing if the real is overridden.
+// The event is built from the real status instead of the checker result.
+func (m *Real) processOverridden(event *xevent.Event, initStatus xevent.Status) (drop bool) {
+	newStatus := m.state.Status()
+	if statusEqual(initStatus, newStatus) {
+		// The override hides the change, so the event is not needed.
+		return true
+	}
+
+	event.Type = eventType(newStatus)
+	event.New = newStatus
+	event.Init = initStatus
+	return false
+}
+
+// statusEqual reports whether two statuses are identical from the load
+// balancer's point of view. Weights of disabled reals are not compared.
+func statusEqual(a, b xevent.Status) bool {
+	if a.Enable != b.Enable {
+		return false
+	}
+	return !a.Enable || a.Weight == b.Weight
+}
+
+// eventType returns the type of the event that results in the given status.
+func eventType(status xevent.Status) xevent.Type {
+	if status.Enable {
+		return xevent.Enable
+	}
+	return xevent.Disable
+}
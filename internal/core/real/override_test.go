@@ -0,0 +1,141 @@
+package real
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yanet-platform/monalive/internal/types/override"
+	"github.com/yanet-platform/monalive/internal/types/weight"
+	"github.com/yanet-platform/monalive/internal/types/xevent"
+)
+
+// TestOverride_ForceDown tests that the force down override disables the
+// enabled real and hides the checker results until the override is removed.
+func TestOverride_ForceDown(t *testing.T) {
+	handler := &testHandler{}
+	initWeight := weight.Weight(1)
+	real := defaultReal(initWeight, handler.Handle)
+
+	{
+		real.HandleEvent(enableEvent(weight.Omitted))
+		require.NotNil(t, handler.Event())
+	}
+
+	{
+		// Force down the enabled real and verify that the disable event is
+		// emitted.
+		real.SetOverride(override.Override{Mode: override.ForceDown})
+		event := handler.Event()
+		require.NotNil(t, event)
+		assert.Equal(t, xevent.Disable, event.Type)
+		assert.Equal(t, real.Key(), event.Real)
+		assert.Equal(t, true, event.Init.Enable)
+		assert.Equal(t, false, event.New.Enable)
+	}
+
+	{
+		// Checker results must not affect the overridden real.
+		real.HandleEvent(disableEvent())
+		require.Nil(t, handler.Event())
+		real.HandleEvent(enableEvent(weight.Omitted))
+		require.Nil(t, handler.Event())
+	}
+
+	{
+		// Remove the override and verify that the real is enabled back
+		// according to the last checker result.
+		real.SetOverride(override.Override{Mode: override.Auto})
+		event := handler.Event()
+		require.NotNil(t, event)
+		assert.Equal(t, xevent.Enable, event.Type)
+		assert.Equal(t, false, event.Init.Enable)
+		assert.Equal(t, true, event.New.Enable)
+		assert.Equal(t, initWeight, event.New.Weight)
+	}
+}
+
+// TestOverride_Drain tests that the drain override keeps the real enabled with
+// zero weight.
+func TestOverride_Drain(t *testing.T) {
+	handler := &testHandler{}
+	initWeight := weight.Weight(5)
+	real := defaultReal(initWeight, handler.Handle)
+
+	{
+		real.HandleEvent(enableEvent(weight.Omitted))
+		require.NotNil(t, handler.Event())
+	}
+
+	{
+		real.SetOverride(override.Override{Mode: override.Drain})
+		event := handler.Event()
+		require.NotNil(t, event)
+		assert.Equal(t, xevent.Enable, event.Type)
+		assert.Equal(t, initWeight, event.Init.Weight)
+		assert.Equal(t, true, event.New.Enable)
+		assert.Equal(t, weight.Weight(0), event.New.Weight)
+	}
+
+	{
+		// Failed check disables the drained real.
+		real.HandleEvent(disableEvent())
+		event := handler.Event()
+		require.NotNil(t, event)
+		assert.Equal(t, xevent.Disable, event.Type)
+		assert.Equal(t, false, event.New.Enable)
+	}
+}
+
+// TestOverride_ServiceOverride tests that the own override of the real takes
+// precedence over the service override.
+func TestOverride_ServiceOverride(t *testing.T) {
+	handler := &testHandler{}
+	initWeight := weight.Weight(1)
+	real := defaultReal(initWeight, handler.Handle)
+
+	{
+		// Force up the disabled real via the service override.
+		real.SetServiceOverride(override.Override{Mode: override.ForceUp})
+		event := handler.Event()
+		require.NotNil(t, event)
+		assert.Equal(t, xevent.Enable, event.Type)
+		assert.Equal(t, initWeight, event.New.Weight)
+	}
+
+	{
+		// Own override of the real takes precedence.
+		real.SetOverride(override.Override{Mode: override.ForceDown})
+		event := handler.Event()
+		require.NotNil(t, event)
+		assert.Equal(t, xevent.Disable, event.Type)
+	}
+
+	{
+		// Service override changes are hidden by the own override.
+		real.SetServiceOverride(override.Override{Mode: override.Auto})
+		require.Nil(t, handler.Event())
+	}
+}
+
+// TestOverride_Expire tests that the override is removed once it expires.
+func TestOverride_Expire(t *testing.T) {
+	events := make(chan *xevent.Event, 2)
+	real := defaultReal(weight.Weight(1), func(event *xevent.Event) { events <- event })
+
+	real.SetOverride(override.Override{
+		Mode:    override.ForceUp,
+		Expires: time.Now().Add(10 * time.Millisecond),
+	})
+	require.Equal(t, xevent.Enable, (<-events).Type)
+
+	select {
+	case event := <-events:
+		assert.Equal(t, xevent.Disable, event.Type)
+	case <-time.After(time.Second):
+		t.Fatal("override has not expired")
+	}
+	assert.False(t, real.State().Override.Active())
+}
@@ -11,6 +11,7 @@ import (
 
 	"github.com/yanet-platform/monalive/internal/core/checker"
 	"github.com/yanet-platform/monalive/internal/types/key"
+	"github.com/yanet-platform/monalive/internal/types/override"
 	"github.com/yanet-platform/monalive/internal/types/weight"
 	"github.com/yanet-platform/monalive/internal/types/xevent"
 	"github.com/yanet-platform/monalive/internal/utils/shutdown"
@@ -40,6 +41,13 @@ type Real struct {
 	state   State        // current state of the real
 	stateMu sync.RWMutex // to protect concurent access to the state
 
+	overrideTimer *time.Timer // resets the override of the real when it expires
+	checked       bool        // whether at least one check result has been processed
+
+	snapshot        *Snapshot   // state restored once the real is activated, optional
+	snapshotExpires time.Time   // time the restored state is trusted until
+	snapshotTimer   *time.Timer // resets the restored state when it expires
+
 	handler  xevent.Handler // callback event handler function provided by the parent service
 	eventsWG sync.WaitGroup // to manage goroutines handling events
 
@@ -47,6 +55,8 @@ type Real struct {
 
 	activationFunc ActivationFunc // used to control the activation of the real if necessary
 	isActive       bool
+	checkLimiter   checker.Limiter // passed to the checkers to limit the checks, optional
+	latencyTracker *LatencyTracker // shared by the service reals to detect outliers, optional
 
 	reloadMu sync.Mutex // to prevent concurrent config updates
 	shutdown *shutdown.Shutdown
@@ -73,21 +83,60 @@ type State struct {
 	// because if the checker reports a successful check in the future, we must
 	// enable the real with its weight before inhibition.
 	Inhibited bool
+
+	// SlowStart is the state of the weight ramp after the real is enabled.
+	SlowStart SlowStart
+	// Latency is the latency state of the real reported by the checkers.
+	Latency Latency
+
+	// Override is the manual override of the real set via API.
+	Override override.Override
+	// ServiceOverride is the manual override inherited from the service. It is
+	// applied only if the real does not have its own override.
+	ServiceOverride override.Override
 }
 
 // Status returns the current status of the real based on its state.
 // If the service is inhibited, the status will reflect a weight of zero.
+// If the real is overridden, the status reflects the override mode.
 func (m State) Status() xevent.Status {
+	status := xevent.Status{
+		Enable: m.Alive,
+		Weight: m.Weight,
+	}
 	if m.Inhibited {
-		return xevent.Status{
+		status = xevent.Status{
 			Enable: true,
 			Weight: 0,
 		}
 	}
-	return xevent.Status{
-		Enable: m.Alive,
-		Weight: m.Weight,
+	if status.Enable && !m.Inhibited {
+		// Reduce the weight of the slow real.
+		status.Weight = m.Latency.Weight(status.Weight)
+		// Apply the weight ramp if the real is recently enabled.
+		status.Weight = m.SlowStart.Weight(status.Weight)
+	}
+
+	switch m.EffectiveOverride().Mode {
+	case override.Drain:
+		// Keep the real enabled if it is, but do not schedule new flows.
+		status.Weight = 0
+	case override.ForceDown:
+		status.Enable = false
+	case override.ForceUp:
+		status.Enable = true
+		status.Weight = m.Weight
 	}
+	return status
+}
+
+// EffectiveOverride returns the override applied to the real: its own override
+// if set, otherwise the override inherited from the service.
+func (m State) EffectiveOverride() override.Override {
+	if m.Override.Active() {
+		return m.Override
+	}
+	return m.ServiceOverride
 }
 
 // Option represents a function that configures a Real instance.
@@ -102,6 +151,22 @@ func WithActivationFunc(activation ActivationFunc) Option {
 	}
 }
 
+// WithCheckLimiter returns an Option that sets the limiter passed to the
+// checkers of the Real instance.
+func WithCheckLimiter(limiter checker.Limiter) Option {
+	return func(m *Real) {
+		m.checkLimiter = limiter
+	}
+}
+
+// WithLatencyTracker returns an Option that sets the tracker of the latencies
+// of the service reals used to detect outliers.
+func WithLatencyTracker(tracker *LatencyTracker) Option {
+	return func(m *Real) {
+		m.latencyTracker = tracker
+	}
+}
+
 // New creates a new Real instance.
 func New(config *Config, handler xevent.Handler, logger *log.Logger, opts ...Option) *Real {
 	logger = logger.With(
@@ -147,6 +212,9 @@ func (m *Real) Run(ctx context.Context) {
 	// Blocks access to the metrics after launching real.
 	m.metrics.Block()
 
+	// Resume with the state persisted before the restart, if any.
+	m.restoreSnapshot()
+
 	// Reload to apply initial checkers configuration.
 	go func() {
 		m.reloadMu.Lock()
@@ -181,6 +249,16 @@ func (m *Real) Stop() {
 	// Trigger the shutdown signal to gracefully stop workers.
 	m.shutdown.Do()
 
+	// Cancel the pending override and restored state expirations.
+	m.stateMu.Lock()
+	if m.overrideTimer != nil {
+		m.overrideTimer.Stop()
+	}
+	if m.snapshotTimer != nil {
+		m.snapshotTimer.Stop()
+	}
+	m.stateMu.Unlock()
+
 	// Lock the checkers mutex to ensure thread-safe access.
 	m.checkersMu.Lock()
 	defer m.checkersMu.Unlock()
@@ -272,15 +350,21 @@ func (m *Real) reload(config *Config) {
 
 			// If it's a new checker, create and initialize it.
 			case false:
+				var checkerOpts []checker.Option
+				if m.checkLimiter != nil {
+					checkerOpts = append(checkerOpts, checker.WithLimiter(m.checkLimiter))
+				}
 				newChecker := checker.New(
 					cfg,
 					m.HandleEvent,
 					config.Weight,
 					forwardingData,
 					m.log,
+					checkerOpts...,
 				)
 				newChecker.SetMetrics(
 					checker.SetErrorsMetric(m.metrics.RealErrors()),
+					checker.SetInvalidWeightMetric(m.metrics.RealInvalidWeight()),
 					checker.SetResponceTimeMetric(m.metrics.RealResponseTime()),
 				)
 				newCheckers[key] = newChecker
@@ -0,0 +1,98 @@
+package real
+
+import (
+	"time"
+
+	log "go.uber.org/zap"
+
+	"github.com/yanet-platform/monalive/internal/types/weight"
+)
+
+// Snapshot is the part of the real state persisted across restarts. It allows
+// the real to resume with its last known status instead of waiting for the
+// first check.
+type Snapshot struct {
+	Alive     bool
+	Weight    weight.Weight
+	Inhibited bool
+}
+
+// Enabled reports whether the real is enabled in the load balancer according
+// to the snapshot.
+func (m Snapshot) Enabled() bool {
+	return m.Alive || m.Inhibited
+}
+
+// WithSnapshot returns an Option that restores the state of the real from the
+// snapshot once the real is activated. The snapshot is trusted until the
+// expiration time: if no check result is processed by then, the real falls
+// back to the initial disabled state.
+func WithSnapshot(snapshot Snapshot, expires time.Time) Option {
+	return func(m *Real) {
+		m.snapshot = &snapshot
+		m.snapshotExpires = expires
+	}
+}
+
+// Snapshot returns the snapshot of the current state of the real.
+func (m *Real) Snapshot() Snapshot {
+	m.stateMu.RLock()
+	defer m.stateMu.RUnlock()
+	return Snapshot{
+		Alive:     m.state.Alive,
+		Weight:    m.state.Weight,
+		Inhibited: m.state.Inhibited,
+	}
+}
+
+// restoreSnapshot applies the restored snapshot to the state of the real and
+// schedules its expiration. The expired snapshot is discarded.
+func (m *Real) restoreSnapshot() {
+	if m.snapshot == nil {
+		return
+	}
+	snapshot := *m.snapshot
+	m.snapshot = nil
+
+	trusted := time.Until(m.snapshotExpires)
+	if trusted <= 0 {
+		return
+	}
+
+	m.log.Info(
+		"real state restored",
+		log.Bool("alive", snapshot.Alive),
+		log.Int("weight", int(snapshot.Weight)),
+		log.Bool("inhibited", snapshot.Inhibited),
+		log.Duration("trusted", trusted),
+		log.String("event_type", "real update"),
+	)
+
+	m.updateState(func(state *State) {
+		if m.checked {
+			// The check result is already processed, so the snapshot is
+			// outdated.
+			return
+		}
+		state.Alive = snapshot.Alive
+		state.Weight = snapshot.Weight
+		state.Inhibited = snapshot.Inhibited
+	})
+
+	m.stateMu.Lock()
+	m.snapshotTimer = time.AfterFunc(trusted, m.expireSnapshot)
+	m.stateMu.Unlock()
+}
+
+// expireSnapshot resets the restored state of the real if no check result has
+// been processed while the snapshot was trusted.
+func (m *Real) expireSnapshot() {
+	m.updateState(func(state *State) {
+		if m.checked {
+			return
+		}
+		m.log.Info("restored real state expired", log.String("event_type", "real update"))
+		state.Alive = false
+		state.Inhibited = false
+	})
+}
@@ -0,0 +1,72 @@
+package real
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yanet-platform/monalive/internal/types/weight"
+	"github.com/yanet-platform/monalive/internal/types/xevent"
+)
+
+// TestRestoreSnapshot tests that the restored state enables the real until the
+// first check result overrides it.
+func TestRestoreSnapshot(t *testing.T) {
+	handler := &testHandler{}
+	real := defaultReal(1, handler.Handle)
+	WithSnapshot(Snapshot{Alive: true, Weight: 5}, time.Now().Add(time.Hour))(real)
+
+	{
+		// The restored real is enabled with the restored weight.
+		real.restoreSnapshot()
+		defer real.Stop()
+		event := handler.Event()
+		require.NotNil(t, event)
+		assert.Equal(t, xevent.Enable, event.Type)
+		assert.Equal(t, weight.Weight(5), event.New.Weight)
+	}
+
+	{
+		// The successful check keeps the real enabled without the transition.
+		real.HandleEvent(enableEvent(weight.Omitted))
+		event := handler.Event()
+		require.NotNil(t, event)
+		assert.Equal(t, xevent.Enable, event.Type)
+		assert.Equal(t, true, event.Init.Enable)
+		assert.Equal(t, weight.Weight(1), event.New.Weight)
+		assert.Equal(t, 0, real.State().Transitions)
+	}
+
+	{
+		// The checked real is not affected by the snapshot expiration.
+		real.expireSnapshot()
+		require.Nil(t, handler.Event())
+	}
+}
+
+// TestRestoreSnapshot_Expire tests that the restored state of the real not
+// checked within the trust period is discarded, and that the expired snapshot
+// is not restored at all.
+func TestRestoreSnapshot_Expire(t *testing.T) {
+	handler := &testHandler{}
+	real := defaultReal(1, handler.Handle)
+	WithSnapshot(Snapshot{Alive: true, Weight: 1}, time.Now().Add(time.Hour))(real)
+
+	real.restoreSnapshot()
+	defer real.Stop()
+	require.NotNil(t, handler.Event())
+
+	real.expireSnapshot()
+	event := handler.Event()
+	require.NotNil(t, event)
+	assert.Equal(t, xevent.Disable, event.Type)
+	assert.Equal(t, false, real.State().Alive)
+
+	expired := defaultReal(1, handler.Handle)
+	WithSnapshot(Snapshot{Alive: true, Weight: 1}, time.Now().Add(-time.Second))(expired)
+	expired.restoreSnapshot()
+	require.Nil(t, handler.Event())
+	assert.Equal(t, false, expired.State().Alive)
+}
@@ -0,0 +1,115 @@
+package real
+
+import (
+	"math"
+	"time"
+
+	log "go.uber.org/zap"
+
+	"github.com/yanet-platform/monalive/internal/types/weight"
+)
+
+const (
+	// slowStartLinearSteps is the number of weight updates performed during the
+	// linear ramp.
+	slowStartLinearSteps = 100
+	// slowStartMinTick is the minimal interval between weight updates.
+	slowStartMinTick = 100 * time.Millisecond
+)
+
+// SlowStart represents the state of the weight ramp performed after the real is
+// enabled.
+type SlowStart struct {
+	// Whether the ramp is in progress.
+	Active bool
+	// Start is the time when the ramp started.
+	Start time.Time
+	// Duration of the ramp.
+	Duration time.Duration
+	// Progress is the passed fraction of the ramp in range [0, 1).
+	Progress float64
+	// MinWeight is the weight the ramp starts from.
+	MinWeight weight.Weight
+}
+
+// Weight returns the weight at the current ramp position for the given target
+// weight.
+func (m SlowStart) Weight(target weight.Weight) weight.Weight {
+	if !m.Active || target <= m.MinWeight {
+		return target
+	}
+	return m.MinWeight + weight.Weight(float64(target-m.MinWeight)*m.Progress)
+}
+
+// startSlowStart starts the weight ramp if it is configured and the real has
+// been checked before, so the real is recovering rather than being initially
+// discovered. It assumes the state mutex is already held.
+func (m *Real) startSlowStart() {
+	duration := m.config.GetSlowStart()
+	if duration == 0 || !m.checked {
+		m.state.SlowStart = SlowStart{}
+		return
+	}
+
+	steps := m.config.GetSlowStartSteps()
+	m.state.SlowStart = SlowStart{
+		Active:    true,
+		Start:     time.Now(),
+		Duration:  duration,
+		MinWeight: m.config.GetSlowStartMinWeight(),
+	}
+
+	m.log.Info(
+		"real slow start",
+		log.Duration("duration", duration),
+		log.Int("steps", steps),
+		log.String("event_type", "real update"),
+	)
+
+	// Increment the event counter so that real's Stop function doesn't
+	// complete until the ramp goroutine is finished.
+	m.eventsWG.Add(1)
+	go m.runSlowStart(m.state.SlowStart.Start, duration, steps)
+}
+
+// runSlowStart periodically updates the ramp position until the ramp is
+// finished, canceled or the real is stopped.
+func (m *Real) runSlowStart(start time.Time, duration time.Duration, steps int) {
+	defer m.eventsWG.Done()
+
+	// Linear ramp is performed as a large number of small steps.
+	if steps == 0 {
+		steps = slowStartLinearSteps
+	}
+	tick := max(duration/time.Duration(steps), slowStartMinTick)
+
+	ticker := time.NewTicker(tick)
+	defer ticker.Stop()
+
+	for finished := false; !finished; {
+		select {
+		case <-m.shutdown.Done():
+			return
+		case <-ticker.C:
+		}
+
+		m.updateState(func(state *State) {
+			if !state.SlowStart.Active || !state.SlowStart.Start.Equal(start) {
+				// The ramp is canceled or restarted.
+				finished = true
+				return
+			}
+
+			progress := time.Since(start).Seconds() / duration.Seconds()
+			// Quantize the progress to the configured number of steps.
+			progress = math.Floor(progress*float64(steps)) / float64(steps)
+			if progress >= 1 {
+				// The ramp is finished, so the target weight is applied.
+				state.SlowStart = SlowStart{}
+				finished = true
+				return
+			}
+			state.SlowStart.Progress = progress
+		})
+	}
+}
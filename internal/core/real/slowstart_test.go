@@ -0,0 +1,82 @@
+package real
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yanet-platform/monalive/internal/types/weight"
+	"github.com/yanet-platform/monalive/internal/types/xevent"
+)
+
+// TestSlowStart_Ramp tests that the weight of the recovered real rises in steps
+// from the minimal weight to the target one.
+func TestSlowStart_Ramp(t *testing.T) {
+	events := make(chan *xevent.Event, 10)
+	real := defaultReal(weight.Weight(10), func(event *xevent.Event) { events <- event })
+	defer real.Stop()
+
+	slowStart, steps := 0.2, 2
+	real.config.SlowStart = &slowStart
+	real.config.SlowStartSteps = &steps
+
+	// Initial enable of the real is performed without the ramp.
+	real.HandleEvent(enableEvent(weight.Omitted))
+	assert.Equal(t, weight.Weight(10), (<-events).New.Weight)
+
+	real.HandleEvent(disableEvent())
+	assert.Equal(t, false, (<-events).New.Enable)
+
+	// Recovered real starts from the minimal weight.
+	real.HandleEvent(enableEvent(weight.Omitted))
+	event := <-events
+	assert.Equal(t, true, event.New.Enable)
+	assert.Equal(t, weight.Weight(1), event.New.Weight)
+	assert.True(t, real.State().SlowStart.Active)
+
+	// Then the weight rises to the half of the target and to the target itself.
+	for _, expected := range []weight.Weight{5, 10} {
+		select {
+		case event := <-events:
+			require.Equal(t, xevent.Enable, event.Type)
+			assert.Equal(t, expected, event.New.Weight)
+		case <-time.After(time.Second):
+			t.Fatal("weight ramp stalled")
+		}
+	}
+	assert.False(t, real.State().SlowStart.Active)
+}
+
+// TestSlowStart_Cancel tests that the ramp is canceled once the real is
+// disabled.
+func TestSlowStart_Cancel(t *testing.T) {
+	events := make(chan *xevent.Event, 10)
+	real := defaultReal(weight.Weight(10), func(event *xevent.Event) { events <- event })
+	defer real.Stop()
+
+	slowStart := 0.2
+	real.config.SlowStart = &slowStart
+
+	real.HandleEvent(enableEvent(weight.Omitted))
+	<-events
+	real.HandleEvent(disableEvent())
+	<-events
+	real.HandleEvent(enableEvent(weight.Omitted))
+	<-events
+
+	// Disable the real in the middle of the ramp.
+	real.HandleEvent(disableEvent())
+	for {
+		select {
+		case event := <-events:
+			if event.Type == xevent.Disable {
+				assert.False(t, real.State().SlowStart.Active)
+				return
+			}
+		case <-time.After(time.Second):
+			t.Fatal("disable event is not received")
+		}
+	}
+}
@@ -1,6 +1,9 @@
 package real
 
 import (
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
 	monalivepb "github.com/yanet-platform/monalive/gen/manager"
 )
 
@@ -33,5 +36,46 @@ func (m *Real) Status() *monalivepb.RealStatus {
 		Weight:      state.Weight.Uint32(),
 		Transitions: uint32(state.Transitions),
 		Checkers:    checkerStatus,
+		Override:    state.Override.ProtoMarshaller(),
+		SlowStart:   slowStartStatus(state),
+		Latency:     latencyStatus(state),
+	}
+}
+
+// latencyStatus returns the latency state of the real. If the latency is
+// unknown, it returns nil.
+func latencyStatus(state State) *monalivepb.LatencyStatus {
+	latency := state.Latency
+	if latency.Value == 0 {
+		return nil
+	}
+	return &monalivepb.LatencyStatus{
+		Value:       durationpb.New(latency.Value),
+		Degraded:    latency.Degraded,
+		WeightScale: latencyScale(latency),
+	}
+}
+
+// latencyScale returns the factor the weight is multiplied by due to latency.
+// It returns 1 if the weight is not scaled.
+func latencyScale(latency Latency) float64 {
+	if latency.Scale == nil {
+		return 1
+	}
+	return *latency.Scale
+}
+
+// slowStartStatus returns the status of the weight ramp of the real. If the
+// ramp is not in progress, it returns nil.
+func slowStartStatus(state State) *monalivepb.SlowStartStatus {
+	slowStart := state.SlowStart
+	if !slowStart.Active {
+		return nil
+	}
+	return &monalivepb.SlowStartStatus{
+		Start:    timestamppb.New(slowStart.Start),
+		Duration: durationpb.New(slowStart.Duration),
+		Progress: slowStart.Progress,
+		Weight:   slowStart.Weight(state.Weight).Uint32(),
 	}
 }
@@ -11,6 +11,7 @@ import (
 	"github.com/yanet-platform/monalive/internal/scheduler"
 	"github.com/yanet-platform/monalive/internal/types/key"
 	"github.com/yanet-platform/monalive/internal/types/port"
+	"github.com/yanet-platform/monalive/internal/types/weight"
 	"github.com/yanet-platform/monalive/internal/utils/coalescer"
 	"github.com/yanet-platform/monalive/internal/utils/exp"
 )
@@ -39,12 +40,26 @@ type Config struct {
 	Quorum int `keepalive:"quorum"`
 	// Hysteresis setting for quorum calculations.
 	Hysteresis int `keepalive:"hysteresis"`
+	// QuorumMode defines how the quorum and hysteresis are interpreted: as a
+	// weight (default), a number of alive reals, a percentage of alive reals
+	// or a percentage of the configured total weight of the reals.
+	QuorumMode QuorumMode `keepalive:"quorum_mode"`
 	// Script executed (no) when quorum is achieved.
 	QuorumUp string `keepalive:"quorum_up"`
 	// Script executed (no) when quorum is lost.
 	QuorumDown string `keepalive:"quorum_down"`
 	// The prefix group to which the service belongs.
 	AnnounceGroup string `keepalive:"announce_group"`
+	// Optional aggregate prefix announced instead of the host prefix of the
+	// service. It must contain the VIP of the service.
+	AnnouncePrefix *netip.Prefix `keepalive:"announce_prefix"`
+	// Marks the service as critical for its announce prefix. With the
+	// "critical" prefix policy only the critical services are required to be
+	// up for the prefix to be announced.
+	AnnounceCritical bool `keepalive:"announce_critical"`
+	// Load balancer module the reals of the service are configured in. If not
+	// set, the default module of the load balancer client is used.
+	BalancerModule string `keepalive:"balancer_module"`
 	// Optional virtual host for the service.
 	Virtualhost *string `keepalive:"virtualhost"`
 	// Firewall mark for packet filtering.
@@ -58,6 +73,22 @@ type Config struct {
 	// Optional version identifier of the service config.
 	Version *string `keepalive:"version"`
 
+	// Default slow start duration of the reals in seconds. See [real.Config]
+	// for details.
+	SlowStart *float64 `keepalive:"slow_start"`
+	// Default number of the slow start weight steps of the reals.
+	SlowStartSteps *int `keepalive:"slow_start_steps"`
+	// Default weight the slow start of the reals begins with.
+	SlowStartMinWeight *weight.Weight `keepalive:"slow_start_min_weight"`
+
+	// Default percentage of the weight applied to the degraded reals. See
+	// [real.Config] for details.
+	LatencyDegradedWeight *uint `keepalive:"latency_degraded_weight"`
+	// Default outlier ejection setting of the reals.
+	OutlierEjection *bool `keepalive:"outlier_ejection"`
+	// Default minimal percentage of the weight left to the outliers.
+	OutlierMinWeight *uint `keepalive:"outlier_min_weight"`
+
 	// Embedded scheduler configuration.
 	Scheduler `keepalive_nested:"scheduler"`
 
@@ -155,6 +186,12 @@ func (m *Config) propagate() {
 		real.Retries = coalescer.Coalesce(real.Retries, m.Retries)
 		real.RetryDelay = coalescer.Coalesce(real.RetryDelay, m.RetryDelay)
 		real.Virtualhost = coalescer.Coalesce(real.Virtualhost, m.Virtualhost)
+		real.SlowStart = coalescer.Coalesce(real.SlowStart, m.SlowStart)
+		real.SlowStartSteps = coalescer.Coalesce(real.SlowStartSteps, m.SlowStartSteps)
+		real.SlowStartMinWeight = coalescer.Coalesce(real.SlowStartMinWeight, m.SlowStartMinWeight)
+		real.LatencyDegradedWeight = coalescer.Coalesce(real.LatencyDegradedWeight, m.LatencyDegradedWeight)
+		real.OutlierEjection = coalescer.Coalesce(real.OutlierEjection, m.OutlierEjection)
+		real.OutlierMinWeight = coalescer.Coalesce(real.OutlierMinWeight, m.OutlierMinWeight)
 	}
 }
 
@@ -167,6 +204,10 @@ func (m *Config) validate() (err error) {
 		}
 	}()
 
+	if err := m.QuorumMode.validate(); err != nil {
+		return err
+	}
+
 	if m.AnnounceGroup == "" {
 		m.AnnounceGroup, err = m.announceGroupFromQuorumScript()
 		if err != nil {
@@ -183,3 +183,10 @@ func TestPrepare_ComplexConfig(t *testing.T) {
 	assert.NotNil(t, cfg.Reals[1].RetryDelay)
 	assert.NotNil(t, cfg.Reals[1].Virtualhost)
 }
+
+// TestPrepare_UnknownQuorumMode tests that the unknown quorum mode is rejected.
+func TestPrepare_UnknownQuorumMode(t *testing.T) {
+	config := defaultServiceConfig()
+	config.QuorumMode = "unknown"
+	require.Error(t, config.Prepare())
+}
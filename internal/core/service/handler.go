@@ -4,7 +4,7 @@ import (
 	log "go.uber.org/zap"
 
 	"github.com/yanet-platform/monalive/internal/announcer"
-	"github.com/yanet-platform/monalive/internal/types/weight"
+	"github.com/yanet-platform/monalive/internal/types/override"
 	"github.com/yanet-platform/monalive/internal/types/xevent"
 )
 
@@ -33,8 +33,9 @@ func (m *Service) processEvent(event *xevent.Event) {
 	m.stateMu.Lock()
 	defer m.stateMu.Unlock()
 
-	// Assign the service key to the event.
+	// Assign the service key and the load balancer module to the event.
 	event.Service = m.key
+	event.Module = m.module
 
 	// Process the event based on its type.
 	switch event.Type {
@@ -98,6 +99,8 @@ func (m *Service) updateAnnounce() (changed bool) {
 		log.Bool("alive", m.state.Alive),
 		log.Int("alive_count", m.state.RealsAlive),
 		log.Int("weight", int(m.state.Weight)),
+		log.String("quorum_mode", string(m.config.GetQuorumMode())),
+		log.Int("quorum_value", m.quorumValue()),
 		log.String("event_type", "service update"),
 	)
 
@@ -161,21 +164,33 @@ func (m *Service) disableService() (changed bool) {
 	return true
 }
 
-// quorumState calculates the current quorum state based on the service weight
-// and configured quorum thresholds. Returns the appropriate quorum state.
+// quorumState calculates the current quorum state based on the service state
+// and configured quorum mode and thresholds. Returns the appropriate quorum state.
 func (m *Service) quorumState() quorum {
-	// Get the current weight of the service.
-	w := m.state.Weight
+	// The manual override takes precedence over the quorum.
+	switch m.state.Override.Mode {
+	case override.ForceUp:
+		return quorumUp
+	case override.ForceDown:
+		return quorumDown
+	case override.Drain:
+		// Keep the announce as is, so established flows are not broken.
+		return quorumHold
+	}
+
+	// Get the current value of the service state according to the quorum
+	// mode (weight by default).
+	value := m.quorumValue()
 	// Retrieve quorum and hysteresis values from the config.
 	quorum, hysteresis := m.config.Quorum, m.config.Hysteresis
 
-	// Determine the quorum state based on the weight.
+	// Determine the quorum state based on the value.
 	switch {
-	case w >= weight.Weight(quorum+hysteresis):
-		// If the weight exceeds the quorum + hysteresis, the quorum is up.
+	case value >= quorum+hysteresis:
+		// If the value exceeds the quorum + hysteresis, the quorum is up.
 		return quorumUp
-	case w < weight.Weight(quorum-hysteresis) || w == 0:
-		// If the weight is below the quorum - hysteresis or zero, the quorum is
+	case value < quorum-hysteresis || value == 0:
+		// If the value is below the quorum - hysteresis or zero, the quorum is
 		// down.
 		return quorumDown
 	default:
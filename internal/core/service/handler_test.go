@@ -8,6 +8,8 @@ import (
 
 	"github.com/yanet-platform/monalive/internal/announcer"
 	"github.com/yanet-platform/monalive/internal/balancer"
+	"github.com/yanet-platform/monalive/internal/core/real"
+	"github.com/yanet-platform/monalive/internal/monitoring/metrics"
 	"github.com/yanet-platform/monalive/internal/types/weight"
 	"github.com/yanet-platform/monalive/internal/types/xevent"
 )
@@ -19,8 +21,8 @@ func defaultService() *Service {
 		Quorum:     1,
 		Hysteresis: 0,
 	}
-	announcer := announcer.New(&announcer.Config{}, nil, log.NewNop())
-	balancer := balancer.New(&balancer.Config{}, nil, announcer, log.NewNop())
+	announcer := announcer.New(&announcer.Config{}, nil, &metrics.NopProvider{}, log.NewNop())
+	balancer := balancer.New(&balancer.Config{}, nil, announcer, &metrics.NopProvider{}, log.NewNop())
 	return New(serviceConfig, announcer, balancer, log.NewNop())
 }
 
@@ -286,3 +288,65 @@ func TestHandleEvent_QuorumHold(t *testing.T) {
 		assert.Equal(t, 1, state.RealsAlive)
 	}
 }
+
+// TestHandleEvent_QuorumAliveCount verifies that in the alive count quorum mode
+// the service state depends on the number of alive reals regardless of their
+// weights.
+func TestHandleEvent_QuorumAliveCount(t *testing.T) {
+	service := defaultService()
+	service.config.QuorumMode = QuorumAliveCount
+	service.config.Quorum = 2
+
+	service.HandleEvent(enableRealEvent(weight.Weight(100)))
+	assert.Equal(t, false, service.State().Alive)
+
+	service.HandleEvent(enableRealEvent(weight.Weight(1)))
+	assert.Equal(t, true, service.State().Alive)
+
+	service.HandleEvent(disableRealEvent(weight.Weight(1)))
+	assert.Equal(t, false, service.State().Alive)
+}
+
+// TestHandleEvent_QuorumPercent verifies that in the percentage quorum modes
+// the service state depends on the share of alive reals or their weight
+// relative to the configured reals.
+func TestHandleEvent_QuorumPercent(t *testing.T) {
+	reals := []*real.Config{
+		{Weight: 10},
+		{Weight: 10},
+		{Weight: 20},
+		{Weight: 60},
+	}
+
+	{
+		service := defaultService()
+		service.config.QuorumMode = QuorumAlivePercent
+		service.config.Quorum = 50
+		service.config.Reals = reals
+
+		service.HandleEvent(enableRealEvent(weight.Weight(60)))
+		assert.Equal(t, 25, service.quorumValueLocked())
+		assert.Equal(t, false, service.State().Alive)
+
+		service.HandleEvent(enableRealEvent(weight.Weight(10)))
+		assert.Equal(t, 50, service.quorumValueLocked())
+		assert.Equal(t, true, service.State().Alive)
+	}
+
+	{
+		service := defaultService()
+		service.config.QuorumMode = QuorumWeightPercent
+		service.config.Quorum = 50
+		service.config.Reals = reals
+
+		service.HandleEvent(enableRealEvent(weight.Weight(10)))
+		service.HandleEvent(enableRealEvent(weight.Weight(10)))
+		service.HandleEvent(enableRealEvent(weight.Weight(20)))
+		assert.Equal(t, 40, service.quorumValueLocked())
+		assert.Equal(t, false, service.State().Alive)
+
+		service.HandleEvent(enableRealEvent(weight.Weight(60)))
+		assert.Equal(t, 100, service.quorumValueLocked())
+		assert.Equal(t, true, service.State().Alive)
+	}
+}
@@ -15,7 +15,8 @@ type Metrics struct {
 	realsTransitionPeriod metrics.Histogram
 	realsResponseTime     metrics.Histogram
 
-	realsErrors metrics.CounterVec
+	realsErrors        metrics.CounterVec
+	realsInvalidWeight metrics.Counter
 
 	isBlocked bool
 	mu        sync.Mutex
@@ -29,7 +30,8 @@ func NewMetrics() *Metrics {
 		realsTransitionPeriod: &metrics.NopHistogram{},
 		realsResponseTime:     &metrics.NopHistogram{},
 
-		realsErrors: &metrics.NopCounterVec{},
+		realsErrors:        &metrics.NopCounterVec{},
+		realsInvalidWeight: &metrics.NopCounter{},
 	}
 
 	return m
@@ -55,6 +57,10 @@ func (m *Metrics) RealsErrors() metrics.CounterVec {
 	return m.realsErrors
 }
 
+func (m *Metrics) RealsInvalidWeight() metrics.Counter {
+	return m.realsInvalidWeight
+}
+
 func (m *Metrics) Block() {
 	m.mu.Lock()
 	defer m.mu.Unlock()
@@ -112,3 +118,13 @@ func SetRealsErrorsMetric(counterVec metrics.CounterVec) SetMetricFunc {
 		}
 	}
 }
+
+func SetRealsInvalidWeightMetric(counter metrics.Counter) SetMetricFunc {
+	return func(m *Metrics) {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		if !m.isBlocked {
+			m.realsInvalidWeight = counter
+		}
+	}
+}
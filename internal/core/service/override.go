@@ -0,0 +1,94 @@
+package service
+
+import (
+	"errors"
+
+	log "go.uber.org/zap"
+
+	"github.com/yanet-platform/monalive/internal/types/key"
+	"github.com/yanet-platform/monalive/internal/types/override"
+)
+
+// ErrRealNotFound is an error that indicates the requested real does not belong
+// to the service.
+var ErrRealNotFound = errors.New("real not found")
+
+// SetOverride sets the manual override of the service. The override is
+// propagated to all reals of the service that do not have their own override.
+// Setting the override with [override.Auto] mode removes it.
+func (m *Service) SetOverride(newOverride override.Override) {
+	m.stateMu.Lock()
+	// Cancel the expiration of the previous override.
+	if m.overrideTimer != nil {
+		m.overrideTimer.Stop()
+	}
+	// Schedule the reset of the new override once it expires.
+	m.overrideTimer = newOverride.AfterExpire(func() {
+		m.expireOverride(newOverride)
+	})
+	m.state.Override = newOverride
+	m.stateMu.Unlock()
+
+	m.log.Info(
+		"service override set",
+		log.String("mode", string(newOverride.Mode)),
+		log.String("reason", newOverride.Reason),
+		log.Time("expires", newOverride.Expires),
+		log.String("event_type", "service update"),
+	)
+
+	// Propagate the override to the reals.
+	m.realsMu.Lock()
+	for _, real := range m.reals {
+		real.SetServiceOverride(newOverride)
+	}
+	m.realsMu.Unlock()
+
+	// The override might force the quorum state, so update the service
+	// announce.
+	m.processAnnounce()
+}
+
+// SetRealOverride sets the manual override of the real with the given key.
+// Returns [ErrRealNotFound] if the real does not belong to the service.
+func (m *Service) SetRealOverride(realKey key.Real, newOverride override.Override) error {
+	m.realsMu.Lock()
+	defer m.realsMu.Unlock()
+
+	real, exists := m.reals[realKey]
+	if !exists {
+		return ErrRealNotFound
+	}
+	real.SetOverride(newOverride)
+	return nil
+}
+
+// Overrides returns the active overrides of the service and its reals.
+func (m *Service) Overrides() (serviceOverride override.Override, realOverrides map[key.Real]override.Override) {
+	m.realsMu.Lock()
+	defer m.realsMu.Unlock()
+
+	realOverrides = make(map[key.Real]override.Override)
+	for key, real := range m.reals {
+		if realOverride := real.State().Override; realOverride.Active() {
+			realOverrides[key] = realOverride
+		}
+	}
+	// Overrides of the reals that are not created yet are still valid.
+	for key, realOverride := range m.restoredOverride {
+		if _, exists := realOverrides[key]; !exists {
+			realOverrides[key] = realOverride
+		}
+	}
+
+	return m.State().Override, realOverrides
+}
+
+// expireOverride removes the given override of the service if it has not been
+// replaced since.
+func (m *Service) expireOverride(expired override.Override) {
+	if m.State().Override != expired {
+		return
+	}
+	m.SetOverride(override.Override{})
+}
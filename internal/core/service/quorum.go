@@ -0,0 +1,79 @@
+package service
+
+import (
+	"fmt"
+)
+
+// QuorumMode defines how the service state is compared against the quorum.
+type QuorumMode string
+
+const (
+	// QuorumWeight compares the summed weight of the alive reals against the
+	// quorum. This is the default mode.
+	QuorumWeight QuorumMode = "weight"
+	// QuorumAliveCount compares the number of the alive reals against the
+	// quorum.
+	QuorumAliveCount QuorumMode = "alive_count"
+	// QuorumAlivePercent compares the percentage of the alive reals against
+	// the quorum.
+	QuorumAlivePercent QuorumMode = "alive_percent"
+	// QuorumWeightPercent compares the summed weight of the alive reals as a
+	// percentage of the configured total weight of the reals against the
+	// quorum.
+	QuorumWeightPercent QuorumMode = "weight_percent"
+)
+
+// validate checks that the quorum mode is known.
+func (m QuorumMode) validate() error {
+	switch m {
+	case "", QuorumWeight, QuorumAliveCount, QuorumAlivePercent, QuorumWeightPercent:
+		return nil
+	default:
+		return fmt.Errorf("unknown quorum mode: %s", m)
+	}
+}
+
+// GetQuorumMode returns the quorum mode of the service.
+func (m *Config) GetQuorumMode() QuorumMode {
+	if m.QuorumMode == "" {
+		return QuorumWeight
+	}
+	return m.QuorumMode
+}
+
+// quorumValue returns the current value of the service state compared against
+// the quorum according to the configured quorum mode. It assumes the state
+// mutex is already held.
+func (m *Service) quorumValue() int {
+	switch m.config.GetQuorumMode() {
+	case QuorumAliveCount:
+		return m.state.RealsAlive
+
+	case QuorumAlivePercent:
+		total := len(m.config.Reals)
+		if total == 0 {
+			return 0
+		}
+		return m.state.RealsAlive * 100 / total
+
+	case QuorumWeightPercent:
+		total := 0
+		for _, real := range m.config.Reals {
+			total += max(int(real.Weight), 0)
+		}
+		if total == 0 {
+			return 0
+		}
+		return int(m.state.Weight) * 100 / total
+
+	default:
+		return int(m.state.Weight)
+	}
+}
+
+// quorumValueLocked is the same as quorumValue, but acquires the state mutex.
+func (m *Service) quorumValueLocked() int {
+	m.stateMu.RLock()
+	defer m.stateMu.RUnlock()
+	return m.quorumValue()
+}
@@ -6,13 +6,16 @@ package service
 import (
 	"context"
 	"sync"
+	"time"
 
 	log "go.uber.org/zap"
 
 	"github.com/yanet-platform/monalive/internal/announcer"
 	"github.com/yanet-platform/monalive/internal/balancer"
+	"github.com/yanet-platform/monalive/internal/core/limiter"
 	"github.com/yanet-platform/monalive/internal/core/real"
 	"github.com/yanet-platform/monalive/internal/types/key"
+	"github.com/yanet-platform/monalive/internal/types/override"
 	"github.com/yanet-platform/monalive/internal/types/weight"
 	"github.com/yanet-platform/monalive/internal/utils/shutdown"
 	"github.com/yanet-platform/monalive/internal/utils/workerpool"
@@ -24,17 +27,29 @@ import (
 type Service struct {
 	config *Config
 	key    key.Service // used in events to set one-to-one correspondence to the current service
+	module string      // load balancer module of the reals, it is fixed for the service lifetime
 
 	announcer *announcer.Announcer // to update annouce status (enable/disable) of current service
 	balancer  *balancer.Balancer   // updates real servers state in the load balancer according health check results
 
+	checkLimiter *limiter.Limiter // to obtain the queue of checks of the service, optional
+	checkQueue   *limiter.Queue   // shared by all checkers of the service to limit the checks
+
 	reals     map[key.Real]*real.Real // current reals mapped by their unique [key.Real]
 	realsMu   sync.Mutex              // to protect concurent access to the reals map
 	realsPool *workerpool.Pool
 
+	latencyTracker *real.LatencyTracker // shared by the reals to detect outliers
+
 	state   State        // current state of the service
 	stateMu sync.RWMutex // to protect concurent access to the state
 
+	overrideTimer    *time.Timer                    // resets the override of the service when it expires
+	restoredOverride map[key.Real]override.Override // overrides applied to the reals once they are created
+
+	restoredSnapshots map[key.Real]real.Snapshot // states restored by the reals once they are created
+	snapshotsExpires  time.Time                  // time the restored states are trusted until
+
 	eventsWG sync.WaitGroup // to manage goroutines handling events
 
 	metrics *Metrics
@@ -49,10 +64,44 @@ type State struct {
 	Weight      weight.Weight
 	RealsAlive  int
 	Transitions int
+
+	// Override is the manual override of the service set via API. It is
+	// propagated to all reals of the service.
+	Override override.Override
+}
+
+// Option represents a function that configures a Service instance.
+type Option func(*Service)
+
+// WithCheckLimiter returns an Option that sets the limiter of the health checks
+// performed by the reals of the service.
+func WithCheckLimiter(limiter *limiter.Limiter) Option {
+	return func(m *Service) {
+		m.checkLimiter = limiter
+	}
+}
+
+// WithOverrides returns an Option that restores the overrides of the service
+// and its reals. Overrides of the reals are applied once the reals are created.
+func WithOverrides(serviceOverride override.Override, realOverrides map[key.Real]override.Override) Option {
+	return func(m *Service) {
+		m.state.Override = serviceOverride
+		m.restoredOverride = realOverrides
+	}
+}
+
+// WithSnapshots returns an Option that restores the states of the reals
+// persisted before the restart. The states are trusted until the expiration
+// time and are applied once the reals are created.
+func WithSnapshots(snapshots map[key.Real]real.Snapshot, expires time.Time) Option {
+	return func(m *Service) {
+		m.restoredSnapshots = snapshots
+		m.snapshotsExpires = expires
+	}
 }
 
 // New creates a new Service instance.
-func New(config *Config, announcer *announcer.Announcer, balancer *balancer.Balancer, logger *log.Logger) *Service {
+func New(config *Config, announcer *announcer.Announcer, balancer *balancer.Balancer, logger *log.Logger, opts ...Option) *Service {
 	logger = logger.With(
 		log.String("virtual_ip", config.VIP.String()),
 		log.String("port", config.VPort.String()),
@@ -60,9 +109,10 @@ func New(config *Config, announcer *announcer.Announcer, balancer *balancer.Bala
 	)
 	defer logger.Info("service created", log.String("event_type", "service update"))
 
-	return &Service{
+	service := &Service{
 		config: config,
 		key:    config.Key(),
+		module: config.BalancerModule,
 
 		announcer: announcer,
 		balancer:  balancer,
@@ -70,11 +120,30 @@ func New(config *Config, announcer *announcer.Announcer, balancer *balancer.Bala
 		reals:     make(map[key.Real]*real.Real),
 		realsPool: workerpool.New(),
 
+		latencyTracker: real.NewLatencyTracker(),
+
 		metrics: NewMetrics(),
 
 		shutdown: shutdown.New(),
 		log:      logger,
 	}
+
+	// Apply optional configurations.
+	for _, opt := range opts {
+		opt(service)
+	}
+
+	// Schedule the expiration of the restored override.
+	restored := service.state.Override
+	service.overrideTimer = restored.AfterExpire(func() {
+		service.expireOverride(restored)
+	})
+
+	// Create the queue of checks of the service. Nil limiter produces nil
+	// queue which does not limit anything.
+	service.checkQueue = service.checkLimiter.Queue(service.key, config.AnnounceGroup)
+
+	return service
 }
 
 // Run starts the service.
@@ -106,6 +175,10 @@ func (m *Service) Reload(config *Config) {
 	m.realsMu.Lock()
 	defer m.realsMu.Unlock()
 
+	// Announce group might be changed, so update the scope of the checks
+	// queue.
+	m.checkQueue.SetGroup(config.AnnounceGroup)
+
 	// Prepare a new map to hold the new set of reals.
 	// This map will eventually replace the existing reals map.
 	newReals := make(map[key.Real]*real.Real)
@@ -136,19 +209,36 @@ func (m *Service) Reload(config *Config) {
 				// If the real doesn't exist in the current reals map, it's a
 				// new real. Create a new real instance with the provided
 				// configuration.
-				var realOpts []real.Option
+				realOpts := []real.Option{real.WithLatencyTracker(m.latencyTracker)}
 				if m.balancer.SupportsState() {
 					realOpts = append(
 						realOpts,
 						real.WithActivationFunc(m.realActivationFunc(key)),
 					)
 				}
+				if m.checkQueue != nil {
+					realOpts = append(realOpts, real.WithCheckLimiter(m.checkQueue))
+				}
+				if snapshot, exists := m.restoredSnapshots[key]; exists {
+					realOpts = append(realOpts, real.WithSnapshot(snapshot, m.snapshotsExpires))
+					delete(m.restoredSnapshots, key)
+				}
 				newReal := real.New(cfg, m.HandleEvent, m.log, realOpts...)
 				newReal.SetMetrics(
 					real.SetRealErrorsMetric(m.metrics.RealsErrors()),
+					real.SetRealInvalidWeightMetric(m.metrics.RealsInvalidWeight()),
 					real.SetRealTransitionPeriodMetric(m.metrics.RealsTransitionPeriod()),
 					real.SetRealResponseTimeMetric(m.metrics.RealsResponseTime()),
 				)
+				// Propagate the service override to the new real and restore
+				// its own override if any.
+				if serviceOverride := m.State().Override; serviceOverride.Active() {
+					newReal.SetServiceOverride(serviceOverride)
+				}
+				if realOverride, exists := m.restoredOverride[key]; exists {
+					newReal.SetOverride(realOverride)
+					delete(m.restoredOverride, key)
+				}
 				// Add the new real to the new reals map.
 				newReals[key] = newReal
 				// Add new real to the pool.
@@ -173,6 +263,10 @@ func (m *Service) Reload(config *Config) {
 	// updated set of reals and update service config.
 	m.reals = newReals
 	m.config = config
+	// All reals are created, so overrides of the reals missing in the config
+	// are discarded along with their restored states.
+	m.restoredOverride = nil
+	m.restoredSnapshots = nil
 
 	// It is neccesary to process the status of the service announce after
 	// reload due to possible changes in the announce settings.
@@ -185,6 +279,13 @@ func (m *Service) Stop() {
 	// Trigger the shutdown signal to gracefully stop workers.
 	m.shutdown.Do()
 
+	// Cancel the pending override expiration.
+	m.stateMu.Lock()
+	if m.overrideTimer != nil {
+		m.overrideTimer.Stop()
+	}
+	m.stateMu.Unlock()
+
 	// Lock the reals mutex to ensure thread-safe access.
 	m.realsMu.Lock()
 	defer m.realsMu.Unlock()
@@ -200,6 +301,21 @@ func (m *Service) Stop() {
 	m.realsPool.Close()
 }
 
+// Snapshots returns the snapshots of the states of the reals enabled in the
+// load balancer.
+func (m *Service) Snapshots() map[key.Real]real.Snapshot {
+	m.realsMu.Lock()
+	defer m.realsMu.Unlock()
+
+	snapshots := make(map[key.Real]real.Snapshot)
+	for realKey, real := range m.reals {
+		if snapshot := real.Snapshot(); snapshot.Enabled() {
+			snapshots[realKey] = snapshot
+		}
+	}
+	return snapshots
+}
+
 // State returns a snapshot of the current service state.
 func (m *Service) State() State {
 	// Acquire a read lock on the state.
@@ -218,10 +334,16 @@ func (m *Service) Key() key.Service {
 	return m.key
 }
 
+// Module returns the load balancer module the reals of the service are
+// configured in.
+func (m *Service) Module() string {
+	return m.module
+}
+
 // realActivationFunc returns an activation function for a given real.
 func (m *Service) realActivationFunc(real key.Real) real.ActivationFunc {
 	return func(ctx context.Context) (activated bool) {
-		balancerKey := key.Balancer{Service: m.key, Real: real}
+		balancerKey := key.Balancer{Service: m.key, Real: real, Module: m.module}
 		subscription := m.balancer.LookupSubscription(ctx, balancerKey)
 
 		if subscription == nil {
@@ -4,6 +4,16 @@ import (
 	monalivepb "github.com/yanet-platform/monalive/gen/manager"
 )
 
+// announcePrefix returns the aggregate announce prefix of the service, or nil if
+// the service is announced with its host prefix.
+func announcePrefix(config *Config) *string {
+	if config.AnnouncePrefix == nil {
+		return nil
+	}
+	prefix := config.AnnouncePrefix.String()
+	return &prefix
+}
+
 // Status retrieves the current status of all reals managed by this service. It
 // returns [monalivepb.ServiceStatus] messages representing the status of the
 // service and its reals.
@@ -32,14 +42,20 @@ func (m *Service) Status() *monalivepb.ServiceStatus {
 		Protocol:               m.config.Protocol,
 		LvsMethod:              m.config.ForwardingMethod,
 		QuorumState:            alive,
+		QuorumMode:             string(m.config.GetQuorumMode()),
+		Quorum:                 int32(m.config.Quorum),
+		Hysteresis:             int32(m.config.Hysteresis),
+		QuorumValue:            int32(m.quorumValueLocked()),
 		AliveWeight:            state.Weight.Uint32(),
 		AliveCount:             uint32(state.RealsAlive),
 		Transitions:            uint32(state.Transitions),
 		Fwmark:                 uint32(m.config.FwMark),
 		AnnounceGroup:          &m.config.AnnounceGroup,
+		AnnouncePrefix:         announcePrefix(m.config),
 		Ipv4OuterSourceNetwork: m.config.IPv4OuterSourceNetwork,
 		Ipv6OuterSourceNetwork: m.config.IPv6OuterSourceNetwork,
 		Rs:                     realStatus,
+		Override:               state.Override.ProtoMarshaller(),
 
 		// TODO: REMOVE
 		QuorumUp: m.config.QuorumUp,
@@ -0,0 +1,145 @@
+package core
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/netip"
+	"os"
+	"time"
+
+	"github.com/yanet-platform/monalive/internal/core/real"
+	"github.com/yanet-platform/monalive/internal/types/key"
+	"github.com/yanet-platform/monalive/internal/types/port"
+	"github.com/yanet-platform/monalive/internal/types/weight"
+)
+
+// RealSnapshots holds the snapshots of the states of the service reals.
+type RealSnapshots = map[key.Real]real.Snapshot
+
+// Snapshots returns the snapshots of the states of the reals enabled in the
+// load balancer.
+func (m *Core) Snapshots() map[key.Service]RealSnapshots {
+	m.servicesMu.Lock()
+	defer m.servicesMu.Unlock()
+
+	snapshots := make(map[key.Service]RealSnapshots)
+	for serviceKey, service := range m.services {
+		if reals := service.Snapshots(); len(reals) > 0 {
+			snapshots[serviceKey] = reals
+		}
+	}
+	return snapshots
+}
+
+// RestoreSnapshots sets the states of the reals to be restored once they are
+// created. The states are trusted until the expiration time. It is expected to
+// be called before the first reload.
+func (m *Core) RestoreSnapshots(snapshots map[key.Service]RealSnapshots, expires time.Time) {
+	m.servicesMu.Lock()
+	defer m.servicesMu.Unlock()
+	m.restoredSnapshots = snapshots
+	m.snapshotsExpires = expires
+}
+
+// snapshotsFile represents the file the states of the reals are persisted to.
+type snapshotsFile struct {
+	// Saved is the time the states are persisted at.
+	Saved time.Time        `json:"saved"`
+	Reals []snapshotRecord `json:"reals"`
+}
+
+// snapshotRecord represents the state of a single real stored in the states
+// file.
+type snapshotRecord struct {
+	VIP       netip.Addr `json:"vip"`
+	Port      *uint32    `json:"port,omitempty"`
+	Protocol  string     `json:"protocol"`
+	RealIP    netip.Addr `json:"real_ip"`
+	RealPort  *uint32    `json:"real_port,omitempty"`
+	Alive     bool       `json:"alive"`
+	Weight    int        `json:"weight"`
+	Inhibited bool       `json:"inhibited,omitempty"`
+}
+
+// dumpSnapshots saves the states of the reals to the file at the specified
+// path.
+func dumpSnapshots(path string, snapshots map[key.Service]RealSnapshots, saved time.Time) error {
+	file := snapshotsFile{
+		Saved: saved,
+		Reals: []snapshotRecord{},
+	}
+	for serviceKey, reals := range snapshots {
+		for realKey, snapshot := range reals {
+			file.Reals = append(file.Reals, snapshotRecord{
+				VIP:       serviceKey.Addr,
+				Port:      serviceKey.Port.ProtoMarshaller(),
+				Protocol:  serviceKey.Proto,
+				RealIP:    realKey.Addr,
+				RealPort:  realKey.Port.ProtoMarshaller(),
+				Alive:     snapshot.Alive,
+				Weight:    int(snapshot.Weight),
+				Inhibited: snapshot.Inhibited,
+			})
+		}
+	}
+
+	return dumpJSON(path, file)
+}
+
+// loadSnapshots loads the states of the reals from the file at the specified
+// path along with the time the states are trusted until. The states saved
+// longer than the trust period ago are not returned. If the file does not
+// exist, no states are returned.
+func loadSnapshots(path string, trustPeriod time.Duration) (snapshots map[key.Service]RealSnapshots, expires time.Time, err error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, time.Time{}, nil
+	}
+	if err != nil {
+		return nil, time.Time{}, fmt.Errorf("failed to read: %w", err)
+	}
+
+	var file snapshotsFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, time.Time{}, fmt.Errorf("failed to unmarshal: %w", err)
+	}
+
+	expires = file.Saved.Add(trustPeriod)
+	if !time.Now().Before(expires) {
+		// The states are too old to be trusted.
+		return nil, time.Time{}, nil
+	}
+
+	snapshots = make(map[key.Service]RealSnapshots)
+	for _, record := range file.Reals {
+		servicePort, err := port.ProtoUnmarshaller(record.Port)
+		if err != nil {
+			return nil, time.Time{}, err
+		}
+		realPort, err := port.ProtoUnmarshaller(record.RealPort)
+		if err != nil {
+			return nil, time.Time{}, err
+		}
+		serviceKey := key.Service{
+			Addr:  record.VIP,
+			Port:  servicePort,
+			Proto: record.Protocol,
+		}
+		realKey := key.Real{
+			Addr: record.RealIP,
+			Port: realPort,
+		}
+
+		if snapshots[serviceKey] == nil {
+			snapshots[serviceKey] = make(RealSnapshots)
+		}
+		snapshots[serviceKey][realKey] = real.Snapshot{
+			Alive:     record.Alive,
+			Weight:    weight.Weight(record.Weight),
+			Inhibited: record.Inhibited,
+		}
+	}
+
+	return snapshots, expires, nil
+}
@@ -0,0 +1,45 @@
+package core
+
+import (
+	"net/netip"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yanet-platform/monalive/internal/types/key"
+	"github.com/yanet-platform/monalive/internal/types/port"
+)
+
+// TestSnapshots_DumpLoad tests that the persisted states of the reals are
+// restored within the trust period only.
+func TestSnapshots_DumpLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "states.json")
+	serviceKey := key.Service{Addr: netip.MustParseAddr("10.0.0.1"), Port: port.Omitted, Proto: "TCP"}
+	snapshots := map[key.Service]RealSnapshots{
+		serviceKey: {
+			{Addr: netip.MustParseAddr("10.1.0.1"), Port: 80}:           {Alive: true, Weight: 3},
+			{Addr: netip.MustParseAddr("10.1.0.2"), Port: port.Omitted}: {Inhibited: true},
+		},
+	}
+
+	saved := time.Now().Add(-time.Minute)
+	require.NoError(t, dumpSnapshots(path, snapshots, saved))
+
+	loaded, expires, err := loadSnapshots(path, time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, snapshots, loaded)
+	assert.WithinDuration(t, saved.Add(time.Hour), expires, time.Millisecond)
+
+	// The states saved longer than the trust period ago are not restored.
+	loaded, _, err = loadSnapshots(path, time.Second)
+	require.NoError(t, err)
+	assert.Empty(t, loaded)
+
+	// The missing file means no states.
+	loaded, _, err = loadSnapshots(filepath.Join(t.TempDir(), "missing.json"), time.Hour)
+	require.NoError(t, err)
+	assert.Empty(t, loaded)
+}
@@ -1,7 +1,16 @@
 package core
 
 import (
+	"context"
+	"slices"
+	"time"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+
 	monalivepb "github.com/yanet-platform/monalive/gen/manager"
+	"github.com/yanet-platform/monalive/internal/announcer"
+	"github.com/yanet-platform/monalive/internal/balancer"
+	"github.com/yanet-platform/monalive/internal/types/key"
 )
 
 // Status retrieves the current status of all services managed by the Core
@@ -22,3 +31,120 @@ func (m *Core) Status() []*monalivepb.ServiceStatus {
 	// Return the collected service status information.
 	return status
 }
+
+// PrefixStatus retrieves the current readiness of all announced prefixes. For
+// the unready prefixes it lists the disabled services blocking the announce.
+func (m *Core) PrefixStatus() []*monalivepb.PrefixStatus {
+	prefixes := m.announcer.Prefixes()
+	status := make([]*monalivepb.PrefixStatus, 0, len(prefixes))
+	for _, prefix := range prefixes {
+		blocking := make([]string, 0, len(prefix.Blocking))
+		for _, service := range prefix.Blocking {
+			blocking = append(blocking, service.String())
+		}
+		status = append(status, &monalivepb.PrefixStatus{
+			Prefix:        prefix.Prefix.String(),
+			AnnounceGroup: prefix.Group,
+			Policy:        prefix.Policy.String(),
+			Ready:         prefix.Status == announcer.Ready,
+			Blocking:      blocking,
+		})
+	}
+	return status
+}
+
+// Announces retrieves the current state of all known prefixes together with
+// the states delivered to the external announcer.
+func (m *Core) Announces() []*monalivepb.AnnounceStatus {
+	announces := m.announcer.Announces()
+	status := make([]*monalivepb.AnnounceStatus, 0, len(announces))
+	for _, announce := range announces {
+		services := make([]key.Service, 0, len(announce.Services))
+		for service := range announce.Services {
+			services = append(services, service)
+		}
+		slices.SortFunc(services, key.Service.Compare)
+
+		servicesStatus := make([]*monalivepb.AnnounceServiceStatus, 0, len(services))
+		for _, service := range services {
+			servicesStatus = append(servicesStatus, &monalivepb.AnnounceServiceStatus{
+				Vip:      service.Addr.String(),
+				Port:     service.Port.ProtoMarshaller(),
+				Protocol: service.Proto,
+				Enabled:  announce.Services[service] == announcer.ServiceEnabled,
+			})
+		}
+
+		announceStatus := &monalivepb.AnnounceStatus{
+			Prefix:        announce.Prefix.String(),
+			AnnounceGroup: announce.Group,
+			Policy:        announce.Policy.String(),
+			Ready:         announce.Status == announcer.Ready,
+			Services:      servicesStatus,
+			Announced:     announce.Announced == announcer.Ready,
+			Pinned:        announce.Pinned,
+		}
+		if !announce.Changed.IsZero() {
+			announceStatus.LastChange = timestamppb.New(announce.Changed)
+		}
+		if !announce.Sent.IsZero() {
+			announceStatus.LastSend = timestamppb.New(announce.Sent)
+		}
+		status = append(status, announceStatus)
+	}
+	return status
+}
+
+// BalancerConnection retrieves the health of the connection to the load
+// balancer. It returns nil if the load balancer client does not track it.
+func (m *Core) BalancerConnection() *monalivepb.BalancerConnection {
+	health, known := m.balancer.Health()
+	if !known {
+		return nil
+	}
+	return &monalivepb.BalancerConnection{
+		Connected: health.Connected,
+		LastError: health.LastError,
+		Since:     timestamppb.New(health.Since),
+	}
+}
+
+// BalancerDrift retrieves the real servers whose state in the load balancer
+// differs from the state applied by Monalive, along with the time of the load
+// balancer state synchronization they are detected by.
+func (m *Core) BalancerDrift() (drifts []*monalivepb.RealDrift, synced time.Time) {
+	drift, synced := m.balancer.Drift()
+	return realDrifts(drift), synced
+}
+
+// ShadowDiff retrieves the real servers whose state decided by Monalive in the
+// shadow mode differs from their state in the live load balancer.
+func (m *Core) ShadowDiff(ctx context.Context) ([]*monalivepb.RealDrift, error) {
+	drift, err := m.balancer.Compare(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return realDrifts(drift), nil
+}
+
+// realDrifts converts the drifted real servers to their protobuf
+// representation.
+func realDrifts(drift []balancer.Drift) []*monalivepb.RealDrift {
+	drifts := make([]*monalivepb.RealDrift, 0, len(drift))
+	for _, real := range drift {
+		drifts = append(drifts, &monalivepb.RealDrift{
+			Module:          real.Key.Module,
+			Vip:             real.Key.Service.Addr.String(),
+			Port:            real.Key.Service.Port.ProtoMarshaller(),
+			Protocol:        real.Key.Service.Proto,
+			RealIp:          real.Key.Real.Addr.String(),
+			RealPort:        real.Key.Real.Port.ProtoMarshaller(),
+			Enabled:         real.Desired.Enable,
+			Weight:          uint32(real.Desired.Weight),
+			BalancerEnabled: real.Actual.Enabled,
+			BalancerWeight:  uint32(real.Actual.Weight),
+			Detected:        timestamppb.New(real.Detected),
+		})
+	}
+	return drifts
+}
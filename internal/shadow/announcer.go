@@ -0,0 +1,56 @@
+package shadow
+
+import (
+	"cmp"
+	"net/netip"
+	"slices"
+
+	"github.com/yanet-platform/monalive/internal/announcer"
+)
+
+// AnnouncerClient is an implementation of the external announcer client
+// recording the announce decisions of Monalive instead of applying them.
+type AnnouncerClient struct {
+	recorder *Recorder
+}
+
+// NewAnnouncerClient creates a new AnnouncerClient recording the decisions
+// with the recorder.
+func NewAnnouncerClient(recorder *Recorder) *AnnouncerClient {
+	return &AnnouncerClient{recorder: recorder}
+}
+
+// RaiseAnnounce records the decision to announce the prefix in the group.
+func (m *AnnouncerClient) RaiseAnnounce(group string, prefix netip.Prefix) error {
+	m.recorder.Record(announceRecord(ActionAnnounce, group, prefix))
+	return nil
+}
+
+// RemoveAnnounce records the decision to withdraw the prefix in the group.
+func (m *AnnouncerClient) RemoveAnnounce(group string, prefix netip.Prefix) error {
+	m.recorder.Record(announceRecord(ActionWithdraw, group, prefix))
+	return nil
+}
+
+// ProcessBatch records the decisions of the batch ordered by prefix.
+func (m *AnnouncerClient) ProcessBatch(group string, prefixes map[netip.Prefix]announcer.PrefixStatus) error {
+	ordered := make([]netip.Prefix, 0, len(prefixes))
+	for prefix := range prefixes {
+		ordered = append(ordered, prefix)
+	}
+	slices.SortFunc(ordered, func(a, b netip.Prefix) int {
+		return cmp.Or(a.Addr().Compare(b.Addr()), cmp.Compare(a.Bits(), b.Bits()))
+	})
+
+	for _, prefix := range ordered {
+		action := ActionWithdraw
+		if prefixes[prefix] == announcer.Ready {
+			action = ActionAnnounce
+		}
+		m.recorder.Record(announceRecord(action, group, prefix))
+	}
+	return nil
+}
+
+// Shutdown does nothing, as no announces are made.
+func (m *AnnouncerClient) Shutdown() {}
@@ -0,0 +1,113 @@
+package shadow
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/yanet-platform/monalive/internal/balancer"
+	"github.com/yanet-platform/monalive/internal/types/key"
+	"github.com/yanet-platform/monalive/internal/types/weight"
+	"github.com/yanet-platform/monalive/internal/types/xevent"
+)
+
+// ErrNoLiveState is returned on the comparison if the live load balancer state
+// is not available.
+var ErrNoLiveState = errors.New("live balancer state is not available")
+
+// BalancerClient is an implementation of the load balancer client recording
+// the decisions of Monalive instead of applying them.
+//
+// The client keeps the last decided state of each real server and compares
+// them with the live load balancer state retrieved by the optional [Stater].
+// It does not implement the [balancer.Stater] interface itself, so the
+// balancer neither waits for the reals to appear in the live state nor tries
+// to repair their drift.
+type BalancerClient struct {
+	recorder *Recorder
+	live     balancer.Stater // source of the live state, may be nil
+
+	decided map[key.Balancer]xevent.Status // last decided states of the reals
+	mu      sync.Mutex                     // to protect decided
+}
+
+// NewBalancerClient creates a new BalancerClient recording the decisions with
+// the recorder. The live state is retrieved from the live client if it is not
+// nil. The live client also resolves the default module of the reals if it
+// implements the [balancer.ModuleResolver] interface.
+func NewBalancerClient(recorder *Recorder, live balancer.Stater) *BalancerClient {
+	return &BalancerClient{
+		recorder: recorder,
+		live:     live,
+		decided:  make(map[key.Balancer]xevent.Status),
+	}
+}
+
+// ResolveModule resolves the module with the live client. If it does not
+// resolve modules, the module is returned as is.
+func (m *BalancerClient) ResolveModule(module string) string {
+	if resolver, implements := m.live.(balancer.ModuleResolver); implements {
+		return resolver.ResolveModule(module)
+	}
+	return module
+}
+
+// EnableReal records the decision to enable the real with the weight.
+func (m *BalancerClient) EnableReal(_ context.Context, balancerKey key.Balancer, weight weight.Weight) error {
+	m.decide(balancerKey, true, weight)
+	return nil
+}
+
+// DisableReal records the decision to disable the real.
+func (m *BalancerClient) DisableReal(_ context.Context, balancerKey key.Balancer) error {
+	m.decide(balancerKey, false, weight.Omitted)
+	return nil
+}
+
+// ApplyReals records the decisions of the batch. No update fails.
+func (m *BalancerClient) ApplyReals(_ context.Context, updates []balancer.RealUpdate) (failed []key.Balancer, err error) {
+	for _, update := range updates {
+		m.decide(update.Key, update.Enable, update.Weight)
+	}
+	return nil, nil
+}
+
+// Flush records the decision to commit the updates.
+func (m *BalancerClient) Flush(context.Context) error {
+	m.recorder.Record(Record{Action: ActionFlush})
+	return nil
+}
+
+// Compare retrieves the live load balancer state and returns the reals whose
+// decided state differs from it. The reals missing in the live state are
+// considered disabled there.
+func (m *BalancerClient) Compare(ctx context.Context) ([]balancer.Drift, error) {
+	if m.live == nil {
+		return nil, ErrNoLiveState
+	}
+	requested := time.Now()
+	state, err := m.live.GetState(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return balancer.Diff(m.decided, state, requested), nil
+}
+
+// decide records the decision and stores the decided state of the real.
+func (m *BalancerClient) decide(balancerKey key.Balancer, enable bool, weight weight.Weight) {
+	record := realRecord(ActionDisable, balancerKey)
+	if enable {
+		record.Action = ActionEnable
+		value := weight.Uint32()
+		record.Weight = &value
+	}
+	m.recorder.Record(record)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.decided[balancerKey] = xevent.Status{Enable: enable, Weight: weight}
+}
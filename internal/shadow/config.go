@@ -0,0 +1,8 @@
+package shadow
+
+// Config represents the configuration of the shadow mode.
+type Config struct {
+	// Path is the path to the JSON Lines file the decisions are appended to.
+	// If it is empty, the decisions are written to the log.
+	Path string `yaml:"path"`
+}
@@ -0,0 +1,125 @@
+// Package shadow provides the load balancer and announcer clients recording
+// the decisions of Monalive instead of applying them. It allows to run
+// Monalive next to the load balancer managed by other means and to compare
+// the decisions with the live load balancer state.
+package shadow
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/netip"
+	"os"
+	"sync"
+	"time"
+
+	log "go.uber.org/zap"
+
+	"github.com/yanet-platform/monalive/internal/types/key"
+)
+
+// Action is the kind of the recorded decision.
+type Action string
+
+const (
+	// ActionEnable is the decision to enable the real server.
+	ActionEnable Action = "enable"
+	// ActionDisable is the decision to disable the real server.
+	ActionDisable Action = "disable"
+	// ActionFlush is the decision to commit the real server updates.
+	ActionFlush Action = "flush"
+	// ActionAnnounce is the decision to announce the prefix.
+	ActionAnnounce Action = "announce"
+	// ActionWithdraw is the decision to withdraw the prefix.
+	ActionWithdraw Action = "withdraw"
+)
+
+// Record is a single decision recorded in the shadow mode.
+type Record struct {
+	Time   time.Time `json:"time"`
+	Action Action    `json:"action"`
+
+	// Fields of the real server decisions.
+	Module   string  `json:"module,omitempty"`
+	VIP      string  `json:"vip,omitempty"`
+	Port     *uint32 `json:"port,omitempty"`
+	Protocol string  `json:"protocol,omitempty"`
+	RealIP   string  `json:"real_ip,omitempty"`
+	RealPort *uint32 `json:"real_port,omitempty"`
+	Weight   *uint32 `json:"weight,omitempty"`
+
+	// Fields of the announce decisions.
+	Group  string `json:"group,omitempty"`
+	Prefix string `json:"prefix,omitempty"`
+}
+
+// realRecord creates the record of the real server decision.
+func realRecord(action Action, balancerKey key.Balancer) Record {
+	return Record{
+		Action:   action,
+		Module:   balancerKey.Module,
+		VIP:      balancerKey.Service.Addr.String(),
+		Port:     balancerKey.Service.Port.ProtoMarshaller(),
+		Protocol: balancerKey.Service.Proto,
+		RealIP:   balancerKey.Real.Addr.String(),
+		RealPort: balancerKey.Real.Port.ProtoMarshaller(),
+	}
+}
+
+// announceRecord creates the record of the announce decision.
+func announceRecord(action Action, group string, prefix netip.Prefix) Record {
+	return Record{
+		Action: action,
+		Group:  group,
+		Prefix: prefix.String(),
+	}
+}
+
+// Recorder writes the decisions as JSON Lines to the file or to the log.
+type Recorder struct {
+	file    *os.File      // nil if the decisions are written to the log
+	encoder *json.Encoder // encodes the records to the file
+	mu      sync.Mutex    // to serialize the writes
+
+	log *log.Logger
+}
+
+// NewRecorder creates a new Recorder. The file of the decisions is created if
+// it does not exist and appended to otherwise.
+func NewRecorder(config Config, logger *log.Logger) (*Recorder, error) {
+	recorder := &Recorder{log: logger}
+	if config.Path == "" {
+		return recorder, nil
+	}
+
+	file, err := os.OpenFile(config.Path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open decisions file: %w", err)
+	}
+	recorder.file = file
+	recorder.encoder = json.NewEncoder(file)
+	return recorder, nil
+}
+
+// Record stamps the decision with the current time and writes it. The write
+// failures are logged, as the decisions are not applied anyway.
+func (m *Recorder) Record(record Record) {
+	record.Time = time.Now()
+	if m.file == nil {
+		m.log.Info("shadow decision", log.Any("decision", record))
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.encoder.Encode(record); err != nil {
+		m.log.Warn("failed to record shadow decision", log.Error(err))
+	}
+}
+
+// Close closes the file of the decisions.
+func (m *Recorder) Close() error {
+	if m.file == nil {
+		return nil
+	}
+	return m.file.Close()
+}
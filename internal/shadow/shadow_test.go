@@ -0,0 +1,121 @@
+package shadow
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	log "go.uber.org/zap"
+
+	"github.com/yanet-platform/monalive/internal/announcer"
+	"github.com/yanet-platform/monalive/internal/balancer"
+	"github.com/yanet-platform/monalive/internal/types/key"
+	"github.com/yanet-platform/monalive/internal/types/port"
+)
+
+// liveStater is the stub of the live load balancer state.
+type liveStater struct {
+	state map[string]map[key.Service]map[key.Real]balancer.RealState
+}
+
+func (m *liveStater) GetState(context.Context) (map[string]map[key.Service]map[key.Real]balancer.RealState, error) {
+	return m.state, nil
+}
+
+// readRecords reads the records written to the file.
+func readRecords(t *testing.T, path string) []Record {
+	file, err := os.Open(path)
+	require.NoError(t, err)
+	defer file.Close()
+
+	var records []Record
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var record Record
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &record))
+		records = append(records, record)
+	}
+	require.NoError(t, scanner.Err())
+	return records
+}
+
+// TestBalancerClient tests that the real server decisions are recorded and
+// compared with the live state.
+func TestBalancerClient(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "decisions.jsonl")
+	recorder, err := NewRecorder(Config{Path: path}, log.NewNop())
+	require.NoError(t, err)
+	defer recorder.Close()
+
+	service := key.Service{Addr: netip.MustParseAddr("10.0.0.1"), Port: 80, Proto: "TCP"}
+	matching := key.Balancer{Module: "balancer0", Service: service, Real: key.Real{Addr: netip.MustParseAddr("10.1.0.1"), Port: port.Omitted}}
+	reweighted := key.Balancer{Module: "balancer0", Service: service, Real: key.Real{Addr: netip.MustParseAddr("10.1.0.2"), Port: port.Omitted}}
+	missing := key.Balancer{Module: "balancer0", Service: service, Real: key.Real{Addr: netip.MustParseAddr("10.1.0.3"), Port: port.Omitted}}
+
+	live := &liveStater{state: map[string]map[key.Service]map[key.Real]balancer.RealState{
+		"balancer0": {service: {
+			matching.Real:   {Enabled: true, Weight: 1},
+			reweighted.Real: {Enabled: true, Weight: 1},
+		}},
+	}}
+	client := NewBalancerClient(recorder, live)
+
+	ctx := context.Background()
+	failed, err := client.ApplyReals(ctx, []balancer.RealUpdate{
+		{Key: matching, Enable: true, Weight: 1},
+		{Key: reweighted, Enable: true, Weight: 5},
+		{Key: missing, Enable: true, Weight: 1},
+	})
+	require.NoError(t, err)
+	assert.Empty(t, failed)
+	require.NoError(t, client.DisableReal(ctx, missing))
+	require.NoError(t, client.Flush(ctx))
+
+	records := readRecords(t, path)
+	require.Len(t, records, 5)
+	assert.Equal(t, ActionEnable, records[1].Action)
+	assert.Equal(t, "10.1.0.2", records[1].RealIP)
+	require.NotNil(t, records[1].Weight)
+	assert.EqualValues(t, 5, *records[1].Weight)
+	assert.Equal(t, ActionDisable, records[3].Action)
+	assert.Nil(t, records[3].Weight)
+	assert.Equal(t, ActionFlush, records[4].Action)
+
+	// The disabled real missing in the live state matches it, while the real
+	// of the different weight does not.
+	diff, err := client.Compare(ctx)
+	require.NoError(t, err)
+	require.Len(t, diff, 1)
+	assert.Equal(t, reweighted, diff[0].Key)
+	assert.Equal(t, balancer.RealState{Enabled: true, Weight: 1}, diff[0].Actual)
+
+	// The comparison fails without the live state.
+	_, err = NewBalancerClient(recorder, nil).Compare(ctx)
+	assert.ErrorIs(t, err, ErrNoLiveState)
+}
+
+// TestAnnouncerClient tests that the announce decisions of the batch are
+// recorded ordered by prefix.
+func TestAnnouncerClient(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "decisions.jsonl")
+	recorder, err := NewRecorder(Config{Path: path}, log.NewNop())
+	require.NoError(t, err)
+	defer recorder.Close()
+
+	client := NewAnnouncerClient(recorder)
+	require.NoError(t, client.ProcessBatch("default", map[netip.Prefix]announcer.PrefixStatus{
+		netip.MustParsePrefix("10.0.0.2/32"): announcer.Unready,
+		netip.MustParsePrefix("10.0.0.1/32"): announcer.Ready,
+	}))
+
+	records := readRecords(t, path)
+	require.Len(t, records, 2)
+	assert.Equal(t, Record{Time: records[0].Time, Action: ActionAnnounce, Group: "default", Prefix: "10.0.0.1/32"}, records[0])
+	assert.Equal(t, Record{Time: records[1].Time, Action: ActionWithdraw, Group: "default", Prefix: "10.0.0.2/32"}, records[1])
+}
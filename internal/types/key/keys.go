@@ -1,7 +1,10 @@
 package key
 
 import (
+	"cmp"
+	"fmt"
 	"net/netip"
+	"strings"
 
 	"github.com/yanet-platform/monalive/internal/monitoring/metrics"
 	"github.com/yanet-platform/monalive/internal/types/port"
@@ -25,6 +28,19 @@ func (m Service) LabelNames() []string {
 	return []string{"vip", "vport", "proto"}
 }
 
+func (m Service) String() string {
+	if m.Port < 0 {
+		return fmt.Sprintf("%s/%s", m.Addr, m.Proto)
+	}
+	return fmt.Sprintf("%s/%s", netip.AddrPortFrom(m.Addr, m.Port.Value()), m.Proto)
+}
+
+// Compare returns an integer comparing two services by address, port and
+// protocol. The result is suitable for [slices.SortFunc].
+func (m Service) Compare(other Service) int {
+	return cmp.Or(m.Addr.Compare(other.Addr), cmp.Compare(m.Port, other.Port), strings.Compare(m.Proto, other.Proto))
+}
+
 func (m Service) Prefix() netip.Prefix {
 	return netip.PrefixFrom(m.Addr, m.Addr.BitLen())
 }
@@ -48,4 +64,7 @@ func (m Real) LabelNames() []string {
 type Balancer struct {
 	Service Service
 	Real    Real
+	// Module is the load balancer module the real is configured in. Empty
+	// value means the default module of the load balancer client.
+	Module string
 }
@@ -0,0 +1,105 @@
+// Package override provides the manual overrides of the health check results
+// set via API.
+package override
+
+import (
+	"time"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	monalivepb "github.com/yanet-platform/monalive/gen/manager"
+)
+
+// Mode determines how the override affects the state of the overridden entity.
+type Mode string
+
+const (
+	// Auto is the default mode: the state is determined by the health checks
+	// only.
+	Auto Mode = "auto"
+	// Drain keeps the real enabled in the load balancer, but sets its weight
+	// to zero, so established flows are kept while new ones are not scheduled.
+	Drain Mode = "drain"
+	// ForceDown disables the real regardless of the health checks results.
+	ForceDown Mode = "force_down"
+	// ForceUp enables the real regardless of the health checks results.
+	ForceUp Mode = "force_up"
+)
+
+// Override represents a manual override of the real or the service state.
+type Override struct {
+	Mode Mode `json:"mode"`
+	// Reason is a human readable description of why the override is set.
+	Reason string `json:"reason,omitempty"`
+	// Created is the time when the override was set.
+	Created time.Time `json:"created"`
+	// Expires is the time when the override is automatically reset. Zero value
+	// means that the override never expires.
+	Expires time.Time `json:"expires,omitempty"`
+}
+
+// Active reports whether the override affects the state.
+func (m Override) Active() bool {
+	return m.Mode != "" && m.Mode != Auto
+}
+
+// Expired reports whether the override is expired at the given moment.
+func (m Override) Expired(now time.Time) bool {
+	return !m.Expires.IsZero() && !now.Before(m.Expires)
+}
+
+// AfterExpire waits for the override to expire and then calls f in its own
+// goroutine. Returns nil if the override never expires, otherwise returns the
+// timer that can be used to cancel the call.
+func (m Override) AfterExpire(f func()) *time.Timer {
+	if !m.Active() || m.Expires.IsZero() {
+		return nil
+	}
+	return time.AfterFunc(time.Until(m.Expires), f)
+}
+
+// ProtoMarshaller converts the override to the protocol buffer message. If the
+// override is not active, it returns nil.
+func (m Override) ProtoMarshaller() *monalivepb.Override {
+	if !m.Active() {
+		return nil
+	}
+
+	override := &monalivepb.Override{
+		Mode:    ModeProtoMarshaller(m.Mode),
+		Reason:  m.Reason,
+		Created: timestamppb.New(m.Created),
+	}
+	if !m.Expires.IsZero() {
+		override.Expires = timestamppb.New(m.Expires)
+	}
+	return override
+}
+
+// ModeProtoMarshaller converts the mode to the protocol buffer enum value.
+func ModeProtoMarshaller(mode Mode) monalivepb.OverrideMode {
+	switch mode {
+	case Drain:
+		return monalivepb.OverrideMode_DRAIN
+	case ForceDown:
+		return monalivepb.OverrideMode_FORCE_DOWN
+	case ForceUp:
+		return monalivepb.OverrideMode_FORCE_UP
+	default:
+		return monalivepb.OverrideMode_AUTO
+	}
+}
+
+// ModeProtoUnmarshaller converts the protocol buffer enum value to the mode.
+func ModeProtoUnmarshaller(mode monalivepb.OverrideMode) Mode {
+	switch mode {
+	case monalivepb.OverrideMode_DRAIN:
+		return Drain
+	case monalivepb.OverrideMode_FORCE_DOWN:
+		return ForceDown
+	case monalivepb.OverrideMode_FORCE_UP:
+		return ForceUp
+	default:
+		return Auto
+	}
+}
@@ -3,6 +3,7 @@ package port
 import (
 	"encoding/json"
 	"fmt"
+	"math"
 	"strconv"
 )
 
@@ -75,3 +76,15 @@ func (p Port) ProtoMarshaller() *uint32 {
 	v := uint32(p)
 	return &v
 }
+
+// ProtoUnmarshaller converts a pointer to a uint32 from protocol buffer
+// messages to the Port value. If the pointer is nil, it returns [Omitted].
+func ProtoUnmarshaller(value *uint32) (Port, error) {
+	if value == nil {
+		return Omitted, nil
+	}
+	if *value > math.MaxUint16 {
+		return Omitted, fmt.Errorf("invalid port value %d", *value)
+	}
+	return Port(*value), nil
+}
@@ -1,6 +1,8 @@
 package xevent
 
 import (
+	"time"
+
 	"github.com/yanet-platform/monalive/internal/types/key"
 	"github.com/yanet-platform/monalive/internal/types/weight"
 )
@@ -31,6 +33,13 @@ type Event struct {
 	New Status
 	// Initial status of the [key.Balancer].
 	Init Status
+
+	// Latency is the response time percentile of the real reported by the
+	// checker. Zero value means that the latency is unknown.
+	Latency time.Duration
+	// Degraded indicates that the latency of the real exceeds the degradation
+	// threshold of the checker.
+	Degraded bool
 }
 
 // Status represents the state of a health check with respect to its enabled
@@ -57,6 +57,19 @@ func (r *Registry[K, V]) Store(key K, value V) {
 	r.events[key] = newValue
 }
 
+// StoreIfAbsent adds the event to the registry only if there is no event
+// associated with the given key. Returns true if the event is stored.
+func (r *Registry[K, V]) StoreIfAbsent(key K, value V) (stored bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.events[key]; exists {
+		return false
+	}
+	r.events[key] = value
+	return true
+}
+
 // Flush clears all events from the registry and returns them.
 func (r *Registry[K, V]) Flush() map[K]V {
 	r.mu.Lock()
@@ -95,3 +108,29 @@ func (r *Registry[K, V]) Process(processor func(key K, event V) error) (processe
 	}
 	return processed
 }
+
+// ProcessBatch applies the provided processor function to all events in the
+// registry at once. The processor returns the keys of the events it failed to
+// process, these events are kept in the registry, the others are removed. The
+// function returns the number of successfully processed events.
+func (r *Registry[K, V]) ProcessBatch(processor func(events map[K]V) (failed []K)) (processed int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if len(r.events) == 0 {
+		return 0
+	}
+
+	failed := processor(r.events)
+
+	// Keep only the failed events in the registry.
+	events := make(map[K]V, len(failed))
+	for _, key := range failed {
+		if value, exists := r.events[key]; exists {
+			events[key] = value
+		}
+	}
+	processed = len(r.events) - len(events)
+	r.events = events
+	return processed
+}
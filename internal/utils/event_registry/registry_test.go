@@ -2,6 +2,7 @@ package eventregistry
 
 import (
 	"fmt"
+	"maps"
 	"slices"
 	"testing"
 
@@ -113,3 +114,26 @@ func TestRegistry_Process(t *testing.T) {
 	assert.Equal(t, 1, data["badKey"].Value)
 	assert.Equal(t, 0, data["badKey"].InitValue)
 }
+
+// TestRegistry_ProcessBatch verifies the behavior of the ProcessBatch method.
+// It checks that all events are passed to the processor at once, and that only
+// the failed events are kept in the registry.
+func TestRegistry_ProcessBatch(t *testing.T) {
+	events := NewRegistry[string, TestEvent]()
+	events.Store("goodKey", TestEvent{Value: 1, InitValue: 0})
+	events.Store("badKey", TestEvent{Value: 2, InitValue: 0})
+
+	var batch map[string]TestEvent
+	processed := events.ProcessBatch(func(events map[string]TestEvent) []string {
+		batch = maps.Clone(events)
+		return []string{"badKey"}
+	})
+
+	assert.Equal(t, 1, processed)
+	assert.Len(t, batch, 2)
+
+	// Verify that only the failed event remains in the registry.
+	data := events.Events()
+	require.Len(t, data, 1)
+	assert.Equal(t, 2, data["badKey"].Value)
+}
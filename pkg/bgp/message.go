@@ -0,0 +1,300 @@
+// Package bgp provides a minimal BGP-4 speaker (RFC 4271) capable of
+// originating and withdrawing IPv4 and IPv6 unicast prefixes towards its peers.
+// Routes received from the peers are ignored.
+package bgp
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net/netip"
+)
+
+// MessageType is the type of a BGP message.
+type MessageType uint8
+
+const (
+	MessageOpen         MessageType = 1
+	MessageUpdate       MessageType = 2
+	MessageNotification MessageType = 3
+	MessageKeepalive    MessageType = 4
+)
+
+const (
+	headerSize     = 19   // marker, length and type
+	maxMessageSize = 4096 // maximal BGP message size
+
+	version = 4 // BGP version
+
+	// asTrans is the AS number placed into the OPEN message by the speakers
+	// with 4-octet AS numbers that do not fit into 2 octets (RFC 6793).
+	asTrans = 23456
+)
+
+// Path attribute type codes.
+const (
+	attrOrigin      = 1
+	attrASPath      = 2
+	attrNextHop     = 3
+	attrLocalPref   = 5
+	attrCommunities = 8
+	attrMPReach     = 14
+	attrMPUnreach   = 15
+)
+
+// Path attribute flags.
+const (
+	flagOptional   = 0x80
+	flagTransitive = 0x40
+	flagExtended   = 0x10
+)
+
+// Capability codes.
+const (
+	capMultiprotocol = 1
+	capFourOctetAS   = 65
+)
+
+// Address families.
+const (
+	afiIPv4     = 1
+	afiIPv6     = 2
+	safiUnicast = 1
+)
+
+// Notification error codes used by the speaker.
+const (
+	errCodeOpenMessage      = 2
+	errCodeHoldTimerExpired = 4
+	errCodeCease            = 6
+)
+
+// Notification error subcodes of the OPEN message errors used by the speaker.
+const (
+	errSubcodeUnacceptableHoldTime = 6
+)
+
+// ErrInvalidMessage is returned when a malformed message is received.
+var ErrInvalidMessage = errors.New("invalid bgp message")
+
+// Attributes are the path attributes of the originated prefix.
+type Attributes struct {
+	// NextHop is the next hop of the prefix. It must be of the same address
+	// family as the prefix.
+	NextHop netip.Addr
+	// LocalPref is the local preference of the prefix. It is sent to the
+	// internal peers only.
+	LocalPref *uint32
+	// Communities is the list of the standard communities of the prefix.
+	Communities []uint32
+}
+
+// open represents the OPEN message.
+type open struct {
+	as       uint32
+	holdTime uint16
+	routerID netip.Addr
+}
+
+// writeMessage writes the message of the given type with the body to w.
+func writeMessage(w io.Writer, msgType MessageType, body []byte) error {
+	if headerSize+len(body) > maxMessageSize {
+		return fmt.Errorf("message of %d bytes exceeds maximal size", headerSize+len(body))
+	}
+	msg := make([]byte, headerSize, headerSize+len(body))
+	for i := range 16 {
+		msg[i] = 0xff
+	}
+	binary.BigEndian.PutUint16(msg[16:], uint16(headerSize+len(body)))
+	msg[18] = byte(msgType)
+	msg = append(msg, body...)
+	_, err := w.Write(msg)
+	return err
+}
+
+// readMessage reads the next message from r.
+func readMessage(r io.Reader) (msgType MessageType, body []byte, err error) {
+	var header [headerSize]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return 0, nil, err
+	}
+	length := int(binary.BigEndian.Uint16(header[16:]))
+	if length < headerSize || length > maxMessageSize {
+		return 0, nil, fmt.Errorf("%w: length %d", ErrInvalidMessage, length)
+	}
+	body = make([]byte, length-headerSize)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return 0, nil, err
+	}
+	return MessageType(header[18]), body, nil
+}
+
+// encodeOpen encodes the OPEN message body announcing the 4-octet AS and the
+// IPv4 and IPv6 unicast capabilities.
+func encodeOpen(msg open) []byte {
+	var caps bytes.Buffer
+	for _, afi := range []uint16{afiIPv4, afiIPv6} {
+		caps.Write([]byte{capMultiprotocol, 4})
+		_ = binary.Write(&caps, binary.BigEndian, afi)
+		caps.Write([]byte{0, safiUnicast})
+	}
+	caps.Write([]byte{capFourOctetAS, 4})
+	_ = binary.Write(&caps, binary.BigEndian, msg.as)
+
+	as := uint16(asTrans)
+	if msg.as <= 0xffff {
+		as = uint16(msg.as)
+	}
+
+	var body bytes.Buffer
+	body.WriteByte(version)
+	_ = binary.Write(&body, binary.BigEndian, as)
+	_ = binary.Write(&body, binary.BigEndian, msg.holdTime)
+	routerID := msg.routerID.As4()
+	body.Write(routerID[:])
+	// Optional parameters contain a single capabilities parameter.
+	body.WriteByte(byte(2 + caps.Len()))
+	body.Write([]byte{2, byte(caps.Len())})
+	body.Write(caps.Bytes())
+	return body.Bytes()
+}
+
+// decodeOpen decodes the OPEN message body. The 4-octet AS capability value is
+// used as the AS number if present.
+func decodeOpen(body []byte) (msg open, err error) {
+	if len(body) < 10 || body[0] != version {
+		return msg, fmt.Errorf("%w: open", ErrInvalidMessage)
+	}
+	msg.as = uint32(binary.BigEndian.Uint16(body[1:]))
+	msg.holdTime = binary.BigEndian.Uint16(body[3:])
+	msg.routerID = netip.AddrFrom4([4]byte(body[5:9]))
+
+	params := body[10:]
+	if len(params) != int(body[9]) {
+		return msg, fmt.Errorf("%w: open parameters", ErrInvalidMessage)
+	}
+	for len(params) >= 2 {
+		paramType, paramLen := params[0], int(params[1])
+		if len(params) < 2+paramLen {
+			return msg, fmt.Errorf("%w: open parameters", ErrInvalidMessage)
+		}
+		caps := params[2 : 2+paramLen]
+		params = params[2+paramLen:]
+		if paramType != 2 {
+			continue
+		}
+		for len(caps) >= 2 {
+			capCode, capLen := caps[0], int(caps[1])
+			if len(caps) < 2+capLen {
+				return msg, fmt.Errorf("%w: capabilities", ErrInvalidMessage)
+			}
+			if capCode == capFourOctetAS && capLen == 4 {
+				msg.as = binary.BigEndian.Uint32(caps[2:])
+			}
+			caps = caps[2+capLen:]
+		}
+	}
+	return msg, nil
+}
+
+// encodeNotification encodes the NOTIFICATION message body.
+func encodeNotification(code, subcode uint8) []byte {
+	return []byte{code, subcode}
+}
+
+// encodePrefix appends the prefix in the NLRI encoding to the buffer.
+func encodePrefix(buf *bytes.Buffer, prefix netip.Prefix) {
+	bits := prefix.Bits()
+	buf.WriteByte(byte(bits))
+	addr := prefix.Addr().AsSlice()
+	buf.Write(addr[:(bits+7)/8])
+}
+
+// encodeAttribute appends the path attribute to the buffer.
+func encodeAttribute(buf *bytes.Buffer, flags, code uint8, value []byte) {
+	if len(value) > 0xff {
+		buf.Write([]byte{flags | flagExtended, code})
+		_ = binary.Write(buf, binary.BigEndian, uint16(len(value)))
+	} else {
+		buf.Write([]byte{flags, code, byte(len(value))})
+	}
+	buf.Write(value)
+}
+
+// encodeAnnounce encodes the UPDATE message body originating the prefix with
+// the attributes. The localAS is prepended to the AS path for the external
+// peers.
+func encodeAnnounce(prefix netip.Prefix, attrs Attributes, localAS uint32, external bool) []byte {
+	var pathAttrs bytes.Buffer
+	// ORIGIN is IGP.
+	encodeAttribute(&pathAttrs, flagTransitive, attrOrigin, []byte{0})
+
+	// AS_PATH is empty for internal peers and consists of the local AS for
+	// external ones.
+	var asPath []byte
+	if external {
+		asPath = binary.BigEndian.AppendUint32([]byte{2, 1}, localAS)
+	}
+	encodeAttribute(&pathAttrs, flagTransitive, attrASPath, asPath)
+
+	if !external && attrs.LocalPref != nil {
+		encodeAttribute(&pathAttrs, flagTransitive, attrLocalPref, binary.BigEndian.AppendUint32(nil, *attrs.LocalPref))
+	}
+	if len(attrs.Communities) > 0 {
+		var communities []byte
+		for _, community := range attrs.Communities {
+			communities = binary.BigEndian.AppendUint32(communities, community)
+		}
+		encodeAttribute(&pathAttrs, flagOptional|flagTransitive, attrCommunities, communities)
+	}
+
+	var nlri bytes.Buffer
+	if prefix.Addr().Is4() {
+		nextHop := attrs.NextHop.As4()
+		encodeAttribute(&pathAttrs, flagTransitive, attrNextHop, nextHop[:])
+		encodePrefix(&nlri, prefix)
+	} else {
+		var mpReach bytes.Buffer
+		_ = binary.Write(&mpReach, binary.BigEndian, uint16(afiIPv6))
+		mpReach.WriteByte(safiUnicast)
+		nextHop := attrs.NextHop.As16()
+		mpReach.WriteByte(byte(len(nextHop)))
+		mpReach.Write(nextHop[:])
+		mpReach.WriteByte(0) // reserved
+		encodePrefix(&mpReach, prefix)
+		encodeAttribute(&pathAttrs, flagOptional, attrMPReach, mpReach.Bytes())
+	}
+
+	return encodeUpdate(nil, pathAttrs.Bytes(), nlri.Bytes())
+}
+
+// encodeWithdraw encodes the UPDATE message body withdrawing the prefix.
+func encodeWithdraw(prefix netip.Prefix) []byte {
+	var withdrawn bytes.Buffer
+	if prefix.Addr().Is4() {
+		encodePrefix(&withdrawn, prefix)
+		return encodeUpdate(withdrawn.Bytes(), nil, nil)
+	}
+
+	var mpUnreach bytes.Buffer
+	_ = binary.Write(&mpUnreach, binary.BigEndian, uint16(afiIPv6))
+	mpUnreach.WriteByte(safiUnicast)
+	encodePrefix(&mpUnreach, prefix)
+
+	var pathAttrs bytes.Buffer
+	encodeAttribute(&pathAttrs, flagOptional, attrMPUnreach, mpUnreach.Bytes())
+	return encodeUpdate(nil, pathAttrs.Bytes(), nil)
+}
+
+// encodeUpdate encodes the UPDATE message body from its parts.
+func encodeUpdate(withdrawn, pathAttrs, nlri []byte) []byte {
+	var body bytes.Buffer
+	_ = binary.Write(&body, binary.BigEndian, uint16(len(withdrawn)))
+	body.Write(withdrawn)
+	_ = binary.Write(&body, binary.BigEndian, uint16(len(pathAttrs)))
+	body.Write(pathAttrs)
+	body.Write(nlri)
+	return body.Bytes()
+}
@@ -0,0 +1,309 @@
+package bgp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"sync"
+	"time"
+
+	log "go.uber.org/zap"
+)
+
+// ErrNotEstablished is returned when the session with the peer is not
+// established. The routes are advertised once the session is up.
+var ErrNotEstablished = errors.New("bgp session is not established")
+
+// MinHoldTime is the minimal non-zero hold time. A hold time of one or two
+// seconds is not acceptable.
+const MinHoldTime = 3 * time.Second
+
+const (
+	defaultHoldTime     = 90 * time.Second
+	defaultConnectRetry = 5 * time.Second
+	// openHoldTime is the hold time used until the OPEN message is received.
+	openHoldTime = 4 * time.Minute
+)
+
+// Config represents the configuration of a BGP session with a single peer.
+type Config struct {
+	// LocalAS is the AS number of the speaker.
+	LocalAS uint32
+	// RouterID is the BGP identifier of the speaker.
+	RouterID netip.Addr
+	// Peer is the address of the peer.
+	Peer netip.AddrPort
+	// PeerAS is the expected AS number of the peer. The session is external
+	// if it differs from the LocalAS.
+	PeerAS uint32
+	// HoldTime is the proposed hold time. It must be at least MinHoldTime.
+	// Defaults to 90s.
+	HoldTime time.Duration
+	// ConnectRetry is the delay between connection attempts. Defaults to 5s.
+	ConnectRetry time.Duration
+}
+
+// Speaker maintains a BGP session with a single peer and advertises the
+// originated prefixes to it. The session is re-established on failures, and
+// all originated prefixes are advertised again each time it comes up.
+type Speaker struct {
+	config Config
+
+	routes map[netip.Prefix]Attributes // prefixes originated by the speaker
+	conn   net.Conn                    // established session, nil if it is down
+	mu     sync.Mutex                  // protects routes and serializes writes to conn
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	done   chan struct{}
+
+	log *log.Logger
+}
+
+// NewSpeaker creates a new Speaker. The session is established by Run.
+func NewSpeaker(config Config, logger *log.Logger) *Speaker {
+	if config.HoldTime == 0 {
+		config.HoldTime = defaultHoldTime
+	}
+	if config.ConnectRetry == 0 {
+		config.ConnectRetry = defaultConnectRetry
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Speaker{
+		config: config,
+		routes: make(map[netip.Prefix]Attributes),
+		ctx:    ctx,
+		cancel: cancel,
+		done:   make(chan struct{}),
+		log:    logger.With(log.Stringer("bgp_peer", config.Peer)),
+	}
+}
+
+// Run establishes the session with the peer and keeps it up until Shutdown is
+// called.
+func (m *Speaker) Run() {
+	defer close(m.done)
+	for {
+		err := m.session()
+		if m.ctx.Err() != nil {
+			return
+		}
+		m.log.Warn("bgp session is down", log.Error(err))
+
+		select {
+		case <-m.ctx.Done():
+			return
+		case <-time.After(m.config.ConnectRetry):
+		}
+	}
+}
+
+// Announce originates the prefix with the attributes. If the session is down,
+// the prefix is advertised once it is established and [ErrNotEstablished] is
+// returned.
+func (m *Speaker) Announce(prefix netip.Prefix, attrs Attributes) error {
+	if !attrs.NextHop.IsValid() || attrs.NextHop.Is4() != prefix.Addr().Is4() {
+		return fmt.Errorf("invalid next hop %s for prefix %s", attrs.NextHop, prefix)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.routes[prefix] = attrs
+	return m.send(MessageUpdate, encodeAnnounce(prefix, attrs, m.config.LocalAS, m.external()))
+}
+
+// Withdraw withdraws the prefix. If the session is down, the prefix is not
+// advertised once it is established and [ErrNotEstablished] is returned.
+func (m *Speaker) Withdraw(prefix netip.Prefix) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, exists := m.routes[prefix]; !exists {
+		return nil
+	}
+	delete(m.routes, prefix)
+	return m.send(MessageUpdate, encodeWithdraw(prefix))
+}
+
+// Established reports whether the session with the peer is established.
+func (m *Speaker) Established() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.conn != nil
+}
+
+// Shutdown closes the session with the peer and stops the Run loop. The peer
+// withdraws the advertised prefixes on its side.
+func (m *Speaker) Shutdown() {
+	m.cancel()
+	m.mu.Lock()
+	if m.conn != nil {
+		_ = m.write(m.conn, MessageNotification, encodeNotification(errCodeCease, 0))
+		_ = m.conn.Close()
+	}
+	m.mu.Unlock()
+	<-m.done
+}
+
+// session establishes the session and serves it until it fails.
+func (m *Speaker) session() error {
+	dialer := net.Dialer{Timeout: m.config.ConnectRetry}
+	conn, err := dialer.DialContext(m.ctx, "tcp", m.config.Peer.String())
+	if err != nil {
+		return fmt.Errorf("failed to connect: %w", err)
+	}
+	defer conn.Close()
+
+	holdTime, err := m.handshake(conn)
+	if err != nil {
+		return err
+	}
+
+	// Advertise all originated prefixes.
+	if err := m.establish(conn); err != nil {
+		return err
+	}
+	defer func() {
+		m.mu.Lock()
+		m.conn = nil
+		m.mu.Unlock()
+	}()
+	m.log.Info("bgp session is established")
+
+	// Send keepalives while the session is up.
+	stop := make(chan struct{})
+	defer close(stop)
+	if holdTime > 0 {
+		go m.keepalive(holdTime/3, stop)
+	}
+
+	for {
+		if holdTime > 0 {
+			_ = conn.SetReadDeadline(time.Now().Add(holdTime))
+		}
+		msgType, body, err := readMessage(conn)
+		if err != nil {
+			var netErr net.Error
+			if errors.As(err, &netErr) && netErr.Timeout() {
+				m.mu.Lock()
+				_ = m.write(conn, MessageNotification, encodeNotification(errCodeHoldTimerExpired, 0))
+				m.mu.Unlock()
+				return fmt.Errorf("hold timer expired")
+			}
+			return fmt.Errorf("failed to read message: %w", err)
+		}
+		if msgType == MessageNotification {
+			return fmt.Errorf("notification received: %v", body)
+		}
+		// Keepalives only reset the hold timer, the updates are ignored.
+	}
+}
+
+// handshake exchanges OPEN and KEEPALIVE messages with the peer. Returns the
+// negotiated hold time.
+func (m *Speaker) handshake(conn net.Conn) (holdTime time.Duration, err error) {
+	_ = conn.SetDeadline(time.Now().Add(openHoldTime))
+	defer conn.SetDeadline(time.Time{})
+
+	localOpen := open{
+		as:       m.config.LocalAS,
+		holdTime: uint16(m.config.HoldTime / time.Second),
+		routerID: m.config.RouterID,
+	}
+	if err := writeMessage(conn, MessageOpen, encodeOpen(localOpen)); err != nil {
+		return 0, fmt.Errorf("failed to send open: %w", err)
+	}
+
+	msgType, body, err := readMessage(conn)
+	if err != nil {
+		return 0, fmt.Errorf("failed to receive open: %w", err)
+	}
+	if msgType != MessageOpen {
+		return 0, fmt.Errorf("%w: expected open, got %d", ErrInvalidMessage, msgType)
+	}
+	peerOpen, err := decodeOpen(body)
+	if err != nil {
+		return 0, err
+	}
+	if m.config.PeerAS != 0 && peerOpen.as != m.config.PeerAS {
+		return 0, fmt.Errorf("unexpected peer AS %d", peerOpen.as)
+	}
+	if peerOpen.holdTime != 0 && time.Duration(peerOpen.holdTime)*time.Second < MinHoldTime {
+		_ = writeMessage(conn, MessageNotification, encodeNotification(errCodeOpenMessage, errSubcodeUnacceptableHoldTime))
+		return 0, fmt.Errorf("unacceptable peer hold time %ds", peerOpen.holdTime)
+	}
+
+	if err := writeMessage(conn, MessageKeepalive, nil); err != nil {
+		return 0, fmt.Errorf("failed to send keepalive: %w", err)
+	}
+	msgType, _, err = readMessage(conn)
+	if err != nil {
+		return 0, fmt.Errorf("failed to receive keepalive: %w", err)
+	}
+	if msgType != MessageKeepalive {
+		return 0, fmt.Errorf("%w: expected keepalive, got %d", ErrInvalidMessage, msgType)
+	}
+
+	holdTime = min(time.Duration(localOpen.holdTime), time.Duration(peerOpen.holdTime)) * time.Second
+	return holdTime, nil
+}
+
+// establish marks the session as established and advertises all originated
+// prefixes.
+func (m *Speaker) establish(conn net.Conn) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for prefix, attrs := range m.routes {
+		if err := m.write(conn, MessageUpdate, encodeAnnounce(prefix, attrs, m.config.LocalAS, m.external())); err != nil {
+			return fmt.Errorf("failed to advertise %s: %w", prefix, err)
+		}
+	}
+	m.conn = conn
+	return nil
+}
+
+// keepalive periodically sends KEEPALIVE messages until stop is closed.
+func (m *Speaker) keepalive(interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			m.mu.Lock()
+			if err := m.send(MessageKeepalive, nil); err != nil && !errors.Is(err, ErrNotEstablished) {
+				m.log.Warn("failed to send keepalive", log.Error(err))
+			}
+			m.mu.Unlock()
+		}
+	}
+}
+
+// send sends the message to the established session. On failure the session
+// is closed to be re-established. It assumes the mutex is held.
+func (m *Speaker) send(msgType MessageType, body []byte) error {
+	if m.conn == nil {
+		return ErrNotEstablished
+	}
+	if err := m.write(m.conn, msgType, body); err != nil {
+		// The session is torn down, the Run loop re-establishes it.
+		_ = m.conn.Close()
+		m.conn = nil
+		return fmt.Errorf("failed to send message: %w", err)
+	}
+	return nil
+}
+
+// write writes the message to the connection. The write is limited by the hold
+// time, so a stalled peer does not block the speaker holding the mutex.
+func (m *Speaker) write(conn net.Conn, msgType MessageType, body []byte) error {
+	_ = conn.SetWriteDeadline(time.Now().Add(m.config.HoldTime))
+	return writeMessage(conn, msgType, body)
+}
+
+// external reports whether the session is external.
+func (m *Speaker) external() bool {
+	return m.config.PeerAS != m.config.LocalAS
+}
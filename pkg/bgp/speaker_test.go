@@ -0,0 +1,176 @@
+package bgp
+
+import (
+	"encoding/binary"
+	"net"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	log "go.uber.org/zap"
+)
+
+// testPeer accepts a single session from the speaker and completes the
+// handshake.
+func testPeer(t *testing.T, listener net.Listener, as uint32) net.Conn {
+	conn, err := listener.Accept()
+	require.NoError(t, err)
+	_ = conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+	msgType, body, err := readMessage(conn)
+	require.NoError(t, err)
+	require.Equal(t, MessageOpen, msgType)
+	speakerOpen, err := decodeOpen(body)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(4200000000), speakerOpen.as)
+
+	peerOpen := open{as: as, holdTime: 90, routerID: netip.MustParseAddr("192.0.2.254")}
+	require.NoError(t, writeMessage(conn, MessageOpen, encodeOpen(peerOpen)))
+	require.NoError(t, writeMessage(conn, MessageKeepalive, nil))
+
+	msgType, _, err = readMessage(conn)
+	require.NoError(t, err)
+	require.Equal(t, MessageKeepalive, msgType)
+	return conn
+}
+
+// readUpdate reads the next UPDATE message skipping keepalives.
+func readUpdate(t *testing.T, conn net.Conn) (withdrawn, pathAttrs, nlri []byte) {
+	for {
+		msgType, body, err := readMessage(conn)
+		require.NoError(t, err)
+		if msgType != MessageUpdate {
+			continue
+		}
+		withdrawnLen := int(binary.BigEndian.Uint16(body))
+		withdrawn = body[2 : 2+withdrawnLen]
+		body = body[2+withdrawnLen:]
+		attrsLen := int(binary.BigEndian.Uint16(body))
+		return withdrawn, body[2 : 2+attrsLen], body[2+attrsLen:]
+	}
+}
+
+// TestSpeaker_AnnounceWithdraw tests that the speaker advertises the prefixes
+// originated before the session is established and sends the subsequent
+// updates.
+func TestSpeaker_AnnounceWithdraw(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+
+	speaker := NewSpeaker(Config{
+		LocalAS:      4200000000,
+		RouterID:     netip.MustParseAddr("192.0.2.1"),
+		Peer:         netip.MustParseAddrPort(listener.Addr().String()),
+		PeerAS:       4200000000,
+		ConnectRetry: 100 * time.Millisecond,
+	}, log.NewNop())
+
+	localPref := uint32(200)
+	attrs := Attributes{
+		NextHop:     netip.MustParseAddr("192.0.2.1"),
+		LocalPref:   &localPref,
+		Communities: []uint32{65000<<16 | 100},
+	}
+	prefix := netip.MustParsePrefix("198.51.100.0/24")
+	assert.ErrorIs(t, speaker.Announce(prefix, attrs), ErrNotEstablished)
+
+	go speaker.Run()
+	defer speaker.Shutdown()
+
+	conn := testPeer(t, listener, 4200000000)
+	defer conn.Close()
+
+	// The prefix originated earlier is advertised.
+	_, pathAttrs, nlri := readUpdate(t, conn)
+	assert.Equal(t, []byte{24, 198, 51, 100}, nlri)
+	assert.Contains(t, string(pathAttrs), string([]byte{flagTransitive, attrLocalPref, 4, 0, 0, 0, 200}))
+	assert.Eventually(t, speaker.Established, time.Second, 10*time.Millisecond)
+
+	// IPv6 prefixes are sent with MP_REACH_NLRI.
+	prefixV6 := netip.MustParsePrefix("2001:db8::/32")
+	require.NoError(t, speaker.Announce(prefixV6, Attributes{NextHop: netip.MustParseAddr("2001:db8::1")}))
+	_, pathAttrs, nlri = readUpdate(t, conn)
+	assert.Empty(t, nlri)
+	assert.Contains(t, string(pathAttrs), string([]byte{32, 0x20, 0x01, 0x0d, 0xb8}))
+
+	require.NoError(t, speaker.Withdraw(prefix))
+	withdrawn, _, _ := readUpdate(t, conn)
+	assert.Equal(t, []byte{24, 198, 51, 100}, withdrawn)
+}
+
+// TestSpeaker_StalledPeer tests that sending to the peer that does not read
+// the messages fails within the hold time and the session is torn down.
+func TestSpeaker_StalledPeer(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	speaker := NewSpeaker(Config{
+		LocalAS:      4200000000,
+		RouterID:     netip.MustParseAddr("192.0.2.1"),
+		Peer:         netip.MustParseAddrPort(listener.Addr().String()),
+		PeerAS:       4200000000,
+		HoldTime:     3 * time.Second,
+		ConnectRetry: 100 * time.Millisecond,
+	}, log.NewNop())
+
+	go speaker.Run()
+	defer speaker.Shutdown()
+
+	conn := testPeer(t, listener, 4200000000)
+	defer conn.Close()
+	// The session is not re-established.
+	listener.Close()
+	require.Eventually(t, speaker.Established, time.Second, 10*time.Millisecond)
+
+	// The peer does not read the messages, so the socket buffers are filled up
+	// eventually and the write blocks.
+	attrs := Attributes{NextHop: netip.MustParseAddr("192.0.2.1")}
+	start := time.Now()
+	for i := 0; err == nil && i < 1<<20; i++ {
+		prefix := netip.PrefixFrom(netip.AddrFrom4([4]byte{10, byte(i >> 16), byte(i >> 8), byte(i)}), 32)
+		err = speaker.Announce(prefix, attrs)
+	}
+	require.Error(t, err)
+	assert.Less(t, time.Since(start), 10*time.Second)
+	assert.False(t, speaker.Established())
+}
+
+// TestSpeaker_UnacceptableHoldTime tests that the peer proposing a hold time
+// of less than three seconds is rejected with a NOTIFICATION.
+func TestSpeaker_UnacceptableHoldTime(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+
+	speaker := NewSpeaker(Config{
+		LocalAS:      4200000000,
+		RouterID:     netip.MustParseAddr("192.0.2.1"),
+		Peer:         netip.MustParseAddrPort(listener.Addr().String()),
+		PeerAS:       4200000000,
+		ConnectRetry: time.Minute,
+	}, log.NewNop())
+
+	go speaker.Run()
+	defer speaker.Shutdown()
+
+	conn, err := listener.Accept()
+	require.NoError(t, err)
+	defer conn.Close()
+	_ = conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+	msgType, _, err := readMessage(conn)
+	require.NoError(t, err)
+	require.Equal(t, MessageOpen, msgType)
+
+	peerOpen := open{as: 4200000000, holdTime: 2, routerID: netip.MustParseAddr("192.0.2.254")}
+	require.NoError(t, writeMessage(conn, MessageOpen, encodeOpen(peerOpen)))
+
+	msgType, body, err := readMessage(conn)
+	require.NoError(t, err)
+	assert.Equal(t, MessageNotification, msgType)
+	assert.Equal(t, encodeNotification(errCodeOpenMessage, errSubcodeUnacceptableHoldTime), body)
+	assert.False(t, speaker.Established())
+}
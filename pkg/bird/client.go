@@ -11,6 +11,8 @@ import (
 	"net"
 	"os"
 	"path"
+	"strconv"
+	"strings"
 	"syscall"
 	"unsafe"
 )
@@ -19,11 +21,47 @@ import (
 // uninitialized socket.
 var ErrEmptySock = errors.New("empty sock")
 
+// ErrBatchTooLarge is returned when a batch of messages does not fit into a
+// single unixgram datagram.
+var ErrBatchTooLarge = errors.New("batch does not fit into a single datagram")
+
 const (
 	defaultBatchSize = 4096                           // defines the default size for message batching
 	messageSize      = uint(unsafe.Sizeof(Message{})) // represents the size of a Message struct in bytes
+
+	// DefaultListenSockName is the default name template of the socket the
+	// client listens for the BIRD state requests on.
+	DefaultListenSockName = GroupPlaceholder + "_b2m"
+	// DefaultWriteSockName is the default name template of the socket the
+	// client sends messages to.
+	DefaultWriteSockName = GroupPlaceholder + "_m2b"
+	// GroupPlaceholder is replaced with the socket name prefix in the socket
+	// name templates.
+	GroupPlaceholder = "{group}"
+
+	// fallbackMaxDatagramSize is the maximal unixgram datagram size used if the
+	// system limit cannot be determined. It corresponds to the default Linux
+	// net.core.wmem_max value.
+	fallbackMaxDatagramSize = 212992
+	// datagramOverhead is the space of the socket send buffer reserved by the
+	// kernel for each datagram.
+	datagramOverhead = 32
+	// wmemMaxPath is the path to the maximal socket send buffer size limit.
+	wmemMaxPath = "/proc/sys/net/core/wmem_max"
 )
 
+// MaxBatchSize returns the maximal number of messages that can be sent to the
+// BIRD in a single unixgram datagram on this host.
+func MaxBatchSize() uint {
+	maxDatagramSize := uint(fallbackMaxDatagramSize)
+	if data, err := os.ReadFile(wmemMaxPath); err == nil {
+		if value, err := strconv.ParseUint(strings.TrimSpace(string(data)), 10, 64); err == nil {
+			maxDatagramSize = uint(value)
+		}
+	}
+	return (maxDatagramSize - datagramOverhead) / messageSize
+}
+
 // Client is a client for communicating with the BIRD routing daemon. It manages
 // Unix domain sockets for sending and receiving messages.
 type Client struct {
@@ -32,6 +70,12 @@ type Client struct {
 	writeAddr  *net.UnixAddr // the address to send outgoing messages
 
 	batchSize uint // the size of the batch when sending messages in bulk
+
+	listenSockName string       // name template of the listen socket
+	writeSockName  string       // name template of the write socket
+	sockMode       *fs.FileMode // permissions of the listen socket file
+	sockUID        int          // owner user of the listen socket file, -1 to keep
+	sockGID        int          // owner group of the listen socket file, -1 to keep
 }
 
 // ClientOption is a function that configures a Client.
@@ -44,12 +88,43 @@ func WithBatchSize(size uint) ClientOption {
 	}
 }
 
+// WithSockNames is a ClientOption that sets the name templates of the listen
+// and write sockets. [GroupPlaceholder] in the templates is replaced with the
+// socket name prefix.
+func WithSockNames(listenSockName, writeSockName string) ClientOption {
+	return func(c *Client) {
+		c.listenSockName = listenSockName
+		c.writeSockName = writeSockName
+	}
+}
+
+// WithSockMode is a ClientOption that sets the permissions of the listen socket
+// file. By default, write permissions for group and others are added.
+func WithSockMode(mode fs.FileMode) ClientOption {
+	return func(c *Client) {
+		c.sockMode = &mode
+	}
+}
+
+// WithSockOwner is a ClientOption that sets the owner of the listen socket
+// file. Passing -1 keeps the corresponding ID unchanged.
+func WithSockOwner(uid, gid int) ClientOption {
+	return func(c *Client) {
+		c.sockUID = uid
+		c.sockGID = gid
+	}
+}
+
 // NewClient creates a new Client with the specified socket directory and name
 // prefix. The client listens and sends messages using Unix domain sockets.
 // Additional options can be provided using the ClientOption functions.
 func NewClient(sockDir, sockNamePrefix string, opts ...ClientOption) (*Client, error) {
 	client := &Client{
-		batchSize: defaultBatchSize,
+		batchSize:      defaultBatchSize,
+		listenSockName: DefaultListenSockName,
+		writeSockName:  DefaultWriteSockName,
+		sockUID:        -1,
+		sockGID:        -1,
 	}
 
 	// Apply all the provided options to the client.
@@ -58,7 +133,7 @@ func NewClient(sockDir, sockNamePrefix string, opts ...ClientOption) (*Client, e
 	}
 
 	// Set up the listen socket address.
-	listenSockName := sockNamePrefix + "_b2m"
+	listenSockName := strings.ReplaceAll(client.listenSockName, GroupPlaceholder, sockNamePrefix)
 	listenAddr := &net.UnixAddr{
 		Name: path.Join(sockDir, listenSockName),
 		Net:  "unixgram",
@@ -76,21 +151,21 @@ func NewClient(sockDir, sockNamePrefix string, opts ...ClientOption) (*Client, e
 		return nil, fmt.Errorf("failed to bind to %q: %w", listenAddr.Name, err)
 	}
 
-	// Get the current file permissions of the socket.
-	var stat syscall.Stat_t
-	if err := syscall.Stat(listenAddr.Name, &stat); err != nil {
-		return nil, fmt.Errorf("failed to get permissions of %q: %w", listenAddr.Name, err)
+	if err := client.setupSockFile(listenAddr.Name); err != nil {
+		_ = client.sock.Close()
+		return nil, err
 	}
 
-	// Add write permissions for others and group.
-	if err := os.Chmod(listenAddr.Name, fs.FileMode(stat.Mode)|syscall.S_IWOTH|syscall.S_IWGRP); err != nil {
-		return nil, fmt.Errorf("failed to add write permission to %q: %w", listenAddr.Name, err)
+	// Ensure that the whole batch fits into a single datagram.
+	if err := client.setupWriteBuffer(); err != nil {
+		_ = client.sock.Close()
+		return nil, err
 	}
 
 	client.listenAddr = listenAddr
 
 	// Set up the write socket address.
-	writeSockName := sockNamePrefix + "_m2b"
+	writeSockName := strings.ReplaceAll(client.writeSockName, GroupPlaceholder, sockNamePrefix)
 	client.writeAddr = &net.UnixAddr{
 		Name: path.Join(sockDir, writeSockName),
 		Net:  "unixgram",
@@ -99,6 +174,78 @@ func NewClient(sockDir, sockNamePrefix string, opts ...ClientOption) (*Client, e
 	return client, nil
 }
 
+// setupSockFile sets the permissions and the owner of the listen socket file.
+func (m *Client) setupSockFile(name string) error {
+	mode := m.sockMode
+	if mode == nil {
+		// Get the current file permissions of the socket.
+		var stat syscall.Stat_t
+		if err := syscall.Stat(name, &stat); err != nil {
+			return fmt.Errorf("failed to get permissions of %q: %w", name, err)
+		}
+
+		// Add write permissions for others and group.
+		defaultMode := fs.FileMode(stat.Mode) | syscall.S_IWOTH | syscall.S_IWGRP
+		mode = &defaultMode
+	}
+	if err := os.Chmod(name, *mode); err != nil {
+		return fmt.Errorf("failed to set permissions of %q: %w", name, err)
+	}
+
+	if m.sockUID != -1 || m.sockGID != -1 {
+		if err := os.Chown(name, m.sockUID, m.sockGID); err != nil {
+			return fmt.Errorf("failed to set owner of %q: %w", name, err)
+		}
+	}
+
+	return nil
+}
+
+// setupWriteBuffer grows the socket send buffer if it cannot hold a full batch
+// of messages. Returns [ErrBatchTooLarge] if the buffer cannot be grown enough.
+func (m *Client) setupWriteBuffer() error {
+	required := int(m.batchSize*messageSize + datagramOverhead)
+
+	current, err := m.writeBuffer()
+	if err != nil {
+		return err
+	}
+	if current >= required {
+		return nil
+	}
+
+	if err := m.sock.SetWriteBuffer(required); err != nil {
+		return fmt.Errorf("failed to set socket send buffer: %w", err)
+	}
+	// The kernel silently caps the buffer size, so check the actual value.
+	if current, err = m.writeBuffer(); err != nil {
+		return err
+	}
+	if current < required {
+		return fmt.Errorf("%w: batch size %d requires %d bytes, socket send buffer is %d bytes", ErrBatchTooLarge, m.batchSize, required, current)
+	}
+	return nil
+}
+
+// writeBuffer returns the current socket send buffer size.
+func (m *Client) writeBuffer() (size int, err error) {
+	conn, err := m.sock.SyscallConn()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get raw socket: %w", err)
+	}
+	var sockErr error
+	err = conn.Control(func(fd uintptr) {
+		size, sockErr = syscall.GetsockoptInt(int(fd), syscall.SOL_SOCKET, syscall.SO_SNDBUF)
+	})
+	if err == nil {
+		err = sockErr
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to get socket send buffer: %w", err)
+	}
+	return size, nil
+}
+
 // ListenRequest listens for an incoming request on the client's socket. This is
 // a blocking call that waits for a message to be received.
 func (m *Client) ListenRequest() error {
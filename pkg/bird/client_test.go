@@ -0,0 +1,35 @@
+package bird
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestNewClient_Options tests that the client creates the listen socket using
+// the name template and the permissions set.
+func TestNewClient_Options(t *testing.T) {
+	dir := t.TempDir()
+	client, err := NewClient(
+		dir, "g-1",
+		WithSockNames("bird-"+GroupPlaceholder+".in", "bird-"+GroupPlaceholder+".out"),
+		WithSockMode(0o600),
+	)
+	require.NoError(t, err)
+	defer client.Shutdown()
+
+	stat, err := os.Stat(filepath.Join(dir, "bird-g-1.in"))
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), stat.Mode().Perm())
+	assert.Equal(t, filepath.Join(dir, "bird-g-1.out"), client.writeAddr.Name)
+}
+
+// TestNewClient_BatchTooLarge tests that the client rejects the batch size not
+// fitting into a single datagram.
+func TestNewClient_BatchTooLarge(t *testing.T) {
+	_, err := NewClient(t.TempDir(), "g-1", WithBatchSize(1<<30))
+	assert.ErrorIs(t, err, ErrBatchTooLarge)
+}
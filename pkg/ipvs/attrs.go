@@ -0,0 +1,327 @@
+package ipvs
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net/netip"
+	"syscall"
+
+	"github.com/yanet-platform/netlink"
+)
+
+// Generic netlink controller definitions.
+const (
+	genlIDCtrl         = 0x10
+	ctrlCmdGetFamily   = 3
+	ctrlAttrFamilyID   = 1
+	ctrlAttrFamilyName = 2
+	ctrlVersion        = 1
+	genlHeaderSize     = 4
+)
+
+// IPVS generic netlink family definitions.
+const (
+	familyName  = "IPVS"
+	ipvsVersion = 1
+
+	cmdNewService = 1
+	cmdSetService = 2
+	cmdDelService = 3
+	cmdGetService = 4
+	cmdNewDest    = 5
+	cmdSetDest    = 6
+	cmdDelDest    = 7
+	cmdGetDest    = 8
+
+	cmdAttrService = 1
+	cmdAttrDest    = 2
+
+	svcAttrAF        = 1
+	svcAttrProtocol  = 2
+	svcAttrAddr      = 3
+	svcAttrPort      = 4
+	svcAttrSchedName = 6
+	svcAttrFlags     = 7
+	svcAttrTimeout   = 8
+	svcAttrNetmask   = 9
+
+	destAttrAddr        = 1
+	destAttrPort        = 2
+	destAttrFwdMethod   = 3
+	destAttrWeight      = 4
+	destAttrUThresh     = 5
+	destAttrLThresh     = 6
+	destAttrAddrFamily  = 11
+	destAttrTunnelType  = 12
+	destAttrTunnelPort  = 13
+	destAttrTunnelFlags = 14
+
+	// fwdMethodMask extracts the forwarding method from the connection flags.
+	fwdMethodMask = 0x0007
+)
+
+// errInvalidAttributes is returned when the attributes of a reply are
+// malformed.
+var errInvalidAttributes = errors.New("invalid ipvs attributes")
+
+// genlHeader encodes the generic netlink header.
+func genlHeader(cmd, version uint8) []byte {
+	return []byte{cmd, version, 0, 0}
+}
+
+// resolveFamily resolves the identifier of the generic netlink family.
+func resolveFamily(conn *netlink.Conn, name string) (uint16, error) {
+	ae := netlink.NewAttributeEncoder()
+	ae.String(ctrlAttrFamilyName, name)
+	attrs, err := ae.Encode()
+	if err != nil {
+		return 0, err
+	}
+
+	msgs, err := conn.Execute(netlink.Message{
+		Header: netlink.Header{
+			Type:  genlIDCtrl,
+			Flags: netlink.Request,
+		},
+		Data: append(genlHeader(ctrlCmdGetFamily, ctrlVersion), attrs...),
+	})
+	if errors.Is(err, syscall.ENOENT) {
+		return 0, ErrFamilyNotFound
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to resolve %s family: %w", name, err)
+	}
+
+	for _, msg := range msgs {
+		if len(msg.Data) < genlHeaderSize {
+			continue
+		}
+		ad, err := netlink.NewAttributeDecoder(msg.Data[genlHeaderSize:])
+		if err != nil {
+			return 0, err
+		}
+		for ad.Next() {
+			if ad.Type() == ctrlAttrFamilyID {
+				return ad.Uint16(), nil
+			}
+		}
+		if err := ad.Err(); err != nil {
+			return 0, err
+		}
+	}
+	return 0, ErrFamilyNotFound
+}
+
+// encodeCommand encodes the attributes of the command on the service and,
+// optionally, its destination.
+func encodeCommand(service *Service, dest *Destination) ([]byte, error) {
+	ae := netlink.NewAttributeEncoder()
+	ae.Nested(cmdAttrService, func(nae *netlink.AttributeEncoder) error {
+		return encodeService(nae, service)
+	})
+	if dest != nil {
+		ae.Nested(cmdAttrDest, func(nae *netlink.AttributeEncoder) error {
+			return encodeDest(nae, service, dest)
+		})
+	}
+	return ae.Encode()
+}
+
+// encodeService encodes the service attributes.
+func encodeService(ae *netlink.AttributeEncoder, service *Service) error {
+	family, netmask, err := addrFamily(service.Addr)
+	if err != nil {
+		return err
+	}
+	ae.Uint16(svcAttrAF, family)
+	ae.Uint16(svcAttrProtocol, service.Protocol)
+	ae.Bytes(svcAttrAddr, encodeAddr(service.Addr))
+	ae.Bytes(svcAttrPort, binary.BigEndian.AppendUint16(nil, service.Port))
+	ae.String(svcAttrSchedName, service.Scheduler)
+	flags := make([]byte, 8)
+	binary.NativeEndian.PutUint32(flags[0:], service.Flags)
+	binary.NativeEndian.PutUint32(flags[4:], ^uint32(0))
+	ae.Bytes(svcAttrFlags, flags)
+	ae.Uint32(svcAttrTimeout, service.Timeout)
+	ae.Uint32(svcAttrNetmask, netmask)
+	return nil
+}
+
+// encodeDest encodes the destination attributes.
+func encodeDest(ae *netlink.AttributeEncoder, service *Service, dest *Destination) error {
+	family, _, err := addrFamily(dest.Addr)
+	if err != nil {
+		return err
+	}
+	ae.Bytes(destAttrAddr, encodeAddr(dest.Addr))
+	ae.Bytes(destAttrPort, binary.BigEndian.AppendUint16(nil, dest.Port))
+	ae.Uint32(destAttrFwdMethod, uint32(dest.ForwardMethod))
+	ae.Uint32(destAttrWeight, dest.Weight)
+	ae.Uint32(destAttrUThresh, 0)
+	ae.Uint32(destAttrLThresh, 0)
+	if dest.Addr.Is4() != service.Addr.Is4() || dest.ForwardMethod == ForwardTunnel {
+		// The address family is required for the mixed family tunnels.
+		ae.Uint16(destAttrAddrFamily, family)
+	}
+	if dest.ForwardMethod == ForwardTunnel && dest.TunnelType != TunnelIPIP {
+		ae.Uint8(destAttrTunnelType, uint8(dest.TunnelType))
+		ae.Bytes(destAttrTunnelPort, []byte{0, 0})
+		ae.Uint16(destAttrTunnelFlags, 0)
+	}
+	return nil
+}
+
+// decodeServiceMessage decodes the service from the generic netlink message.
+func decodeServiceMessage(data []byte) (Service, error) {
+	var service Service
+	err := decodeNested(data, cmdAttrService, func(ad *netlink.AttributeDecoder) error {
+		var family uint16
+		var addr []byte
+		for ad.Next() {
+			switch ad.Type() {
+			case svcAttrAF:
+				family = ad.Uint16()
+			case svcAttrProtocol:
+				service.Protocol = ad.Uint16()
+			case svcAttrAddr:
+				addr = ad.Bytes()
+			case svcAttrPort:
+				service.Port = decodePort(ad.Bytes())
+			case svcAttrSchedName:
+				service.Scheduler = ad.String()
+			case svcAttrFlags:
+				if flags := ad.Bytes(); len(flags) >= 4 {
+					service.Flags = binary.NativeEndian.Uint32(flags)
+				}
+			case svcAttrTimeout:
+				service.Timeout = ad.Uint32()
+			}
+		}
+		service.Addr = decodeAddr(family, addr)
+		return nil
+	})
+	return service, err
+}
+
+// decodeDestMessage decodes the destination from the generic netlink message.
+func decodeDestMessage(data []byte) (Destination, error) {
+	var dest Destination
+	err := decodeNested(data, cmdAttrDest, func(ad *netlink.AttributeDecoder) error {
+		var family uint16
+		var addr []byte
+		for ad.Next() {
+			switch ad.Type() {
+			case destAttrAddr:
+				addr = ad.Bytes()
+			case destAttrPort:
+				dest.Port = decodePort(ad.Bytes())
+			case destAttrFwdMethod:
+				dest.ForwardMethod = ForwardMethod(ad.Uint32() & fwdMethodMask)
+			case destAttrWeight:
+				dest.Weight = ad.Uint32()
+			case destAttrAddrFamily:
+				family = ad.Uint16()
+			case destAttrTunnelType:
+				dest.TunnelType = TunnelType(ad.Uint8())
+			}
+		}
+		if family == 0 {
+			// Old kernels do not report the family, the address is of the
+			// service family then.
+			family = syscall.AF_INET6
+			if isIPv4Mapped(addr) {
+				family = syscall.AF_INET
+			}
+		}
+		dest.Addr = decodeAddr(family, addr)
+		return nil
+	})
+	return dest, err
+}
+
+// decodeNested decodes the nested attribute of the given type of the generic
+// netlink message.
+func decodeNested(data []byte, typ uint16, fn func(ad *netlink.AttributeDecoder) error) error {
+	if len(data) < genlHeaderSize {
+		return errInvalidAttributes
+	}
+	ad, err := netlink.NewAttributeDecoder(data[genlHeaderSize:])
+	if err != nil {
+		return err
+	}
+	found := false
+	for ad.Next() {
+		if ad.Type() == typ {
+			found = true
+			ad.Nested(fn)
+		}
+	}
+	if err := ad.Err(); err != nil {
+		return fmt.Errorf("%w: %w", errInvalidAttributes, err)
+	}
+	if !found {
+		return fmt.Errorf("%w: attribute %d is missing", errInvalidAttributes, typ)
+	}
+	return nil
+}
+
+// addrFamily returns the address family and the netmask of the address.
+func addrFamily(addr netip.Addr) (family uint16, netmask uint32, err error) {
+	switch {
+	case addr.Is4():
+		return syscall.AF_INET, 0xffffffff, nil
+	case addr.Is6():
+		return syscall.AF_INET6, 128, nil
+	default:
+		return 0, 0, fmt.Errorf("invalid address %s", addr)
+	}
+}
+
+// encodeAddr encodes the address as the 16 bytes union of IPv4 and IPv6
+// addresses.
+func encodeAddr(addr netip.Addr) []byte {
+	buf := make([]byte, 16)
+	if addr.Is4() {
+		ipv4 := addr.As4()
+		copy(buf, ipv4[:])
+		return buf
+	}
+	ipv6 := addr.As16()
+	copy(buf, ipv6[:])
+	return buf
+}
+
+// decodeAddr decodes the address of the family.
+func decodeAddr(family uint16, buf []byte) netip.Addr {
+	switch {
+	case family == syscall.AF_INET && len(buf) >= 4:
+		return netip.AddrFrom4([4]byte(buf[:4]))
+	case family == syscall.AF_INET6 && len(buf) >= 16:
+		return netip.AddrFrom16([16]byte(buf[:16]))
+	default:
+		return netip.Addr{}
+	}
+}
+
+// isIPv4Mapped reports whether the address union holds the IPv4 address, i.e.
+// its trailing 12 bytes are zero.
+func isIPv4Mapped(buf []byte) bool {
+	if len(buf) < 16 {
+		return true
+	}
+	for _, b := range buf[4:16] {
+		if b != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// decodePort decodes the port in the network byte order.
+func decodePort(buf []byte) uint16 {
+	if len(buf) < 2 {
+		return 0
+	}
+	return binary.BigEndian.Uint16(buf)
+}
@@ -0,0 +1,228 @@
+// Package ipvs provides a client managing the Linux IPVS virtual services and
+// their destinations via the IPVS generic netlink family.
+package ipvs
+
+import (
+	"errors"
+	"fmt"
+	"net/netip"
+	"sync"
+	"syscall"
+
+	"github.com/yanet-platform/netlink"
+)
+
+// ErrFamilyNotFound is returned when the IPVS generic netlink family is not
+// registered, i.e. the ip_vs kernel module is not loaded.
+var ErrFamilyNotFound = errors.New("ipvs generic netlink family is not found")
+
+// ForwardMethod is the method the packets are forwarded to the destination
+// with.
+type ForwardMethod uint32
+
+const (
+	ForwardMasquerade ForwardMethod = 0 // NAT
+	ForwardTunnel     ForwardMethod = 2 // IP-in-IP or GRE tunnel
+	ForwardDirect     ForwardMethod = 3 // direct routing
+)
+
+// TunnelType is the type of the tunnel used by the [ForwardTunnel] method.
+type TunnelType uint8
+
+const (
+	TunnelIPIP TunnelType = 0
+	TunnelGRE  TunnelType = 2
+)
+
+// Service flags.
+const (
+	ServicePersistent uint32 = 0x0001
+	ServiceHashed     uint32 = 0x0002 // set by the kernel
+	ServiceOnePacket  uint32 = 0x0004
+)
+
+// Service is the IPVS virtual service. It is identified by the address, the
+// port and the protocol.
+type Service struct {
+	Addr      netip.Addr
+	Port      uint16
+	Protocol  uint16 // IP protocol number, e.g. syscall.IPPROTO_TCP
+	Scheduler string
+	Flags     uint32
+	Timeout   uint32 // persistence timeout in seconds
+}
+
+// Destination is the real server of the IPVS virtual service. It is
+// identified by the address and the port.
+type Destination struct {
+	Addr          netip.Addr
+	Port          uint16
+	ForwardMethod ForwardMethod
+	TunnelType    TunnelType
+	Weight        uint32
+}
+
+// Client manages the IPVS table via generic netlink.
+type Client struct {
+	conn   *netlink.Conn
+	family uint16     // IPVS generic netlink family identifier
+	mu     sync.Mutex // serializes the requests
+}
+
+// New creates a new Client connected to the IPVS generic netlink family.
+func New() (*Client, error) {
+	conn, err := netlink.Dial(syscall.NETLINK_GENERIC, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to dial netlink: %w", err)
+	}
+	client, err := NewWithConn(conn)
+	if err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	return client, nil
+}
+
+// NewWithConn creates a new Client using the generic netlink connection. It
+// allows to use the connection stubs in tests.
+func NewWithConn(conn *netlink.Conn) (*Client, error) {
+	family, err := resolveFamily(conn, familyName)
+	if err != nil {
+		return nil, err
+	}
+	return &Client{conn: conn, family: family}, nil
+}
+
+// Close closes the netlink connection.
+func (m *Client) Close() error {
+	return m.conn.Close()
+}
+
+// Services returns all virtual services of the IPVS table.
+func (m *Client) Services() ([]Service, error) {
+	msgs, err := m.execute(cmdGetService, netlink.Dump, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get services: %w", err)
+	}
+	services := make([]Service, 0, len(msgs))
+	for _, msg := range msgs {
+		service, err := decodeServiceMessage(msg.Data)
+		if err != nil {
+			return nil, err
+		}
+		services = append(services, service)
+	}
+	return services, nil
+}
+
+// NewService adds the virtual service.
+func (m *Client) NewService(service Service) error {
+	attrs, err := encodeCommand(&service, nil)
+	if err != nil {
+		return err
+	}
+	if _, err := m.execute(cmdNewService, netlink.Acknowledge, attrs); err != nil {
+		return fmt.Errorf("failed to add service %s: %w", service, err)
+	}
+	return nil
+}
+
+// UpdateService updates the scheduler, the flags and the timeout of the
+// virtual service.
+func (m *Client) UpdateService(service Service) error {
+	attrs, err := encodeCommand(&service, nil)
+	if err != nil {
+		return err
+	}
+	if _, err := m.execute(cmdSetService, netlink.Acknowledge, attrs); err != nil {
+		return fmt.Errorf("failed to update service %s: %w", service, err)
+	}
+	return nil
+}
+
+// RemoveService removes the virtual service with all its destinations.
+func (m *Client) RemoveService(service Service) error {
+	attrs, err := encodeCommand(&service, nil)
+	if err != nil {
+		return err
+	}
+	if _, err := m.execute(cmdDelService, netlink.Acknowledge, attrs); err != nil {
+		return fmt.Errorf("failed to remove service %s: %w", service, err)
+	}
+	return nil
+}
+
+// Destinations returns the destinations of the virtual service.
+func (m *Client) Destinations(service Service) ([]Destination, error) {
+	attrs, err := encodeCommand(&service, nil)
+	if err != nil {
+		return nil, err
+	}
+	msgs, err := m.execute(cmdGetDest, netlink.Dump, attrs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get destinations of %s: %w", service, err)
+	}
+	dests := make([]Destination, 0, len(msgs))
+	for _, msg := range msgs {
+		dest, err := decodeDestMessage(msg.Data)
+		if err != nil {
+			return nil, err
+		}
+		dests = append(dests, dest)
+	}
+	return dests, nil
+}
+
+// SetDestination adds the destination to the virtual service or updates it if
+// it already exists.
+func (m *Client) SetDestination(service Service, dest Destination) error {
+	attrs, err := encodeCommand(&service, &dest)
+	if err != nil {
+		return err
+	}
+	_, err = m.execute(cmdNewDest, netlink.Acknowledge, attrs)
+	if errors.Is(err, syscall.EEXIST) {
+		_, err = m.execute(cmdSetDest, netlink.Acknowledge, attrs)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to set destination %s of %s: %w", dest, service, err)
+	}
+	return nil
+}
+
+// RemoveDestination removes the destination from the virtual service. Removal
+// of the missing destination is not an error.
+func (m *Client) RemoveDestination(service Service, dest Destination) error {
+	attrs, err := encodeCommand(&service, &dest)
+	if err != nil {
+		return err
+	}
+	_, err = m.execute(cmdDelDest, netlink.Acknowledge, attrs)
+	if err != nil && !errors.Is(err, syscall.ENOENT) {
+		return fmt.Errorf("failed to remove destination %s of %s: %w", dest, service, err)
+	}
+	return nil
+}
+
+// execute sends the IPVS command and returns the replies.
+func (m *Client) execute(cmd uint8, flags netlink.HeaderFlags, attrs []byte) ([]netlink.Message, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.conn.Execute(netlink.Message{
+		Header: netlink.Header{
+			Type:  netlink.HeaderType(m.family),
+			Flags: netlink.Request | flags,
+		},
+		Data: append(genlHeader(cmd, ipvsVersion), attrs...),
+	})
+}
+
+// String returns the service in the address:port/protocol form.
+func (m Service) String() string {
+	return fmt.Sprintf("%s/%d", netip.AddrPortFrom(m.Addr, m.Port), m.Protocol)
+}
+
+// String returns the destination in the address:port form.
+func (m Destination) String() string {
+	return netip.AddrPortFrom(m.Addr, m.Port).String()
+}
@@ -0,0 +1,206 @@
+package ipvs
+
+import (
+	"io"
+	"net/netip"
+	"sync"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yanet-platform/netlink"
+	"github.com/yanet-platform/netlink/nltest"
+)
+
+// testFamily is the identifier of the IPVS family assigned by the stub.
+const testFamily = 0x20
+
+// testKernel is the stub of the kernel IPVS table served over the generic
+// netlink.
+type testKernel struct {
+	services map[netip.AddrPort]Service
+	dests    map[netip.AddrPort]map[netip.AddrPort]Destination
+	mu       sync.Mutex
+}
+
+func newTestClient(t *testing.T) (*Client, *testKernel) {
+	kernel := &testKernel{
+		services: make(map[netip.AddrPort]Service),
+		dests:    make(map[netip.AddrPort]map[netip.AddrPort]Destination),
+	}
+	client, err := NewWithConn(nltest.Dial(kernel.handle))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = client.Close() })
+	return client, kernel
+}
+
+func (m *testKernel) handle(reqs []netlink.Message) ([]netlink.Message, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	req := reqs[0]
+	if req.Header.Type == genlIDCtrl {
+		ae := netlink.NewAttributeEncoder()
+		ae.Uint16(ctrlAttrFamilyID, testFamily)
+		attrs, err := ae.Encode()
+		if err != nil {
+			return nil, err
+		}
+		return []netlink.Message{{Header: req.Header, Data: append(genlHeader(1, ctrlVersion), attrs...)}}, nil
+	}
+
+	cmd := req.Data[0]
+	if cmd == cmdGetService {
+		var msgs []netlink.Message
+		for _, service := range m.services {
+			attrs, err := encodeCommand(&service, nil)
+			if err != nil {
+				return nil, err
+			}
+			msgs = append(msgs, netlink.Message{Header: req.Header, Data: append(genlHeader(cmd, ipvsVersion), attrs...)})
+		}
+		return dumpReply(msgs)
+	}
+
+	service, err := decodeServiceMessage(req.Data)
+	if err != nil {
+		return nil, err
+	}
+	serviceKey := netip.AddrPortFrom(service.Addr, service.Port)
+	known, exists := m.services[serviceKey]
+
+	errno := 0
+	switch cmd {
+	case cmdNewService:
+		if exists {
+			return nltest.Error(int(syscall.EEXIST), reqs)
+		}
+		m.services[serviceKey] = service
+		m.dests[serviceKey] = make(map[netip.AddrPort]Destination)
+	case cmdSetService:
+		if !exists {
+			return nltest.Error(int(syscall.ESRCH), reqs)
+		}
+		m.services[serviceKey] = service
+	case cmdDelService:
+		if !exists {
+			return nltest.Error(int(syscall.ESRCH), reqs)
+		}
+		delete(m.services, serviceKey)
+		delete(m.dests, serviceKey)
+	case cmdGetDest:
+		if !exists {
+			return nltest.Error(int(syscall.ESRCH), reqs)
+		}
+		var msgs []netlink.Message
+		for _, dest := range m.dests[serviceKey] {
+			attrs, err := encodeCommand(&known, &dest)
+			if err != nil {
+				return nil, err
+			}
+			msgs = append(msgs, netlink.Message{Header: req.Header, Data: append(genlHeader(cmd, ipvsVersion), attrs...)})
+		}
+		return dumpReply(msgs)
+	case cmdNewDest, cmdSetDest, cmdDelDest:
+		if !exists {
+			return nltest.Error(int(syscall.ESRCH), reqs)
+		}
+		dest, err := decodeDestMessage(req.Data)
+		if err != nil {
+			return nil, err
+		}
+		destKey := netip.AddrPortFrom(dest.Addr, dest.Port)
+		_, destExists := m.dests[serviceKey][destKey]
+		switch {
+		case cmd == cmdNewDest && destExists:
+			errno = int(syscall.EEXIST)
+		case cmd != cmdNewDest && !destExists:
+			errno = int(syscall.ENOENT)
+		case cmd == cmdDelDest:
+			delete(m.dests[serviceKey], destKey)
+		default:
+			m.dests[serviceKey][destKey] = dest
+		}
+	}
+	// Zero error number is the acknowledgement.
+	return nltest.Error(errno, reqs)
+}
+
+// dumpReply replies to the dump request with the messages.
+func dumpReply(msgs []netlink.Message) ([]netlink.Message, error) {
+	if len(msgs) == 0 {
+		return nil, io.EOF
+	}
+	if len(msgs) == 1 {
+		// Multipart does not mark a single message.
+		msgs = append(msgs, netlink.Message{Header: msgs[0].Header})
+	}
+	return nltest.Multipart(msgs)
+}
+
+// TestClient_Services tests that the virtual services are added, reported,
+// updated and removed.
+func TestClient_Services(t *testing.T) {
+	client, _ := newTestClient(t)
+
+	service := Service{
+		Addr:      netip.MustParseAddr("2001:db8::1"),
+		Port:      443,
+		Protocol:  syscall.IPPROTO_TCP,
+		Scheduler: "wrr",
+	}
+	require.NoError(t, client.NewService(service))
+	assert.ErrorIs(t, client.NewService(service), syscall.EEXIST)
+
+	services, err := client.Services()
+	require.NoError(t, err)
+	assert.Equal(t, []Service{service}, services)
+
+	service.Scheduler = "mh"
+	service.Flags = ServiceOnePacket
+	require.NoError(t, client.UpdateService(service))
+	services, err = client.Services()
+	require.NoError(t, err)
+	assert.Equal(t, []Service{service}, services)
+
+	require.NoError(t, client.RemoveService(service))
+	services, err = client.Services()
+	require.NoError(t, err)
+	assert.Empty(t, services)
+}
+
+// TestClient_Destinations tests that the destinations are added, updated and
+// removed, and the removal of the missing destination is not an error.
+func TestClient_Destinations(t *testing.T) {
+	client, _ := newTestClient(t)
+
+	service := Service{
+		Addr:      netip.MustParseAddr("10.0.0.1"),
+		Port:      80,
+		Protocol:  syscall.IPPROTO_UDP,
+		Scheduler: "wrr",
+	}
+	require.NoError(t, client.NewService(service))
+
+	dest := Destination{
+		Addr:          netip.MustParseAddr("2001:db8::2"),
+		Port:          8080,
+		ForwardMethod: ForwardTunnel,
+		TunnelType:    TunnelGRE,
+		Weight:        5,
+	}
+	require.NoError(t, client.SetDestination(service, dest))
+	dest.Weight = 10
+	require.NoError(t, client.SetDestination(service, dest))
+
+	dests, err := client.Destinations(service)
+	require.NoError(t, err)
+	assert.Equal(t, []Destination{dest}, dests)
+
+	require.NoError(t, client.RemoveDestination(service, dest))
+	require.NoError(t, client.RemoveDestination(service, dest))
+	dests, err = client.Destinations(service)
+	require.NoError(t, err)
+	assert.Empty(t, dests)
+}
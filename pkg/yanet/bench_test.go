@@ -0,0 +1,50 @@
+package yanet
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	yanetpb "github.com/yanet-platform/monalive/gen/yanet"
+)
+
+// benchmarkReal measures the throughput of the concurrent Real calls to the
+// control plane stub processing each request for the delay.
+func benchmarkReal(b *testing.B, delay time.Duration, opts ...ClientOption) {
+	server := newTestServer(b, filepath.Join(b.TempDir(), "yanet.sock"))
+	server.delay = delay
+
+	client, err := NewClient(append([]ClientOption{WithControlPlaneSockPath(server.sockPath)}, opts...)...)
+	require.NoError(b, err)
+	defer client.Close()
+
+	request := &yanetpb.BalancerRealRequest{
+		Reals: []*yanetpb.BalancerRealRequest_Real{{Module: "balancer0", Enable: true}},
+	}
+	b.SetParallelism(4)
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			if _, err := client.Real(context.Background(), request); err != nil {
+				b.Error(err)
+				return
+			}
+		}
+	})
+}
+
+func BenchmarkClient_Real(b *testing.B) {
+	for _, delay := range []time.Duration{0, 100 * time.Microsecond} {
+		b.Run("delay="+delay.String(), func(b *testing.B) {
+			b.Run("pool=1", func(b *testing.B) {
+				benchmarkReal(b, delay)
+			})
+			b.Run("pool=4", func(b *testing.B) {
+				benchmarkReal(b, delay, WithPoolSize(4))
+			})
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package fake
+
+import (
+	"cmp"
+	"encoding/binary"
+	"net/netip"
+	"strings"
+
+	yanetpb "github.com/yanet-platform/monalive/gen/yanet"
+)
+
+// toProtoAddr converts a [netip.Addr] to a [yanetpb.IPAddr].
+func toProtoAddr(addr netip.Addr) *yanetpb.IPAddr {
+	if addr.Is4() {
+		return &yanetpb.IPAddr{Addr: &yanetpb.IPAddr_Ipv4{Ipv4: binary.BigEndian.Uint32(addr.AsSlice())}}
+	}
+	return &yanetpb.IPAddr{Addr: &yanetpb.IPAddr_Ipv6{Ipv6: addr.AsSlice()}}
+}
+
+// fromProtoAddr converts a [yanetpb.IPAddr] to a [netip.Addr]. It returns the
+// zero address if the address is malformed.
+func fromProtoAddr(addr *yanetpb.IPAddr) netip.Addr {
+	if bytes := addr.GetIpv6(); bytes != nil {
+		ip, _ := netip.AddrFromSlice(bytes)
+		return ip
+	}
+	var ipv4 [4]byte
+	binary.BigEndian.PutUint32(ipv4[:], addr.GetIpv4())
+	return netip.AddrFrom4(ipv4)
+}
+
+// CompareKeys orders the reals by module, virtual service and real server.
+func CompareKeys(a, b RealKey) int {
+	return cmp.Or(
+		strings.Compare(a.Module, b.Module),
+		a.VirtualIP.Compare(b.VirtualIP),
+		cmp.Compare(a.VirtualPort, b.VirtualPort),
+		cmp.Compare(a.Proto, b.Proto),
+		a.RealIP.Compare(b.RealIP),
+		cmp.Compare(a.RealPort, b.RealPort),
+	)
+}
+
+// sameService reports whether the reals belong to the same virtual service.
+func sameService(a, b RealKey) bool {
+	return a.Module == b.Module && a.VirtualIP == b.VirtualIP && a.VirtualPort == b.VirtualPort && a.Proto == b.Proto
+}
+
+// Service formats the virtual service of the real.
+func (m RealKey) Service() string {
+	return formatAddrPort(m.VirtualIP, m.VirtualPort) + "/" + strings.ToUpper(m.Proto.String())
+}
+
+// Real formats the address of the real.
+func (m RealKey) Real() string {
+	return formatAddrPort(m.RealIP, m.RealPort)
+}
+
+// formatAddrPort formats the address with the port unless it is omitted.
+func formatAddrPort(addr netip.Addr, port uint16) string {
+	if port == 0 {
+		return addr.String()
+	}
+	return netip.AddrPortFrom(addr, port).String()
+}
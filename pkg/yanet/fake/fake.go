@@ -0,0 +1,431 @@
+// Package fake provides an in-process fake of the YANET control plane. It
+// speaks the same length-prefixed RpcMeta and protobuf framing on a Unix domain
+// socket as the YANET control plane and implements the Real, RealFlush and
+// RealFind methods of the balancer service over an in-memory table of reals.
+//
+// It is intended for the tests and the local development, so Monalive can be
+// run end-to-end without YANET.
+package fake
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net"
+	"net/netip"
+	"slices"
+	"sync"
+
+	log "go.uber.org/zap"
+	"google.golang.org/protobuf/proto"
+
+	yanetpb "github.com/yanet-platform/monalive/gen/yanet"
+)
+
+// DefaultModule is the balancer module served if no modules are set.
+const DefaultModule = "balancer0"
+
+// RealKey identifies the real server of the virtual service in the balancer
+// module. The zero ports mean that the port is omitted.
+type RealKey struct {
+	Module      string
+	VirtualIP   netip.Addr
+	VirtualPort uint16
+	Proto       yanetpb.NetProto
+	RealIP      netip.Addr
+	RealPort    uint16
+}
+
+// RealState is the state of the real server.
+type RealState struct {
+	Enabled bool
+	Weight  uint32
+}
+
+// Server is a fake YANET control plane.
+//
+// The real updates are staged by the Real method and applied to the table by
+// the RealFlush method, as YANET does. Unlike YANET, the reals unknown to the
+// table are added on flush, unless the server is strict. The updates of the
+// modules the server does not serve are ignored.
+type Server struct {
+	modules []string
+	strict  bool
+
+	table   map[RealKey]RealState // applied states of the reals
+	pending map[RealKey]RealState // staged states of the reals
+	calls   map[string]int        // number of the calls per method
+	mu      sync.Mutex            // protects the maps
+
+	listener net.Listener
+	conns    map[net.Conn]struct{}
+	closed   bool
+	connsMu  sync.Mutex
+	wg       sync.WaitGroup
+
+	log *log.Logger
+}
+
+// Option represents an option for configuring the Server.
+type Option func(*Server)
+
+// WithModules sets the balancer modules served. Defaults to [DefaultModule].
+func WithModules(modules ...string) Option {
+	return func(s *Server) {
+		s.modules = slices.Clone(modules)
+	}
+}
+
+// WithStrict makes the server ignore the updates of the reals unknown to the
+// table, as YANET ignores the reals missing in its configuration.
+func WithStrict() Option {
+	return func(s *Server) {
+		s.strict = true
+	}
+}
+
+// WithLogger sets the logger of the applied updates.
+func WithLogger(logger *log.Logger) Option {
+	return func(s *Server) {
+		s.log = logger
+	}
+}
+
+// New creates a new Server with the empty table.
+func New(opts ...Option) *Server {
+	server := &Server{
+		modules: []string{DefaultModule},
+		table:   make(map[RealKey]RealState),
+		pending: make(map[RealKey]RealState),
+		calls:   make(map[string]int),
+		conns:   make(map[net.Conn]struct{}),
+		log:     log.NewNop(),
+	}
+	for _, opt := range opts {
+		opt(server)
+	}
+	return server
+}
+
+// Start creates a new Server listening on the Unix domain socket path and
+// serves it in the background until Close is called.
+func Start(sockPath string, opts ...Option) (*Server, error) {
+	listener, err := net.Listen("unix", sockPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen: %w", err)
+	}
+	server := New(opts...)
+	server.listener = listener
+	server.wg.Add(1)
+	go func() {
+		defer server.wg.Done()
+		_ = server.serve(listener)
+	}()
+	return server, nil
+}
+
+// Serve accepts the connections on the listener and serves them until the
+// listener is closed. It always returns a non-nil error.
+func (m *Server) Serve(listener net.Listener) error {
+	m.connsMu.Lock()
+	m.listener = listener
+	m.connsMu.Unlock()
+	return m.serve(listener)
+}
+
+// Close closes the listener and all served connections.
+func (m *Server) Close() error {
+	m.connsMu.Lock()
+	m.closed = true
+	var err error
+	if m.listener != nil {
+		err = m.listener.Close()
+	}
+	for conn := range m.conns {
+		_ = conn.Close()
+	}
+	m.connsMu.Unlock()
+
+	m.wg.Wait()
+	return err
+}
+
+// SetReal sets the applied state of the real, adding it to the table if it
+// is unknown. It allows to simulate the changes made bypassing Monalive.
+func (m *Server) SetReal(key RealKey, state RealState) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.table[key] = state
+}
+
+// DeleteReal removes the real from the table.
+func (m *Server) DeleteReal(key RealKey) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.table, key)
+}
+
+// Real returns the applied state of the real.
+func (m *Server) Real(key RealKey) (RealState, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	state, exists := m.table[key]
+	return state, exists
+}
+
+// Table returns the copy of the applied states of the reals.
+func (m *Server) Table() map[RealKey]RealState {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return maps.Clone(m.table)
+}
+
+// Pending returns the number of the staged updates not flushed yet.
+func (m *Server) Pending() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.pending)
+}
+
+// Calls returns the number of the calls of the method.
+func (m *Server) Calls(method string) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.calls[method]
+}
+
+// serve accepts the connections on the listener and serves each of them in a
+// separate goroutine.
+func (m *Server) serve(listener net.Listener) error {
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			return err
+		}
+
+		m.connsMu.Lock()
+		if m.closed {
+			m.connsMu.Unlock()
+			_ = conn.Close()
+			return net.ErrClosed
+		}
+		m.conns[conn] = struct{}{}
+		m.connsMu.Unlock()
+
+		m.wg.Add(1)
+		go func() {
+			defer m.wg.Done()
+			m.serveConn(conn)
+
+			m.connsMu.Lock()
+			delete(m.conns, conn)
+			m.connsMu.Unlock()
+		}()
+	}
+}
+
+// serveConn serves the requests of the connection sequentially until it is
+// closed or a malformed request is received.
+func (m *Server) serveConn(conn net.Conn) {
+	defer conn.Close()
+	for {
+		response, err := m.handle(conn)
+		if err != nil {
+			if !errors.Is(err, io.EOF) && !errors.Is(err, net.ErrClosed) {
+				m.log.Warn("failed to serve request", log.Error(err))
+			}
+			return
+		}
+		if err := writeFrame(conn, response); err != nil {
+			return
+		}
+	}
+}
+
+// handle reads the request from the connection and processes it.
+func (m *Server) handle(conn net.Conn) (proto.Message, error) {
+	var meta yanetpb.RpcMeta
+	if err := readFrame(conn, &meta); err != nil {
+		return nil, err
+	}
+
+	switch method := meta.GetMethodName(); method {
+	case "Real":
+		var request yanetpb.BalancerRealRequest
+		if err := readFrame(conn, &request); err != nil {
+			return nil, err
+		}
+		m.real(&request)
+		return &yanetpb.Empty{}, nil
+
+	case "RealFlush":
+		if err := readFrame(conn, &yanetpb.Empty{}); err != nil {
+			return nil, err
+		}
+		m.flush()
+		return &yanetpb.Empty{}, nil
+
+	case "RealFind":
+		var request yanetpb.BalancerRealFindRequest
+		if err := readFrame(conn, &request); err != nil {
+			return nil, err
+		}
+		return m.find(&request), nil
+
+	default:
+		return nil, fmt.Errorf("unknown method %q", method)
+	}
+}
+
+// real stages the updates of the reals.
+func (m *Server) real(request *yanetpb.BalancerRealRequest) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.calls["Real"]++
+
+	for _, real := range request.GetReals() {
+		if !slices.Contains(m.modules, real.GetModule()) {
+			continue
+		}
+		key := RealKey{
+			Module:      real.GetModule(),
+			VirtualIP:   fromProtoAddr(real.GetVirtualIp()),
+			VirtualPort: uint16(real.GetVirtualPort()),
+			Proto:       real.GetProto(),
+			RealIP:      fromProtoAddr(real.GetRealIp()),
+			RealPort:    uint16(real.GetRealPort()),
+		}
+		state := RealState{Enabled: real.GetEnable()}
+		if _, exists := real.GetWeightOpt().(*yanetpb.BalancerRealRequest_Real_Weight); exists {
+			state.Weight = real.GetWeight()
+		} else {
+			// The weight is kept if it is omitted.
+			state.Weight = m.table[key].Weight
+		}
+		m.pending[key] = state
+	}
+}
+
+// flush applies the staged updates to the table.
+func (m *Server) flush() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.calls["RealFlush"]++
+
+	for key, state := range m.pending {
+		if _, exists := m.table[key]; !exists && m.strict {
+			continue
+		}
+		m.table[key] = state
+		m.log.Info(
+			"real is updated",
+			log.String("module", key.Module),
+			log.String("service", key.Service()),
+			log.String("real", key.Real()),
+			log.Bool("enabled", state.Enabled),
+			log.Uint32("weight", state.Weight),
+		)
+	}
+	clear(m.pending)
+}
+
+// find returns the reals of the table matching the request. All served modules
+// are reported, even if they have no reals.
+func (m *Server) find(request *yanetpb.BalancerRealFindRequest) *yanetpb.BalancerRealFindResponse {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.calls["RealFind"]++
+
+	keys := slices.SortedFunc(maps.Keys(m.table), CompareKeys)
+
+	response := &yanetpb.BalancerRealFindResponse{}
+	for id, module := range m.modules {
+		if request.GetModule() != "" && request.GetModule() != module {
+			continue
+		}
+		balancer := &yanetpb.BalancerRealFindResponse_Balancer{
+			BalancerId: uint32(id + 1),
+			Module:     module,
+		}
+
+		var service *yanetpb.BalancerRealFindResponse_Service
+		var serviceKey RealKey
+		for _, key := range keys {
+			if key.Module != module || !matches(request, key) {
+				continue
+			}
+			if service == nil || !sameService(serviceKey, key) {
+				service = &yanetpb.BalancerRealFindResponse_Service{
+					Key: &yanetpb.BalancerRealFindResponse_ServiceKey{
+						Ip:    toProtoAddr(key.VirtualIP),
+						Proto: key.Proto,
+					},
+				}
+				if key.VirtualPort != 0 {
+					service.Key.PortOpt = &yanetpb.BalancerRealFindResponse_ServiceKey_Port{Port: uint32(key.VirtualPort)}
+				}
+				serviceKey = key
+				balancer.Services = append(balancer.Services, service)
+			}
+
+			state := m.table[key]
+			real := &yanetpb.BalancerRealFindResponse_Real{
+				Ip:      toProtoAddr(key.RealIP),
+				Enabled: state.Enabled,
+				Weight:  state.Weight,
+			}
+			if key.RealPort != 0 {
+				real.PortOpt = &yanetpb.BalancerRealFindResponse_Real_Port{Port: uint32(key.RealPort)}
+			}
+			service.Reals = append(service.Reals, real)
+		}
+		response.Balancers = append(response.Balancers, balancer)
+	}
+	return response
+}
+
+// matches reports whether the real matches the filters of the request.
+func matches(request *yanetpb.BalancerRealFindRequest, key RealKey) bool {
+	if ip := request.GetVirtualIp(); ip != nil && fromProtoAddr(ip) != key.VirtualIP {
+		return false
+	}
+	if proto := request.GetProto(); proto != yanetpb.NetProto_undefined && proto != key.Proto {
+		return false
+	}
+	if _, set := request.GetVirtualPortOpt().(*yanetpb.BalancerRealFindRequest_VirtualPort); set && uint16(request.GetVirtualPort()) != key.VirtualPort {
+		return false
+	}
+	if ip := request.GetRealIp(); ip != nil && fromProtoAddr(ip) != key.RealIP {
+		return false
+	}
+	if _, set := request.GetRealPortOpt().(*yanetpb.BalancerRealFindRequest_RealPort); set && uint16(request.GetRealPort()) != key.RealPort {
+		return false
+	}
+	return true
+}
+
+// readFrame reads the length-prefixed protobuf message from r.
+func readFrame(r io.Reader, message proto.Message) error {
+	var sizeBuf [8]byte
+	if _, err := io.ReadFull(r, sizeBuf[:]); err != nil {
+		return err
+	}
+	buf := make([]byte, binary.LittleEndian.Uint64(sizeBuf[:]))
+	if _, err := io.ReadFull(r, buf); err != nil {
+		return err
+	}
+	return proto.Unmarshal(buf, message)
+}
+
+// writeFrame writes the length-prefixed protobuf message to w.
+func writeFrame(w io.Writer, message proto.Message) error {
+	messageBuf, err := proto.Marshal(message)
+	if err != nil {
+		return err
+	}
+	buf := binary.LittleEndian.AppendUint64(nil, uint64(len(messageBuf)))
+	_, err = w.Write(append(buf, messageBuf...))
+	return err
+}
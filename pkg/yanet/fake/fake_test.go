@@ -0,0 +1,143 @@
+package fake
+
+import (
+	"context"
+	"net/netip"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	yanetpb "github.com/yanet-platform/monalive/gen/yanet"
+	"github.com/yanet-platform/monalive/pkg/yanet"
+)
+
+// startServer starts the fake control plane and creates the client connected
+// to it.
+func startServer(t *testing.T, opts ...Option) (*Server, *yanet.Client) {
+	sockPath := filepath.Join(t.TempDir(), "yanet.sock")
+	server, err := Start(sockPath, opts...)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = server.Close() })
+
+	client, err := yanet.NewClient(yanet.WithControlPlaneSockPath(sockPath))
+	require.NoError(t, err)
+	return server, client
+}
+
+// realRequest creates the request to update the real of the test service.
+func realRequest(module string, realIP netip.Addr, enable bool, weight uint32) *yanetpb.BalancerRealRequest_Real {
+	return &yanetpb.BalancerRealRequest_Real{
+		Module:         module,
+		VirtualIp:      toProtoAddr(netip.MustParseAddr("10.0.0.1")),
+		VirtualPortOpt: &yanetpb.BalancerRealRequest_Real_VirtualPort{VirtualPort: 80},
+		Proto:          yanetpb.NetProto_tcp,
+		RealIp:         toProtoAddr(realIP),
+		Enable:         enable,
+		WeightOpt:      &yanetpb.BalancerRealRequest_Real_Weight{Weight: weight},
+	}
+}
+
+// TestServer_RealFlush tests that the real updates are staged until the flush,
+// and the updates of the unknown modules are ignored.
+func TestServer_RealFlush(t *testing.T) {
+	server, client := startServer(t)
+	ctx := context.Background()
+
+	_, err := client.Real(ctx, &yanetpb.BalancerRealRequest{
+		Reals: []*yanetpb.BalancerRealRequest_Real{
+			realRequest(DefaultModule, netip.MustParseAddr("10.1.0.1"), true, 10),
+			realRequest("unknown", netip.MustParseAddr("10.1.0.2"), true, 10),
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 1, server.Pending())
+	assert.Empty(t, server.Table())
+
+	_, err = client.RealFlush(ctx, &yanetpb.Empty{})
+	require.NoError(t, err)
+	assert.Equal(t, 0, server.Pending())
+
+	key := RealKey{
+		Module:      DefaultModule,
+		VirtualIP:   netip.MustParseAddr("10.0.0.1"),
+		VirtualPort: 80,
+		Proto:       yanetpb.NetProto_tcp,
+		RealIP:      netip.MustParseAddr("10.1.0.1"),
+	}
+	assert.Equal(t, map[RealKey]RealState{key: {Enabled: true, Weight: 10}}, server.Table())
+	assert.Equal(t, 1, server.Calls("Real"))
+	assert.Equal(t, 1, server.Calls("RealFlush"))
+}
+
+// TestServer_Strict tests that the strict server ignores the updates of the
+// reals unknown to the table.
+func TestServer_Strict(t *testing.T) {
+	server, client := startServer(t, WithStrict())
+	ctx := context.Background()
+
+	known := RealKey{
+		Module:      DefaultModule,
+		VirtualIP:   netip.MustParseAddr("10.0.0.1"),
+		VirtualPort: 80,
+		Proto:       yanetpb.NetProto_tcp,
+		RealIP:      netip.MustParseAddr("10.1.0.1"),
+	}
+	server.SetReal(known, RealState{Weight: 1})
+
+	_, err := client.Real(ctx, &yanetpb.BalancerRealRequest{
+		Reals: []*yanetpb.BalancerRealRequest_Real{
+			realRequest(DefaultModule, known.RealIP, true, 5),
+			realRequest(DefaultModule, netip.MustParseAddr("10.1.0.2"), true, 5),
+		},
+	})
+	require.NoError(t, err)
+	_, err = client.RealFlush(ctx, &yanetpb.Empty{})
+	require.NoError(t, err)
+
+	assert.Equal(t, map[RealKey]RealState{known: {Enabled: true, Weight: 5}}, server.Table())
+}
+
+// TestServer_RealFind tests that the table is reported grouped by modules and
+// services, the served modules are reported even if they have no reals, and
+// the request filters are applied.
+func TestServer_RealFind(t *testing.T) {
+	server, client := startServer(t, WithModules("balancer0", "balancer1"))
+	ctx := context.Background()
+
+	service := RealKey{
+		Module:      "balancer0",
+		VirtualIP:   netip.MustParseAddr("10.0.0.1"),
+		VirtualPort: 80,
+		Proto:       yanetpb.NetProto_tcp,
+	}
+	for i, addr := range []string{"10.1.0.1", "10.1.0.2", "2001:db8::1"} {
+		key := service
+		key.RealIP = netip.MustParseAddr(addr)
+		server.SetReal(key, RealState{Enabled: i%2 == 0, Weight: uint32(i)})
+	}
+
+	response, err := client.RealFind(ctx, &yanetpb.BalancerRealFindRequest{})
+	require.NoError(t, err)
+	require.Len(t, response.Balancers, 2)
+	assert.Equal(t, "balancer0", response.Balancers[0].Module)
+	require.Len(t, response.Balancers[0].Services, 1)
+	reals := response.Balancers[0].Services[0].Reals
+	require.Len(t, reals, 3)
+	assert.Equal(t, netip.MustParseAddr("2001:db8::1"), fromProtoAddr(reals[2].Ip))
+	assert.True(t, reals[2].Enabled)
+	assert.Equal(t, uint32(2), reals[2].Weight)
+	assert.Equal(t, "balancer1", response.Balancers[1].Module)
+	assert.Empty(t, response.Balancers[1].Services)
+
+	response, err = client.RealFind(ctx, &yanetpb.BalancerRealFindRequest{
+		Module: "balancer0",
+		RealIp: toProtoAddr(netip.MustParseAddr("10.1.0.2")),
+	})
+	require.NoError(t, err)
+	require.Len(t, response.Balancers, 1)
+	require.Len(t, response.Balancers[0].Services, 1)
+	require.Len(t, response.Balancers[0].Services[0].Reals, 1)
+	assert.False(t, response.Balancers[0].Services[0].Reals[0].Enabled)
+}
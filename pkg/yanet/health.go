@@ -0,0 +1,90 @@
+package yanet
+
+import (
+	"sync"
+	"time"
+)
+
+// Health represents the health of the connection to the YANET control plane.
+type Health struct {
+	// Connected reports whether the last call to the control plane succeeded.
+	Connected bool
+	// LastError is the error of the last failed call. It is kept after the
+	// connection is restored.
+	LastError error
+	// Since is the time the connection health changed last.
+	Since time.Time
+}
+
+// health tracks the health of the connections to the control plane. It is
+// shared by the connections of all methods.
+type health struct {
+	state Health
+	mu    sync.Mutex
+}
+
+// newHealth creates a new health tracker. The connection is considered healthy
+// initially, as the connections are dialed on the client creation.
+func newHealth() *health {
+	return &health{
+		state: Health{Connected: true, Since: time.Now()},
+	}
+}
+
+// succeeded marks the connection healthy.
+func (m *health) succeeded() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if !m.state.Connected {
+		m.state.Connected = true
+		m.state.Since = time.Now()
+	}
+}
+
+// failed marks the connection unhealthy with the error.
+func (m *health) failed(err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.state.LastError = err
+	if m.state.Connected {
+		m.state.Connected = false
+		m.state.Since = time.Now()
+	}
+}
+
+// get returns the current health.
+func (m *health) get() Health {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.state
+}
+
+// backoff delays the reconnection attempts exponentially.
+type backoff struct {
+	minDelay time.Duration
+	maxDelay time.Duration
+	delay    time.Duration // delay after the last failed attempt, zero if none
+	retry    time.Time     // time the next attempt is allowed at
+}
+
+// newBackoff creates a new backoff with the initial and the maximal delays.
+func newBackoff(minDelay, maxDelay time.Duration) *backoff {
+	return &backoff{minDelay: minDelay, maxDelay: maxDelay}
+}
+
+// Ready reports whether the attempt is allowed at the given time.
+func (m *backoff) Ready(now time.Time) bool {
+	return !now.Before(m.retry)
+}
+
+// Failed delays the next attempt after the failed one.
+func (m *backoff) Failed(now time.Time) {
+	m.delay = min(max(2*m.delay, m.minDelay), m.maxDelay)
+	m.retry = now.Add(m.delay)
+}
+
+// Reset allows the next attempt immediately.
+func (m *backoff) Reset() {
+	m.delay = 0
+	m.retry = time.Time{}
+}
@@ -0,0 +1,63 @@
+package yanet
+
+import (
+	"context"
+	"sync/atomic"
+
+	"google.golang.org/protobuf/proto"
+
+	yanetpb "github.com/yanet-platform/monalive/gen/yanet"
+)
+
+// invoker performs the RPC invocations over the connections to the control
+// plane.
+type invoker interface {
+	Invoke(ctx context.Context, meta *yanetpb.RpcMeta, in, out proto.Message) error
+	// close closes the connections.
+	close() error
+}
+
+// rpcPool is a pool of the connections for a method used in round-robin
+// order, so the concurrent calls of the method do not queue behind each other.
+type rpcPool struct {
+	conns []*rpcConn
+	next  atomic.Uint64 // index of the connection to use next
+}
+
+// newRPCPool initializes a new rpcPool of the given size for the method. Only
+// the first connection is established immediately, the others are dialed on
+// their first use.
+func newRPCPool(sockPath string, method string, size int, opts *connOptions, health *health) (*rpcPool, error) {
+	first, err := newRPCConn(sockPath, method, opts, health)
+	if err != nil {
+		return nil, err
+	}
+	conns := make([]*rpcConn, 0, max(size, 1))
+	conns = append(conns, first)
+	for len(conns) < cap(conns) {
+		conns = append(conns, &rpcConn{
+			sockPath: sockPath,
+			method:   method,
+			timeout:  opts.timeout,
+			backoff:  newBackoff(opts.minBackoff, opts.maxBackoff),
+			health:   health,
+		})
+	}
+	return &rpcPool{conns: conns}, nil
+}
+
+// Invoke performs an RPC invocation over the next connection of the pool.
+func (m *rpcPool) Invoke(ctx context.Context, meta *yanetpb.RpcMeta, in, out proto.Message) error {
+	idx := (m.next.Add(1) - 1) % uint64(len(m.conns))
+	return m.conns[idx].Invoke(ctx, meta, in, out)
+}
+
+// close closes all connections of the pool.
+func (m *rpcPool) close() error {
+	for _, conn := range m.conns {
+		conn.mu.Lock()
+		_ = conn.close()
+		conn.mu.Unlock()
+	}
+	return nil
+}
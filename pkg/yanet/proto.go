@@ -6,11 +6,13 @@ package yanet
 import (
 	"context"
 	"encoding/binary"
+	"errors"
 	"fmt"
 	"net"
 	"reflect"
 	"strings"
 	"sync"
+	"time"
 
 	"google.golang.org/grpc"
 	"google.golang.org/protobuf/proto"
@@ -18,7 +20,18 @@ import (
 	yanetpb "github.com/yanet-platform/monalive/gen/yanet"
 )
 
-const defaultYANETControlPlaneSockPath = "/run/yanet/protocontrolplane.sock"
+const (
+	defaultYANETControlPlaneSockPath = "/run/yanet/protocontrolplane.sock"
+
+	defaultTimeout    = 5 * time.Second
+	defaultMinBackoff = 100 * time.Millisecond
+	defaultMaxBackoff = 5 * time.Second
+	defaultPoolSize   = 1
+)
+
+// ErrDisconnected is returned when the connection to the YANET control plane
+// is down and the reconnection is delayed by the backoff.
+var ErrDisconnected = errors.New("yanet control plane is disconnected")
 
 // Type alias to make embedded field unexportable.
 type balancerServiceClient = yanetpb.BalancerServiceClient
@@ -27,9 +40,20 @@ type balancerServiceClient = yanetpb.BalancerServiceClient
 // connects to the YANET control plane via a Unix domain socket.
 type Client struct {
 	yanetControlPlaneSockPath string
+	connOptions               connOptions
+	health                    *health
+	channel                   *rpcChannel
 	balancerServiceClient
 }
 
+// connOptions holds the settings of the connections to the control plane.
+type connOptions struct {
+	timeout    time.Duration // default timeout of the calls without deadline
+	minBackoff time.Duration // initial delay between reconnection attempts
+	maxBackoff time.Duration // maximal delay between reconnection attempts
+	poolSize   int           // number of connections per method
+}
+
 // wrapError wraps an error with the method name.
 func wrapError(method string, err error) error {
 	if err != nil {
@@ -60,6 +84,13 @@ func init() {
 func NewClient(opts ...ClientOption) (*Client, error) {
 	client := &Client{
 		yanetControlPlaneSockPath: defaultYANETControlPlaneSockPath,
+		connOptions: connOptions{
+			timeout:    defaultTimeout,
+			minBackoff: defaultMinBackoff,
+			maxBackoff: defaultMaxBackoff,
+			poolSize:   defaultPoolSize,
+		},
+		health: newHealth(),
 	}
 
 	// Apply all the provided options to the client.
@@ -67,15 +98,30 @@ func NewClient(opts ...ClientOption) (*Client, error) {
 		opt(client)
 	}
 
-	channel, err := newRPCChannel(client.yanetControlPlaneSockPath)
+	channel, err := newRPCChannel(client.yanetControlPlaneSockPath, &client.connOptions, client.health)
 	if err != nil {
 		return nil, err
 	}
+	client.channel = channel
 	client.balancerServiceClient = yanetpb.NewBalancerServiceClient(channel)
 
 	return client, nil
 }
 
+// Close closes the connections to the YANET control plane. The calls in flight
+// fail.
+func (m *Client) Close() error {
+	for _, conn := range m.channel.conn {
+		_ = conn.close()
+	}
+	return nil
+}
+
+// Health returns the health of the connection to the YANET control plane.
+func (m *Client) Health() Health {
+	return m.health.get()
+}
+
 // ClientOption represents an option for configuring the Client.
 type ClientOption func(*Client)
 
@@ -87,31 +133,62 @@ func WithControlPlaneSockPath(path string) ClientOption {
 	}
 }
 
+// WithTimeout sets the timeout of the calls whose context has no deadline. Zero
+// timeout disables it, so such calls may block forever.
+func WithTimeout(timeout time.Duration) ClientOption {
+	return func(c *Client) {
+		c.connOptions.timeout = timeout
+	}
+}
+
+// WithPoolSize sets the number of connections per method. The calls of the
+// method are distributed over the connections in round-robin order. Only the
+// first connection is established on the client creation, the others are
+// dialed on their first use. Defaults to 1.
+func WithPoolSize(size int) ClientOption {
+	return func(c *Client) {
+		c.connOptions.poolSize = max(size, 1)
+	}
+}
+
+// WithReconnectBackoff sets the initial and the maximal delays between the
+// reconnection attempts. The delay is doubled after each failed attempt.
+func WithReconnectBackoff(minDelay, maxDelay time.Duration) ClientOption {
+	return func(c *Client) {
+		c.connOptions.minBackoff = minDelay
+		c.connOptions.maxBackoff = max(minDelay, maxDelay)
+	}
+}
+
 // rpcChannel implements grpc.ClientConnInterface and manages the RPC
 // connections for each method.
 //
 // In fact rpcChannel stores set of connections to YANET control plane. Each
-// connection refers to one of the RPC calls provided by YANET balancer service.
+// pool of connections refers to one of the RPC calls provided by YANET
+// balancer service.
 //
 // This structure is lock-free because conn map does not change after creation
 // of this object.
 type rpcChannel struct {
-	sockPath string              // path to YANET control plane socket
-	conn     map[string]*rpcConn // maps method name to rpcConn
+	sockPath string             // path to YANET control plane socket
+	conn     map[string]invoker // maps method name to its connections
 }
 
 // newRPCChannel initializes a new rpcChannel for the given Unix domain socket
 // path.
-func newRPCChannel(sockPath string) (*rpcChannel, error) {
-	conn := make(map[string]*rpcConn, len(methods))
+func newRPCChannel(sockPath string, opts *connOptions, health *health) (*rpcChannel, error) {
+	conn := make(map[string]invoker, len(methods))
 
-	// Create an RPC connection for each method.
+	// Create a pool of RPC connections for each method.
 	for _, method := range methods {
-		rpcConn, err := newRPCConn(sockPath, method)
+		pool, err := newRPCPool(sockPath, method, opts.poolSize, opts, health)
 		if err != nil {
+			for _, opened := range conn {
+				_ = opened.close()
+			}
 			return nil, err
 		}
-		conn[method] = rpcConn
+		conn[method] = pool
 	}
 
 	return &rpcChannel{
@@ -152,7 +229,7 @@ func (m *rpcChannel) Invoke(ctx context.Context, method string, in interface{},
 	}
 
 	// Invoke the RPC method with the provided input and output messages.
-	return conn.Invoke(&meta, in.(proto.Message), out.(proto.Message))
+	return conn.Invoke(ctx, &meta, in.(proto.Message), out.(proto.Message))
 }
 
 // NewStream creates a new stream for the given method. Not implemented in this
@@ -168,39 +245,84 @@ func (m *rpcChannel) NewStream(ctx context.Context, desc *grpc.StreamDesc, metho
 
 // rpcConn represents a single RPC connection for a method.
 type rpcConn struct {
-	conn   net.Conn   // Unix domain socket connection
-	method string     // RPC method name
-	closed bool       // whether the connection is closed
-	mu     sync.Mutex // concurrent calls lead to messages mix up, so use mutex
+	sockPath string   // path to YANET control plane socket
+	method   string   // RPC method name
+	conn     net.Conn // Unix domain socket connection, nil if it is closed
+	timeout  time.Duration
+	backoff  *backoff   // delays the reconnection attempts
+	health   *health    // tracks the health of the connections
+	mu       sync.Mutex // concurrent calls lead to messages mix up, so use mutex
 }
 
 // newRPCConn initializes a new rpcConn for the given method.
-func newRPCConn(sockPath string, method string) (*rpcConn, error) {
+func newRPCConn(sockPath string, method string, opts *connOptions, health *health) (*rpcConn, error) {
 	// Establish a connection to the Unix socket.
-	conn, err := net.Dial("unix", sockPath)
+	conn, err := net.DialTimeout("unix", sockPath, opts.timeout)
 	if err != nil {
 		return nil, fmt.Errorf("failed to dial: %w", err)
 	}
 	return &rpcConn{
-		conn:   conn,
-		method: method,
-		closed: false,
+		sockPath: sockPath,
+		method:   method,
+		conn:     conn,
+		timeout:  opts.timeout,
+		backoff:  newBackoff(opts.minBackoff, opts.maxBackoff),
+		health:   health,
 	}, nil
 }
 
 // Invoke performs an RPC invocation by sending and receiving protobuf messages.
-func (m *rpcConn) Invoke(meta *yanetpb.RpcMeta, in, out proto.Message) (err error) {
+//
+// The call is bounded by the deadline of the ctx, or by the default timeout if
+// the ctx has no deadline. Cancellation of the ctx interrupts the call. On any
+// I/O error the connection is closed to be re-established by the next call.
+func (m *rpcConn) Invoke(ctx context.Context, meta *yanetpb.RpcMeta, in, out proto.Message) (err error) {
 	defer func() {
 		err = wrapError(m.method, err)
 	}()
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
 	// Reopen the connection if it is closed.
-	if err := m.open(); err != nil {
+	if err := m.open(ctx); err != nil {
+		m.health.failed(err)
 		return err
 	}
 
+	// Bound the I/O by the deadline and interrupt it once the ctx is done.
+	deadline, ok := ctx.Deadline()
+	if !ok && m.timeout > 0 {
+		deadline = time.Now().Add(m.timeout)
+	}
+	if err := m.conn.SetDeadline(deadline); err != nil {
+		_ = m.close()
+		m.health.failed(err)
+		return fmt.Errorf("failed to set deadline: %w", err)
+	}
+	conn := m.conn
+	stop := context.AfterFunc(ctx, func() {
+		_ = conn.SetDeadline(time.Unix(1, 0))
+	})
+	defer stop()
+
+	if err := m.roundTrip(meta, in, out); err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			err = fmt.Errorf("%w: %w", ctxErr, err)
+		}
+		m.health.failed(err)
+		return err
+	}
+	m.health.succeeded()
+	return nil
+}
+
+// roundTrip sends the RPC metadata with the input message and receives the
+// output message.
+func (m *rpcConn) roundTrip(meta *yanetpb.RpcMeta, in, out proto.Message) error {
 	// Send the RPC metadata.
 	if err := m.send(meta); err != nil {
 		return err
@@ -230,13 +352,13 @@ func (m *rpcConn) send(message proto.Message) (err error) {
 	// Write the size of the message to the Unix socket.
 	if _, err := m.write(sizeBuf); err != nil {
 		_ = m.close()
-		return fmt.Errorf("write [%s]: %w", string(sizeBuf), err)
+		return fmt.Errorf("write size: %w", err)
 	}
 
 	// Write the serialized message itself to the Unix socket.
 	if _, err := m.write(messageBuf); err != nil {
 		_ = m.close()
-		return fmt.Errorf("write [%s]: %w", string(messageBuf), err)
+		return fmt.Errorf("write message: %w", err)
 	}
 
 	return nil
@@ -279,21 +401,29 @@ func (m *rpcConn) recv(message proto.Message) (err error) {
 	return nil
 }
 
-// open reopens the connection if it is closed.
-func (m *rpcConn) open() error {
+// open reopens the connection if it is closed. The reconnection attempts are
+// delayed with the exponential backoff, until the delay expires the call fails
+// with [ErrDisconnected] without dialing.
+func (m *rpcConn) open(ctx context.Context) error {
 	// If the connection is not closed, do nothing.
-	if !m.closed {
+	if m.conn != nil {
 		return nil
 	}
 
+	if !m.backoff.Ready(time.Now()) {
+		return ErrDisconnected
+	}
+
 	// Reopen the connection to the Unix socket.
-	conn, err := net.Dial("unix", m.conn.RemoteAddr().String())
+	dialer := net.Dialer{Timeout: m.timeout}
+	conn, err := dialer.DialContext(ctx, "unix", m.sockPath)
 	if err != nil {
+		m.backoff.Failed(time.Now())
 		return fmt.Errorf("failed to dial: %w", err)
 	}
+	m.backoff.Reset()
 
 	m.conn = conn
-	m.closed = false
 
 	return nil
 }
@@ -311,12 +441,12 @@ func (m *rpcConn) read(b []byte) (int, error) {
 // close closes the connection.
 func (m *rpcConn) close() error {
 	// If the connection is already closed, do nothing.
-	if m.closed {
+	if m.conn == nil {
 		return nil
 	}
 
 	err := m.conn.Close()
-	m.closed = true
+	m.conn = nil
 
 	return err
 }
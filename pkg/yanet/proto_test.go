@@ -0,0 +1,189 @@
+package yanet
+
+import (
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	yanetpb "github.com/yanet-platform/monalive/gen/yanet"
+)
+
+// testServer is a control plane stub replying to every request with an empty
+// message after the delay. The requests of a connection are served
+// sequentially. When it is wedged, it reads the requests but never replies.
+type testServer struct {
+	sockPath string
+	listener net.Listener
+	delay    time.Duration
+	wedged   atomic.Bool
+	accepted atomic.Int64
+	conns    []net.Conn
+	mu       sync.Mutex
+}
+
+// newTestServer starts the control plane stub listening on the socket path.
+func newTestServer(t testing.TB, sockPath string) *testServer {
+	listener, err := net.Listen("unix", sockPath)
+	require.NoError(t, err)
+	server := &testServer{sockPath: sockPath, listener: listener}
+	go server.serve()
+	t.Cleanup(server.Stop)
+	return server
+}
+
+func (m *testServer) serve() {
+	for {
+		conn, err := m.listener.Accept()
+		if err != nil {
+			return
+		}
+		m.accepted.Add(1)
+		m.mu.Lock()
+		m.conns = append(m.conns, conn)
+		m.mu.Unlock()
+		go m.handle(conn)
+	}
+}
+
+func (m *testServer) handle(conn net.Conn) {
+	defer conn.Close()
+	for {
+		// Read the metadata and the request messages.
+		for range 2 {
+			var size [8]byte
+			if _, err := io.ReadFull(conn, size[:]); err != nil {
+				return
+			}
+			if _, err := io.CopyN(io.Discard, conn, int64(binary.LittleEndian.Uint64(size[:]))); err != nil {
+				return
+			}
+		}
+		if m.wedged.Load() {
+			continue
+		}
+		if m.delay > 0 {
+			time.Sleep(m.delay)
+		}
+		if _, err := conn.Write(make([]byte, 8)); err != nil {
+			return
+		}
+	}
+}
+
+// Stop closes the listener and all accepted connections.
+func (m *testServer) Stop() {
+	_ = m.listener.Close()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, conn := range m.conns {
+		_ = conn.Close()
+	}
+	m.conns = nil
+}
+
+// TestClient_Timeout tests that the call to the wedged control plane fails
+// after the default timeout, the connection is reported unhealthy, and the
+// next call re-establishes the connection.
+func TestClient_Timeout(t *testing.T) {
+	server := newTestServer(t, filepath.Join(t.TempDir(), "yanet.sock"))
+	client, err := NewClient(WithControlPlaneSockPath(server.sockPath), WithTimeout(50*time.Millisecond))
+	require.NoError(t, err)
+
+	_, err = client.RealFlush(context.Background(), &yanetpb.Empty{})
+	require.NoError(t, err)
+	assert.True(t, client.Health().Connected)
+
+	server.wedged.Store(true)
+	start := time.Now()
+	_, err = client.RealFlush(context.Background(), &yanetpb.Empty{})
+	assert.ErrorIs(t, err, os.ErrDeadlineExceeded)
+	assert.Less(t, time.Since(start), time.Second)
+
+	health := client.Health()
+	assert.False(t, health.Connected)
+	assert.ErrorIs(t, health.LastError, os.ErrDeadlineExceeded)
+
+	server.wedged.Store(false)
+	_, err = client.RealFlush(context.Background(), &yanetpb.Empty{})
+	require.NoError(t, err)
+	assert.True(t, client.Health().Connected)
+}
+
+// TestClient_ContextCancel tests that the cancellation of the context
+// interrupts the call even if the default timeout is disabled.
+func TestClient_ContextCancel(t *testing.T) {
+	server := newTestServer(t, filepath.Join(t.TempDir(), "yanet.sock"))
+	client, err := NewClient(WithControlPlaneSockPath(server.sockPath), WithTimeout(0))
+	require.NoError(t, err)
+
+	server.wedged.Store(true)
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+
+	_, err = client.RealFlush(ctx, &yanetpb.Empty{})
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+// TestClient_ReconnectBackoff tests that the reconnection attempts are delayed
+// after the dial failure, and the connection is restored once the control
+// plane is back.
+func TestClient_ReconnectBackoff(t *testing.T) {
+	sockPath := filepath.Join(t.TempDir(), "yanet.sock")
+	server := newTestServer(t, sockPath)
+	client, err := NewClient(
+		WithControlPlaneSockPath(sockPath),
+		WithTimeout(time.Second),
+		WithReconnectBackoff(100*time.Millisecond, time.Second),
+	)
+	require.NoError(t, err)
+
+	// The established connection is broken.
+	server.Stop()
+	require.NoError(t, os.RemoveAll(sockPath))
+	_, err = client.RealFlush(context.Background(), &yanetpb.Empty{})
+	require.Error(t, err)
+
+	// The dial fails and the next attempt is delayed.
+	_, err = client.RealFlush(context.Background(), &yanetpb.Empty{})
+	require.Error(t, err)
+	assert.NotErrorIs(t, err, ErrDisconnected)
+	_, err = client.RealFlush(context.Background(), &yanetpb.Empty{})
+	assert.ErrorIs(t, err, ErrDisconnected)
+	assert.False(t, client.Health().Connected)
+
+	newTestServer(t, sockPath)
+	time.Sleep(150 * time.Millisecond)
+	_, err = client.RealFlush(context.Background(), &yanetpb.Empty{})
+	require.NoError(t, err)
+	assert.True(t, client.Health().Connected)
+}
+
+// TestClient_Pool tests that the calls of a method are distributed over the
+// pool of connections, which are dialed on their first use.
+func TestClient_Pool(t *testing.T) {
+	server := newTestServer(t, filepath.Join(t.TempDir(), "yanet.sock"))
+	client, err := NewClient(WithControlPlaneSockPath(server.sockPath), WithPoolSize(3))
+	require.NoError(t, err)
+
+	// Only the first connection of each method is established.
+	accepted := func(expected int) func() bool {
+		return func() bool { return server.accepted.Load() == int64(expected) }
+	}
+	assert.Eventually(t, accepted(len(methods)), time.Second, time.Millisecond)
+
+	for range 6 {
+		_, err = client.RealFlush(context.Background(), &yanetpb.Empty{})
+		require.NoError(t, err)
+	}
+	assert.Eventually(t, accepted(len(methods)+2), time.Second, time.Millisecond)
+}